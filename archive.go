@@ -0,0 +1,556 @@
+package xdg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestName is the name of the manifest at the start of an archive
+// written by Export.
+const ManifestName = "manifest.json"
+
+// manifestVersion is the version of the archive layout.
+const manifestVersion = 1
+
+// Manifest describes the contents of an archive written by Export.
+type Manifest struct {
+	// Version is the version of the archive layout.
+	Version int `json:"version"`
+	// App is the name of the application the archive was made for.
+	App string `json:"app"`
+	// Created is when the archive was written.
+	Created time.Time `json:"created"`
+	// Kinds lists the directories in the archive. The files of each are
+	// stored below a top level directory named after the kind, such as
+	// config/settings.toml.
+	Kinds []Kind `json:"kinds"`
+	// Files lists every regular file and symbolic link in the archive.
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file listed in a Manifest.
+type ManifestFile struct {
+	// Path is the slash separated name of the file in the archive.
+	Path string `json:"path"`
+	// Size is the length of the file in bytes.
+	Size int64 `json:"size"`
+	// Mode is the file's permission bits.
+	Mode fs.FileMode `json:"mode"`
+	// Link is the target of a symbolic link, empty for a regular file.
+	Link string `json:"link,omitempty"`
+}
+
+// defaultExportKinds are the directories exported when none are given. The
+// cache and runtime directories can be recreated and are left out.
+var defaultExportKinds = []Kind{KindConfig, KindData, KindState}
+
+// Export writes the directories of the application name to w. See
+// (*XDG).Export.
+func Export(name string, w io.Writer, kinds ...Kind) error {
+	return newXdg(name).Export(w, kinds...)
+}
+
+// Export writes a gzip compressed tar archive of the application's
+// directories of the given kinds to w, defaulting to the config, data and
+// state directories. The archive starts with a Manifest named ManifestName
+// and stores each directory under the kind's name with paths relative to
+// it, so it can be restored with Import on another machine. Directories
+// that do not exist are recorded in the manifest but have no entries.
+// Kinds that resolve to the same directory are exported once, under the
+// first of them. Permission bits, modification times and owners are
+// recorded for every entry. Symbolic links are stored as links when the package uses the host
+// file system, and other special files are skipped.
+func (xdg *XDG) Export(w io.Writer, kinds ...Kind) error {
+	if len(kinds) == 0 {
+		kinds = defaultExportKinds
+	}
+	type entry struct {
+		name string
+		dir  Dir
+		rel  string
+		info fs.FileInfo
+		link string
+	}
+	var (
+		entries []entry
+		m       = Manifest{Version: manifestVersion, App: xdg.finder.Name(), Created: time.Now().UTC()}
+		seen    = make(map[string]bool)
+	)
+	for _, kind := range kinds {
+		dir, err := xdg.Lookup(kind)
+		if err != nil {
+			return err
+		}
+		// Kinds sharing a directory, as config, data and state do on
+		// darwin, are only stored under the first of them.
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		m.Kinds = append(m.Kinds, kind)
+		d := Dir(dir)
+		if _, err = fileSystem().Stat(dir); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		err = d.Walk(func(rel string, e fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			link := e.Type()&fs.ModeSymlink != 0 && isOSFileSystem()
+			if rel == "." || !e.Type().IsRegular() && !e.IsDir() && !link {
+				return nil
+			}
+			info, err := e.Info()
+			if err != nil {
+				return err
+			}
+			name := path.Join(kind.String(), rel)
+			ent := entry{name: name, dir: d, rel: rel, info: info}
+			if link {
+				if ent.link, err = os.Readlink(longPath(filepath.Join(dir, filepath.FromSlash(rel)))); err != nil {
+					return err
+				}
+			}
+			entries = append(entries, ent)
+			if !e.IsDir() {
+				m.Files = append(m.Files, ManifestFile{Path: name, Size: info.Size(), Mode: info.Mode().Perm(), Link: ent.link})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     ManifestName,
+		Size:     int64(len(manifest)),
+		Mode:     0644,
+		ModTime:  m.Created,
+	})
+	if err == nil {
+		_, err = tw.Write(manifest)
+	}
+	for _, e := range entries {
+		if err != nil {
+			break
+		}
+		err = writeTarEntry(tw, e.name, e.dir, e.rel, e.info, e.link)
+	}
+	if err != nil {
+		return fmt.Errorf("xdg: export: %w", err)
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeTarEntry(tw *tar.Writer, name string, dir Dir, rel string, info fs.FileInfo, link string) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(info.Mode().Perm()),
+		ModTime: info.ModTime(),
+	}
+	hdr.Uid, hdr.Gid, _ = fileOwner(info)
+	switch {
+	case info.IsDir():
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name += "/"
+		return tw.WriteHeader(hdr)
+	case len(link) > 0:
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = link
+		return tw.WriteHeader(hdr)
+	}
+	f, err := dir.Open(rel)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hdr.Typeflag = tar.TypeReg
+	hdr.Size = info.Size()
+	if err = tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// RestorePolicy decides what Import does with the files already in a
+// directory being restored.
+type RestorePolicy int
+
+const (
+	// RestoreSkip keeps existing files and only restores the missing ones.
+	RestoreSkip RestorePolicy = iota
+	// RestoreMerge replaces existing files with the archived ones and keeps
+	// the files that are not in the archive.
+	RestoreMerge
+	// RestoreOverwrite empties each restored directory first so that it ends
+	// up holding exactly the archived files.
+	RestoreOverwrite
+)
+
+// RestoreOptions configures Import.
+type RestoreOptions struct {
+	// Policy is applied to the files already on disk.
+	Policy RestorePolicy
+	// Kinds limits the directories that are restored. By default every
+	// directory in the archive is.
+	Kinds []Kind
+	// DryRun checks the archive and reports what would change without
+	// touching the disk.
+	DryRun bool
+	// IgnoreModes creates files with mode 0644 and directories with DirMode
+	// instead of the archived permission bits.
+	IgnoreModes bool
+	// IgnoreTimes leaves restored files with the time of the restore instead
+	// of the archived modification times.
+	IgnoreTimes bool
+	// Owner restores the archived uid and gid of every entry. It has an
+	// effect only when running as root on Unix, since no one else may give
+	// files away.
+	Owner bool
+	// Report, if set, is called with OpRemove or OpWrite and the path of
+	// every file Import removes or writes, or would with DryRun.
+	Report func(op, path string)
+}
+
+func (o *RestoreOptions) report(op, path string) {
+	if o.Report != nil {
+		o.Report(op, path)
+	}
+}
+
+// Import restores an archive written by Export into the directories of the
+// application name. See (*XDG).Import.
+func Import(name string, r io.Reader, opts RestoreOptions) error {
+	return newXdg(name).Import(r, opts)
+}
+
+// Import restores an archive written by Export into the current locations
+// of the application's directories, which need not be the ones it was
+// exported from. The whole archive is read and checked before anything is
+// written, with file contents spooled to a temporary file on the host
+// rather than held in memory: entries must be regular files, directories or symbolic links
+// below one of the kinds in the manifest, and names that are absolute or
+// would escape their directory, or links pointing outside of it, are
+// rejected with ErrInvalidPath. Missing parent directories are created with
+// DirMode. With RestoreOverwrite a directory shared by several kinds is
+// cleared once. Unless RestoreOptions says otherwise, files and directories get
+// their archived permission bits and modification times back; both can
+// only be set on the host file system for directories and symbolic links
+// are only restored there.
+func (xdg *XDG) Import(r io.Reader, opts RestoreOptions) error {
+	spool, err := os.CreateTemp("", "xdg-import-*")
+	if err != nil {
+		return fmt.Errorf("xdg: import: %w", err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+	m, entries, err := readArchive(r, spool)
+	if err != nil {
+		return fmt.Errorf("xdg: import: %w", err)
+	}
+	want := make(map[Kind]bool)
+	for _, kind := range m.Kinds {
+		want[kind] = len(opts.Kinds) == 0
+	}
+	for _, kind := range opts.Kinds {
+		if _, ok := want[kind]; ok {
+			want[kind] = true
+		}
+	}
+	dirs := make(map[Kind]Dir)
+	for _, kind := range m.Kinds {
+		if !want[kind] {
+			continue
+		}
+		dir, err := xdg.Lookup(kind)
+		if err != nil {
+			return err
+		}
+		dirs[kind] = Dir(dir)
+	}
+	for _, e := range entries {
+		if _, ok := want[e.kind]; !ok {
+			return fmt.Errorf("xdg: import: %s is not below a directory in the manifest", e.name)
+		}
+		if _, err = dirs[e.kind].join(e.rel); err != nil {
+			return err
+		}
+	}
+
+	if opts.Policy == RestoreOverwrite {
+		cleared := make(map[Dir]bool)
+		for _, kind := range m.Kinds {
+			if d, ok := dirs[kind]; ok && !cleared[d] {
+				cleared[d] = true
+				if err = clearDir(d, &opts); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	var restoredDirs []archiveEntry
+	for _, e := range entries {
+		d, ok := dirs[e.kind]
+		if !ok {
+			continue
+		}
+		path, _ := d.join(e.rel)
+		check := e.rel
+		if len(e.link) > 0 {
+			// The link itself may replace an existing one, so only the
+			// directories above it are checked.
+			check = e.rel[:max(strings.LastIndex(e.rel, "/"), 0)]
+		}
+		if len(check) > 0 {
+			if err = checkNoSymlinks(string(d), check); err != nil {
+				return err
+			}
+		}
+		if e.dir {
+			if opts.DryRun {
+				continue
+			}
+			if err = mkdirAll(path, xdg.DirMode(e.kind)); err != nil {
+				return err
+			}
+			e.path = path
+			restoredDirs = append(restoredDirs, e)
+			continue
+		}
+		if len(e.link) > 0 && !isOSFileSystem() {
+			continue
+		}
+		if opts.Policy == RestoreSkip && (exists(path) || len(e.link) > 0 && lexists(path)) {
+			continue
+		}
+		opts.report(OpWrite, path)
+		if opts.DryRun {
+			continue
+		}
+		if err = mkdirAll(filepath.Dir(path), xdg.DirMode(e.kind)); err != nil {
+			return err
+		}
+		if len(e.link) > 0 {
+			err = restoreLink(path, e.link)
+		} else {
+			mode := e.mode
+			if opts.IgnoreModes {
+				mode = 0644
+			}
+			data := io.NewSectionReader(spool, e.offset, e.size)
+			if err = writeFileFrom(path, data, e.size, mode); err == nil && !opts.IgnoreModes && isOSFileSystem() {
+				// writeFile keeps the mode of a file it replaces.
+				err = chmod(path, mode)
+			}
+			if err == nil && !opts.IgnoreTimes {
+				err = setModTime(path, e.modTime)
+			}
+		}
+		if err == nil && opts.Owner {
+			err = setOwner(path, e.uid, e.gid)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// Directories are finished last, deepest first, since writing files
+	// into them changes their times and their modes may forbid writing.
+	for i := len(restoredDirs) - 1; i >= 0; i-- {
+		e := restoredDirs[i]
+		if !isOSFileSystem() {
+			break
+		}
+		if !opts.IgnoreModes {
+			err = chmod(e.path, e.mode)
+		}
+		if err == nil && !opts.IgnoreTimes {
+			err = setModTime(e.path, e.modTime)
+		}
+		if err == nil && opts.Owner {
+			err = setOwner(e.path, e.uid, e.gid)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLink creates a symbolic link at path, replacing whatever is there.
+func restoreLink(path, target string) error {
+	if err := os.Remove(longPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err := os.Symlink(target, longPath(path))
+	audit(OpWrite, path, 0, fs.ModeSymlink, err)
+	return err
+}
+
+type archiveEntry struct {
+	name     string
+	kind     Kind
+	rel      string
+	dir      bool
+	link     string
+	mode     fs.FileMode
+	modTime  time.Time
+	uid, gid int
+	// offset and size locate the contents of a file in the spool.
+	offset, size int64
+	// path is where a directory was restored.
+	path string
+}
+
+// readArchive reads the manifest and every entry of an archive written by
+// Export, copying the contents of the files to spool.
+func readArchive(r io.Reader, spool io.Writer) (Manifest, []archiveEntry, error) {
+	var m Manifest
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return m, nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		return m, nil, err
+	}
+	if hdr.Name != ManifestName {
+		return m, nil, fmt.Errorf("archive does not start with %s", ManifestName)
+	}
+	if err = json.NewDecoder(tr).Decode(&m); err != nil {
+		return m, nil, err
+	}
+	if m.Version > manifestVersion {
+		return m, nil, fmt.Errorf("unsupported archive version %d", m.Version)
+	}
+	var (
+		entries []archiveEntry
+		offset  int64
+	)
+	for {
+		hdr, err = tr.Next()
+		if err == io.EOF {
+			return m, entries, nil
+		}
+		if err != nil {
+			return m, nil, err
+		}
+		e := archiveEntry{
+			name:    hdr.Name,
+			mode:    fs.FileMode(hdr.Mode).Perm(),
+			modTime: hdr.ModTime,
+			uid:     hdr.Uid,
+			gid:     hdr.Gid,
+		}
+		top, rel, _ := strings.Cut(strings.TrimSuffix(hdr.Name, "/"), "/")
+		if err = e.kind.UnmarshalText([]byte(top)); err != nil || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return m, nil, fmt.Errorf("%w: archive entry %q", ErrInvalidPath, hdr.Name)
+		}
+		e.rel = rel
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			e.dir = true
+		case tar.TypeReg:
+			n, err := io.Copy(spool, tr)
+			if err != nil {
+				return m, nil, err
+			}
+			e.offset, e.size = offset, n
+			offset += n
+		case tar.TypeSymlink:
+			// The target is taken relative to the directory holding the
+			// link and must stay inside the restored directory.
+			target := filepath.Join(filepath.Dir(filepath.FromSlash(rel)), filepath.FromSlash(hdr.Linkname))
+			if len(hdr.Linkname) == 0 || path.IsAbs(hdr.Linkname) || filepath.IsAbs(hdr.Linkname) || !filepath.IsLocal(target) {
+				return m, nil, fmt.Errorf("%w: archive link %q points to %q", ErrInvalidPath, hdr.Name, hdr.Linkname)
+			}
+			e.link = hdr.Linkname
+		default:
+			return m, nil, fmt.Errorf("archive entry %s is not a regular file, directory or symbolic link", hdr.Name)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// clearDir removes everything inside d, leaving d itself in place.
+func clearDir(d Dir, opts *RestoreOptions) error {
+	var names []string
+	err := d.Walk(func(rel string, _ fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && rel == "." {
+			return fs.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			names = append(names, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		path := filepath.Join(string(d), filepath.FromSlash(names[i]))
+		opts.report(OpRemove, path)
+		if opts.DryRun {
+			continue
+		}
+		if err = remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNoSymlinks makes sure that nothing that already exists on the way from
+// root to the slash separated rel is a symbolic link that would redirect a
+// write outside of root.
+func checkNoSymlinks(root, rel string) error {
+	if !isOSFileSystem() {
+		return nil
+	}
+	p := root
+	for _, part := range strings.Split(rel, "/") {
+		p = filepath.Join(p, part)
+		info, err := os.Lstat(longPath(p))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s is a symbolic link", ErrInvalidPath, p)
+		}
+	}
+	return nil
+}
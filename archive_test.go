@@ -0,0 +1,408 @@
+package xdg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func readTestArchive(t *testing.T, r io.Reader) (Manifest, map[string]string) {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var (
+		m     Manifest
+		files = make(map[string]string)
+	)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			eq(t, ManifestName, hdr.Name)
+			if err = json.Unmarshal(b, &m); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		files[hdr.Name] = string(b)
+	}
+	return m, files
+}
+
+func TestExport(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	x := New("app")
+	writeTestFile(t, filepath.Join(x.Config(), "settings.toml"))
+	writeTestFile(t, filepath.Join(x.Config(), "themes", "dark.toml"))
+	writeTestFile(t, filepath.Join(x.State(), "history"))
+	writeTestFile(t, filepath.Join(x.Cache(), "big.bin"))
+
+	var buf bytes.Buffer
+	if err := x.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m, files := readTestArchive(t, &buf)
+	eq(t, manifestVersion, m.Version)
+	eq(t, "app", m.App)
+	arrEq(t, []Kind{KindConfig, KindData, KindState}, m.Kinds)
+	eq(t, 3, len(m.Files))
+	for _, f := range m.Files {
+		if _, ok := files[f.Path]; !ok {
+			t.Errorf("manifest lists %s which is not in the archive", f.Path)
+		}
+	}
+	eq(t, filepath.Join(x.Config(), "themes", "dark.toml"), files["config/themes/dark.toml"])
+	eq(t, filepath.Join(x.State(), "history"), files["state/history"])
+	if _, ok := files["config/themes/"]; !ok {
+		t.Error("directories should be stored")
+	}
+	if _, ok := files["cache/big.bin"]; ok {
+		t.Error("cache should not be exported by default")
+	}
+
+	buf.Reset()
+	if err := Export("app", &buf, KindCache); err != nil {
+		t.Fatal(err)
+	}
+	m, files = readTestArchive(t, &buf)
+	arrEq(t, []Kind{KindCache}, m.Kinds)
+	eq(t, filepath.Join(x.Cache(), "big.bin"), files["cache/big.bin"])
+	if err := x.Export(io.Discard, Kind(0)); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+// writeTestArchive writes an archive with a manifest for kinds and the given
+// entries, mapping names to contents. Names ending in a slash are stored as
+// directories and names of the form "link -> target" as symbolic links.
+func writeTestArchive(t *testing.T, kinds []Kind, entries ...[2]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	manifest, err := json.Marshal(Manifest{Version: manifestVersion, Kinds: kinds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries = append([][2]string{{ManifestName, string(manifest)}}, entries...)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e[0], Mode: 0644, Size: int64(len(e[1])), Typeflag: tar.TypeReg, ModTime: time.Now()}
+		if len(e[0]) > 0 && e[0][len(e[0])-1] == '/' {
+			hdr.Typeflag, hdr.Size, hdr.Mode = tar.TypeDir, 0, 0755
+		} else if name, target, ok := strings.Cut(e[0], " -> "); ok {
+			hdr.Name, hdr.Linkname, hdr.Typeflag, hdr.Size = name, target, tar.TypeSymlink, 0
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(e[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestImport(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tmp, "old"))
+	old := New("app")
+	writeTestFile(t, filepath.Join(old.Config(), "settings.toml"))
+	writeTestFile(t, filepath.Join(old.Config(), "themes", "dark.toml"))
+	writeTestFile(t, filepath.Join(old.State(), "history"))
+	var archive bytes.Buffer
+	if err := old.Export(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", filepath.Join(tmp, "new"))
+	x := New("app")
+	settings := filepath.Join(x.Config(), "settings.toml")
+	local := filepath.Join(x.Config(), "local.toml")
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	reset := func() {
+		t.Helper()
+		if err := os.RemoveAll(filepath.Join(tmp, "new")); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, settings, "mine")
+		writeTestFile(t, local, "mine")
+	}
+
+	reset()
+	if err := x.Import(bytes.NewReader(archive.Bytes()), RestoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "mine", read(settings))
+	eq(t, "mine", read(local))
+	eq(t, filepath.Join(old.Config(), "themes", "dark.toml"), read(filepath.Join(x.Config(), "themes", "dark.toml")))
+	eq(t, filepath.Join(old.State(), "history"), read(filepath.Join(x.State(), "history")))
+
+	reset()
+	if err := x.Import(bytes.NewReader(archive.Bytes()), RestoreOptions{Policy: RestoreMerge}); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(old.Config(), "settings.toml"), read(settings))
+	eq(t, "mine", read(local))
+
+	reset()
+	err := Import("app", bytes.NewReader(archive.Bytes()), RestoreOptions{Policy: RestoreOverwrite, Kinds: []Kind{KindConfig}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(old.Config(), "settings.toml"), read(settings))
+	if _, err = os.Stat(local); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", local, err)
+	}
+	if _, err = os.Stat(x.State()); !os.IsNotExist(err) {
+		t.Errorf("state should not be restored, got %v", err)
+	}
+
+	reset()
+	var ops []string
+	err = x.Import(bytes.NewReader(archive.Bytes()), RestoreOptions{
+		Policy: RestoreOverwrite,
+		Kinds:  []Kind{KindConfig},
+		DryRun: true,
+		Report: func(op, path string) { ops = append(ops, op+" "+path) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "mine", read(settings))
+	eq(t, "mine", read(local))
+	arrEq(t, ops, []string{
+		OpRemove + " " + settings,
+		OpRemove + " " + local,
+		OpWrite + " " + settings,
+		OpWrite + " " + filepath.Join(x.Config(), "themes", "dark.toml"),
+	})
+}
+
+func TestImportUnsafe(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	x := New("app")
+	for _, entries := range [][][2]string{
+		{{"config/../../evil", "x"}},
+		{{"config/link -> ../../evil", ""}},
+		{{"config/link -> /etc/passwd", ""}},
+		{{"/etc/passwd", "x"}},
+		{{"config//etc/passwd", "x"}},
+		{{"other/file", "x"}},
+		{{"data/file", "x"}},
+	} {
+		err := x.Import(writeTestArchive(t, []Kind{KindConfig}, entries...), RestoreOptions{})
+		if err == nil {
+			t.Errorf("%s: expected an error", entries[0][0])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "evil")); !os.IsNotExist(err) {
+		t.Error("archive escaped the config directory")
+	}
+	if err := x.Import(bytes.NewBufferString("not an archive"), RestoreOptions{}); err == nil {
+		t.Error("expected an error for a bad archive")
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	outside := filepath.Join(tmp, "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(x.Config(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(x.Config(), "link")); err != nil {
+		t.Fatal(err)
+	}
+	err := x.Import(writeTestArchive(t, []Kind{KindConfig}, [2]string{"config/link/file", "x"}), RestoreOptions{})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(outside, "file")); !os.IsNotExist(err) {
+		t.Error("import followed a symbolic link")
+	}
+}
+
+func TestExportImportPreserve(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits and symbolic links differ on windows")
+	}
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tmp, "old"))
+	old := New("app")
+	script := filepath.Join(old.Config(), "hooks", "run.sh")
+	secret := filepath.Join(old.Config(), "token")
+	writeTestFile(t, script)
+	writeTestFile(t, secret)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for path, mode := range map[string]os.FileMode{script: 0755, secret: 0600, filepath.Dir(script): 0750} {
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("hooks/run.sh", filepath.Join(old.Config(), "current")); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{script, secret, filepath.Dir(script)} {
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var archive bytes.Buffer
+	if err := old.Export(&archive, KindConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", filepath.Join(tmp, "new"))
+	x := New("app")
+	if err := x.Import(bytes.NewReader(archive.Bytes()), RestoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	check := func(rel string, mode os.FileMode, keepTime bool) {
+		t.Helper()
+		info, err := os.Stat(filepath.Join(x.Config(), rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, mode, info.Mode().Perm())
+		eq(t, keepTime, info.ModTime().Equal(mtime))
+	}
+	check("hooks/run.sh", 0755, true)
+	check("token", 0600, true)
+	check("hooks", 0750, true)
+	link, err := os.Readlink(filepath.Join(x.Config(), "current"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "hooks/run.sh", link)
+
+	if err = os.RemoveAll(filepath.Join(tmp, "new")); err != nil {
+		t.Fatal(err)
+	}
+	err = x.Import(bytes.NewReader(archive.Bytes()), RestoreOptions{IgnoreModes: true, IgnoreTimes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("hooks/run.sh", 0644, false)
+	check("token", 0644, false)
+}
+
+func TestExportImportSharedDir(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	// Like darwin, where config, data and state share a directory.
+	t.Setenv(configHomeKey, tmp)
+	t.Setenv(dataHomeKey, tmp)
+	t.Setenv(stateHomeKey, tmp)
+	x := New("app")
+	settings := filepath.Join(x.Config(), "settings.toml")
+	writeTestFile(t, settings)
+	var archive bytes.Buffer
+	if err := x.Export(&archive); err != nil {
+		t.Fatal(err)
+	}
+	m, files := readTestArchive(t, bytes.NewReader(archive.Bytes()))
+	if len(m.Kinds) != 1 || m.Kinds[0] != KindConfig {
+		t.Errorf("wrong manifest kinds %v", m.Kinds)
+	}
+	if len(files) != 1 || files["config/settings.toml"] != settings {
+		t.Errorf("wrong archive files %v", files)
+	}
+
+	// An archive from a machine with separate directories.
+	other := filepath.Join(tmp, "other")
+	t.Setenv(configHomeKey, filepath.Join(other, "config"))
+	t.Setenv(dataHomeKey, filepath.Join(other, "data"))
+	t.Setenv(stateHomeKey, filepath.Join(other, "state"))
+	o := New("app")
+	writeTestFile(t, filepath.Join(o.Config(), "settings.toml"))
+	writeTestFile(t, filepath.Join(o.State(), "history"))
+	archive.Reset()
+	if err := o.Export(&archive); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configHomeKey, tmp)
+	t.Setenv(dataHomeKey, tmp)
+	t.Setenv(stateHomeKey, tmp)
+	var ops []string
+	err := x.Import(&archive, RestoreOptions{
+		Policy: RestoreOverwrite,
+		DryRun: true,
+		Report: func(op, path string) { ops = append(ops, op+" "+path) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, ops, []string{
+		OpRemove + " " + settings,
+		OpWrite + " " + settings,
+		OpWrite + " " + filepath.Join(x.State(), "history"),
+	})
+}
+
+func TestImportFileSystem(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	old := New("app")
+	src := filepath.Join(old.Config(), "themes", "dark.toml")
+	writeTestFile(t, src)
+	var archive bytes.Buffer
+	if err := old.Export(&archive); err != nil {
+		t.Fatal(err)
+	}
+	mem := memFS{m: fstest.MapFS{}}
+	SetFileSystem(mem)
+	defer SetFileSystem(nil)
+	t.Setenv(configHomeKey, "/conf")
+	if err := New("app").Import(&archive, RestoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	f, ok := mem.m["conf/app/themes/dark.toml"]
+	if !ok {
+		t.Fatal("file should be restored to the installed file system")
+	}
+	eq(t, src, string(f.Data))
+}
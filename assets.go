@@ -0,0 +1,117 @@
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// assetsManifest is the file in the data directory listing the installed
+// assets, in the format of sha256sum.
+const assetsManifest = ".assets.sha256"
+
+// InstallAssets copies src into the data directory of the application name.
+// The options are applied to the instance. See (*XDG).InstallAssets.
+func InstallAssets(name string, src fs.FS, opts ...Option) error {
+	return New(name, opts...).InstallAssets(src)
+}
+
+// InstallAssets syncs the files of src, typically an embed.FS, into
+// $XDG_DATA_HOME/<name> so templates and other resources shipped in the
+// binary can be used from disk. Files whose contents already match are left
+// alone, and files installed by an earlier version that are no longer in
+// src are removed along with the directories they leave empty. Other files
+// in the data directory are never touched. The installed files are recorded
+// in a manifest named .assets.sha256.
+func (xdg *XDG) InstallAssets(src fs.FS) error {
+	dir, err := xdg.DataE()
+	if err != nil {
+		return err
+	}
+	d := Dir(dir)
+	old := readAssetsManifest(d)
+	sums := make(map[string]string)
+	err = fs.WalkDir(src, ".", func(name string, e fs.DirEntry, err error) error {
+		if err != nil || !e.Type().IsRegular() {
+			return err
+		}
+		if name == assetsManifest {
+			return fmt.Errorf("xdg: asset %s uses a reserved name", name)
+		}
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		sums[name] = hex.EncodeToString(sum[:])
+		if cur, err := d.ReadFile(name); err == nil && bytes.Equal(cur, data) {
+			return nil
+		}
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err = mkdirAll(filepath.Dir(full), xdg.DirMode(KindData)); err != nil {
+			return err
+		}
+		return d.WriteFileAtomic(filepath.FromSlash(name), data, 0644)
+	})
+	if err != nil {
+		return err
+	}
+
+	parents := make(map[string]bool)
+	for name := range old {
+		if _, ok := sums[name]; ok {
+			continue
+		}
+		err = remove(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		parents[path.Dir(name)] = true
+	}
+	if err = pruneEmpty(d, parents); err != nil {
+		return err
+	}
+	if err = mkdirAll(dir, xdg.DirMode(KindData)); err != nil {
+		return err
+	}
+	return writeAssetsManifest(d, sums)
+}
+
+// readAssetsManifest returns the files recorded by the last install. Names
+// that would escape the directory are dropped.
+func readAssetsManifest(d Dir) map[string]string {
+	sums := make(map[string]string)
+	data, err := d.ReadFile(assetsManifest)
+	if err != nil {
+		return sums
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		sum, name, ok := strings.Cut(sc.Text(), "  ")
+		if ok && fs.ValidPath(name) && name != "." {
+			sums[name] = sum
+		}
+	}
+	return sums
+}
+
+func writeAssetsManifest(d Dir, sums map[string]string) error {
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[name], name)
+	}
+	return d.WriteFileAtomic(assetsManifest, buf.Bytes(), 0644)
+}
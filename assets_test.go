@@ -0,0 +1,66 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestInstallAssets(t *testing.T) {
+	unsetAll()
+	t.Setenv("HOME", t.TempDir())
+	x := New("app")
+	data := x.Data()
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(data, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	v1 := fstest.MapFS{
+		"templates/page.html":     {Data: []byte("page v1")},
+		"templates/old/item.html": {Data: []byte("item")},
+		"README":                  {Data: []byte("readme")},
+	}
+	if err := InstallAssets("app", v1); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "page v1", read("templates/page.html"))
+	eq(t, "item", read("templates/old/item.html"))
+	writeTestFile(t, filepath.Join(data, "user.txt"), "mine")
+
+	// Unchanged files are not rewritten.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	readme := filepath.Join(data, "README")
+	if err := os.Chtimes(readme, past, past); err != nil {
+		t.Fatal(err)
+	}
+	v2 := fstest.MapFS{
+		"templates/page.html": {Data: []byte("page v2")},
+		"README":              {Data: []byte("readme")},
+	}
+	if err := x.InstallAssets(v2); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "page v2", read("templates/page.html"))
+	eq(t, "mine", read("user.txt"))
+	if info, err := os.Stat(readme); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("unchanged file was rewritten: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(data, "templates", "old")); !os.IsNotExist(err) {
+		t.Errorf("expected stale assets to be removed, got %v", err)
+	}
+	manifest := readAssetsManifest(Dir(data))
+	eq(t, 2, len(manifest))
+	if _, ok := manifest["user.txt"]; ok {
+		t.Error("user files should not be in the manifest")
+	}
+
+	if err := x.InstallAssets(fstest.MapFS{assetsManifest: {Data: []byte("x")}}); err == nil {
+		t.Error("expected an error for the reserved name")
+	}
+}
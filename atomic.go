@@ -0,0 +1,93 @@
+package xdg
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to the file name inside the directory. The data
+// is written to a temporary file in the same directory, synced to disk, and
+// then renamed over the destination so readers never see a partially
+// written file. Missing parent directories are created with mode 0755,
+// since a Dir does not know which kind of directory it is in; use
+// (*XDG).Create first to apply DirMode. When a FileSystem
+// other than the host one is installed with SetFileSystem the data is
+// written with its WriteFile method instead.
+func (d Dir) WriteFileAtomic(name string, data []byte, perm fs.FileMode) error {
+	path, err := d.join(name)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(path, data, perm, 0755)
+	audit(OpWrite, path, int64(len(data)), perm, err)
+	return err
+}
+
+// writeFileAtomicIn is Dir(dir).WriteFileAtomic for a file in a directory
+// of the given kind, creating missing directories with DirMode(kind).
+func (xdg *XDG) writeFileAtomicIn(kind Kind, dir, name string, data []byte, perm fs.FileMode) error {
+	path, err := Dir(dir).join(name)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(path, data, perm, xdg.DirMode(kind))
+	audit(OpWrite, path, int64(len(data)), perm, err)
+	return err
+}
+
+func writeFileAtomic(path string, data []byte, perm, dirPerm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := mkdirAll(dir, dirPerm); err != nil {
+		return err
+	}
+	if !isOSFileSystem() {
+		// Only the host file system supports temp files and renames.
+		return fileSystem().WriteFile(path, data, perm)
+	}
+	tmp, err := os.CreateTemp(longPath(dir), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), longPath(path)); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes a directory entry to disk after a rename. Not every
+// platform supports syncing directories so errors are ignored.
+func syncDir(dir string) {
+	f, err := os.Open(longPath(dir))
+	if err != nil {
+		return
+	}
+	_ = f.Sync()
+	f.Close()
+}
+
+// join returns the path of name inside the directory, rejecting names that
+// are absolute or escape the directory.
+func (d Dir) join(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", &fs.PathError{Op: "join", Path: name, Err: ErrInvalidPath}
+	}
+	return filepath.Join(string(d), name), nil
+}
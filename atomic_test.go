@@ -0,0 +1,36 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	d := Dir(t.TempDir())
+	if err := d.WriteFileAtomic("state/last.json", []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(string(d), "state", "last.json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, `{"a":1}`, string(b))
+	info, _ := os.Stat(path)
+	eq(t, os.FileMode(0600), info.Mode().Perm())
+
+	if err = d.WriteFileAtomic("state/last.json", []byte(`{"a":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = os.ReadFile(path)
+	eq(t, `{"a":2}`, string(b))
+	entries, _ := os.ReadDir(filepath.Join(string(d), "state"))
+	eq(t, 1, len(entries))
+
+	for _, bad := range []string{"../x", "/etc/passwd", ""} {
+		if err = d.WriteFileAtomic(bad, nil, 0644); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package xdg
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Operations reported in an AuditRecord.
+const (
+	OpMkdir  = "mkdir"
+	OpWrite  = "write"
+	OpRemove = "remove"
+	OpRename = "rename"
+	OpChmod  = "chmod"
+)
+
+// AuditRecord describes a single filesystem mutation made by this package.
+type AuditRecord struct {
+	Op    string
+	Path  string
+	Bytes int64
+	Mode  fs.FileMode
+	Err   error
+}
+
+// Auditor receives a record for every file or directory created, written,
+// or removed by the helpers in this package.
+type Auditor interface {
+	Audit(AuditRecord)
+}
+
+// AuditorFunc adapts a function to the Auditor interface.
+type AuditorFunc func(AuditRecord)
+
+func (f AuditorFunc) Audit(r AuditRecord) { f(r) }
+
+var (
+	auditMu sync.RWMutex
+	auditor Auditor
+)
+
+// SetAuditor installs a package wide auditor. Passing nil disables
+// auditing.
+func SetAuditor(a Auditor) {
+	auditMu.Lock()
+	auditor = a
+	auditMu.Unlock()
+}
+
+func audit(op, path string, n int64, mode fs.FileMode, err error) {
+	auditMu.RLock()
+	a := auditor
+	auditMu.RUnlock()
+	if a != nil {
+		a.Audit(AuditRecord{Op: op, Path: path, Bytes: n, Mode: mode, Err: err})
+	}
+}
+
+func mkdirAll(path string, perm fs.FileMode) error {
+	err := fileSystem().MkdirAll(path, perm)
+	audit(OpMkdir, path, 0, perm|fs.ModeDir, err)
+	return err
+}
+
+func writeFile(path string, data []byte, perm fs.FileMode) error {
+	err := fileSystem().WriteFile(path, data, perm)
+	audit(OpWrite, path, int64(len(data)), perm, err)
+	return err
+}
+
+// writeFileFrom is writeFile with the contents read from r. The host file
+// system is written to as r is read; other file systems need the whole
+// file in memory.
+func writeFileFrom(path string, r io.Reader, size int64, perm fs.FileMode) error {
+	var err error
+	if isOSFileSystem() {
+		var f *os.File
+		if f, err = os.OpenFile(longPath(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm); err == nil {
+			_, err = io.Copy(f, r)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	} else {
+		var data []byte
+		if data, err = io.ReadAll(r); err == nil {
+			err = fileSystem().WriteFile(path, data, perm)
+		}
+	}
+	audit(OpWrite, path, size, perm, err)
+	return err
+}
+
+func remove(path string) error {
+	err := fileSystem().Remove(path)
+	audit(OpRemove, path, 0, 0, err)
+	return err
+}
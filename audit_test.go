@@ -0,0 +1,39 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditor(t *testing.T) {
+	var records []AuditRecord
+	SetAuditor(AuditorFunc(func(r AuditRecord) { records = append(records, r) }))
+	defer SetAuditor(nil)
+
+	dir := t.TempDir()
+	d := Dir(filepath.Join(dir, "a", "b"))
+	if err := d.Create(); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(string(d), "f")
+	if err := writeFile(file, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := remove(file); err != nil {
+		t.Fatal(err)
+	}
+	err := remove(file)
+	eq(t, 4, len(records))
+	eq(t, AuditRecord{Op: OpMkdir, Path: string(d), Mode: 0755 | fs.ModeDir}, records[0])
+	eq(t, AuditRecord{Op: OpWrite, Path: file, Bytes: 5, Mode: 0600}, records[1])
+	eq(t, AuditRecord{Op: OpRemove, Path: file}, records[2])
+	if !errors.Is(records[3].Err, fs.ErrNotExist) || records[3].Err != err {
+		t.Errorf("expected the error to be recorded, got %v", records[3].Err)
+	}
+
+	SetAuditor(nil)
+	_ = d.Create()
+	eq(t, 4, len(records))
+}
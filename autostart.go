@@ -0,0 +1,111 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harrybrwn/xdg/internal/gdbus"
+)
+
+var (
+	sandboxed = gdbus.Sandboxed
+	dbusCall  = gdbus.Call
+)
+
+// RequestAutostart asks for the application to be started when the user logs
+// in. See (*XDG).RequestAutostart.
+func RequestAutostart(name, reason string, cmd []string) error {
+	return newXdg(name).RequestAutostart(reason, cmd)
+}
+
+// RequestAutostart asks for the application to be started with cmd when the
+// user logs in. Inside a flatpak sandbox the request goes through the
+// background portal, otherwise an autostart desktop entry is written to
+// $XDG_CONFIG_HOME/autostart.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/autostart-spec/latest/
+//	https://flatpak.github.io/xdg-desktop-portal/docs/doc-org.freedesktop.portal.Background.html
+func (xdg *XDG) RequestAutostart(reason string, cmd []string) error {
+	if len(cmd) == 0 {
+		return errors.New("xdg: autostart command is empty")
+	}
+	if sandboxed() {
+		_, err := dbusCall(
+			context.Background(),
+			"org.freedesktop.portal.Desktop",
+			"/org/freedesktop/portal/desktop",
+			"org.freedesktop.portal.Background.RequestBackground",
+			gdbus.String(""),
+			gdbus.Dict(
+				"reason", gdbus.Variant(gdbus.String(reason)),
+				"autostart", gdbus.Variant("true"),
+				"commandline", gdbus.Variant(gdbus.Strings(cmd)),
+				"dbus-activatable", gdbus.Variant("false"),
+			),
+		)
+		return err
+	}
+	file := xdg.autostartFile()
+	if len(file) == 0 {
+		return errors.New("xdg: could not find autostart directory")
+	}
+	if err := mkdirAll(filepath.Dir(file), xdg.DirMode(KindConfig)); err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("[Desktop Entry]\n")
+	b.WriteString("Type=Application\n")
+	b.WriteString("Name=" + escapeValue(xdg.finder.Name()) + "\n")
+	if len(reason) > 0 {
+		b.WriteString("Comment=" + escapeValue(reason) + "\n")
+	}
+	b.WriteString("Exec=" + escapeValue(quoteExec(cmd)) + "\n")
+	b.WriteString("X-GNOME-Autostart-enabled=true\n")
+	return writeFile(file, []byte(b.String()), 0644)
+}
+
+// RemoveAutostart removes the autostart entry written by RequestAutostart.
+// It is not an error if there is no entry.
+func (xdg *XDG) RemoveAutostart() error {
+	file := xdg.autostartFile()
+	if len(file) == 0 {
+		return nil
+	}
+	err := remove(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (xdg *XDG) autostartFile() string {
+	base := xdg.baseDir(configHomeKey)
+	if len(base) == 0 {
+		return ""
+	}
+	return filepath.Join(base, "autostart", xdg.appID()+".desktop")
+}
+
+// quoteExec formats a command line for the Exec key of a desktop entry.
+func quoteExec(cmd []string) string {
+	args := make([]string, len(cmd))
+	for i, arg := range cmd {
+		arg = strings.ReplaceAll(arg, "%", "%%")
+		if len(arg) == 0 || strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+			r := strings.NewReplacer(`"`, `\"`, "`", "\\`", `$`, `\$`, `\`, `\\`)
+			arg = `"` + r.Replace(arg) + `"`
+		}
+		args[i] = arg
+	}
+	return strings.Join(args, " ")
+}
+
+// escapeValue applies the desktop entry string escapes.
+func escapeValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s)
+}
@@ -0,0 +1,72 @@
+package xdg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestAutostart(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(configHomeKey, dir)
+	old := sandboxed
+	defer func() { sandboxed = old }()
+	sandboxed = func() bool { return false }
+
+	if err := RequestAutostart("myapp", "sync", nil); err == nil {
+		t.Error("expected error for empty command")
+	}
+	err := RequestAutostart("myapp", "keep files in sync", []string{"/usr/bin/myapp", "--daemon", "a b", "100%"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "autostart", "myapp.desktop")
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "[Desktop Entry]\n"+
+		"Type=Application\n"+
+		"Name=myapp\n"+
+		"Comment=keep files in sync\n"+
+		"Exec=/usr/bin/myapp --daemon \"a b\" 100%%\n"+
+		"X-GNOME-Autostart-enabled=true\n", string(b))
+
+	x := newXdg("myapp")
+	if err = x.RemoveAutostart(); err != nil {
+		t.Fatal(err)
+	}
+	if exists(file) {
+		t.Error("autostart file should be removed")
+	}
+	if err = x.RemoveAutostart(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequestAutostart_Portal(t *testing.T) {
+	oldSandboxed, oldCall := sandboxed, dbusCall
+	defer func() { sandboxed, dbusCall = oldSandboxed, oldCall }()
+	sandboxed = func() bool { return true }
+	var method string
+	var args []string
+	dbusCall = func(_ context.Context, _, _, m string, a ...string) (string, error) {
+		method, args = m, a
+		return "", nil
+	}
+	if err := RequestAutostart("myapp", "sync", []string{"myapp", "-d"}); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "org.freedesktop.portal.Background.RequestBackground", method)
+	eq(t, "''", args[0])
+	eq(t, "{'reason': <'sync'>, 'autostart': <true>, 'commandline': <['myapp', '-d']>, 'dbus-activatable': <false>}", args[1])
+}
+
+func TestQuoteExec(t *testing.T) {
+	eq(t, `a "" "\$x" "back\\slash" "it's"`, quoteExec([]string{"a", "", "$x", `back\slash`, "it's"}))
+	if !strings.Contains(escapeValue(quoteExec([]string{`\`})), `\\\\`) {
+		t.Error("backslashes should be escaped twice")
+	}
+}
@@ -0,0 +1,29 @@
+package xdg
+
+// ConfigHome returns the base directory for user configuration files,
+// $XDG_CONFIG_HOME, without an application name.
+func ConfigHome() string { return baseXdg().baseDir(configHomeKey) }
+
+// DataHome returns the base directory for user data files, $XDG_DATA_HOME.
+func DataHome() string { return baseXdg().baseDir(dataHomeKey) }
+
+// CacheHome returns the base directory for user cache files,
+// $XDG_CACHE_HOME.
+func CacheHome() string { return baseXdg().baseDir(cacheHomeKey) }
+
+// StateHome returns the base directory for user state files,
+// $XDG_STATE_HOME.
+func StateHome() string { return baseXdg().baseDir(stateHomeKey) }
+
+// RuntimeDir returns $XDG_RUNTIME_DIR, without an application name, or the
+// empty string if it is not set.
+func RuntimeDir() string { return baseXdg().baseDir(runtimeDirKey) }
+
+// SystemConfigDirs returns the system configuration search path,
+// $XDG_CONFIG_DIRS, without an application name.
+func SystemConfigDirs() []string { return baseXdg().getDirs(configDirsKey) }
+
+// SystemDataDirs returns the system data search path, $XDG_DATA_DIRS.
+func SystemDataDirs() []string { return baseXdg().getDirs(dataDirsKey) }
+
+func baseXdg() *XDG { return newXdg("") }
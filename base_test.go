@@ -0,0 +1,22 @@
+package xdg
+
+import "testing"
+
+func TestBaseDirs(t *testing.T) {
+	unsetAll()
+	t.Setenv("HOME", "/home/t")
+	eq(t, "/home/t/.config", ConfigHome())
+	eq(t, "/home/t/.local/share", DataHome())
+	eq(t, "/home/t/.cache", CacheHome())
+	eq(t, "/home/t/.local/state", StateHome())
+	eq(t, "", RuntimeDir())
+	arrEq(t, []string{"/etc/xdg"}, SystemConfigDirs())
+	arrEq(t, []string{"/usr/local/share", "/usr/share"}, SystemDataDirs())
+
+	t.Setenv(configHomeKey, "/h/conf")
+	t.Setenv(runtimeDirKey, "/run/user/1000")
+	t.Setenv(dataDirsKey, "/a:/b")
+	eq(t, "/h/conf", ConfigHome())
+	eq(t, "/run/user/1000", RuntimeDir())
+	arrEq(t, []string{"/a", "/b"}, SystemDataDirs())
+}
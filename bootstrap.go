@@ -0,0 +1,35 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// EnsureConfigFile makes sure the application name has the config file
+// file. See (*XDG).EnsureConfigFile.
+func EnsureConfigFile(name, file string, defaults []byte) (path string, created bool, err error) {
+	return newXdg(name).EnsureConfigFile(file, defaults)
+}
+
+// EnsureConfigFile returns the config file that takes precedence, as found
+// by SearchConfigFile. If there is none anywhere on the search path the
+// defaults are written atomically to the file in the config home with mode
+// 0644, and created is true. A system wide copy in $XDG_CONFIG_DIRS counts
+// as existing and is never shadowed by the defaults.
+func (xdg *XDG) EnsureConfigFile(file string, defaults []byte) (path string, created bool, err error) {
+	path, err = xdg.SearchConfigFile(file)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return path, false, err
+	}
+	dir, err := xdg.ConfigE()
+	if err != nil {
+		return "", false, err
+	}
+	if path, err = Dir(dir).join(file); err != nil {
+		return "", false, err
+	}
+	if err = xdg.writeFileAtomicIn(KindConfig, dir, file, defaults, 0644); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
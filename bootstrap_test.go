@@ -0,0 +1,53 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsureConfigFile(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	x := New("app")
+
+	path, created, err := x.EnsureConfigFile("conf/app.toml", []byte("defaults"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Error("expected the file to be created")
+	}
+	eq(t, filepath.Join(x.Config(), "conf", "app.toml"), path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "defaults", string(b))
+
+	writeTestFile(t, path, "edited")
+	if path, created, err = EnsureConfigFile("app", "conf/app.toml", []byte("defaults")); err != nil || created {
+		t.Fatalf("expected the existing file, got %v, %v", created, err)
+	}
+	if b, _ = os.ReadFile(path); string(b) != "edited" {
+		t.Errorf("existing file was replaced: %q", b)
+	}
+
+	system := filepath.Join(tmp, "etc", "app", "system.toml")
+	writeTestFile(t, system, "system")
+	path, created, err = x.EnsureConfigFile("system.toml", []byte("defaults"))
+	if err != nil || created {
+		t.Fatalf("expected the system file, got %v, %v", created, err)
+	}
+	eq(t, system, path)
+	if exists(filepath.Join(x.Config(), "system.toml")) {
+		t.Error("system file should not be shadowed")
+	}
+
+	if _, _, err = x.EnsureConfigFile("../escape", nil); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
@@ -0,0 +1,471 @@
+package xdg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachePolicy limits the files kept in a cache directory. A zero field
+// disables that limit.
+type CachePolicy struct {
+	// MaxAge is how long a file is kept after it was last modified.
+	MaxAge time.Duration
+	// MaxBytes is the total size of the files in the directory. When it is
+	// exceeded the least recently modified files are removed first.
+	MaxBytes int64
+}
+
+// CleanCache applies p to the application's cache directory. See
+// CachePolicy.Clean.
+func (xdg *XDG) CleanCache(p CachePolicy) ([]string, error) {
+	dir, err := xdg.CacheE()
+	if err != nil {
+		return nil, err
+	}
+	return p.Clean(Dir(dir))
+}
+
+// Clean removes the files in dir that are older than MaxAge, then the oldest
+// remaining files until the directory fits in MaxBytes. Directories left
+// empty are removed, but dir itself is kept. It returns the paths of the
+// removed files. A missing directory is not an error.
+func (p CachePolicy) Clean(dir Dir) ([]string, error) {
+	removed, _, err := p.clean(dir, "")
+	return removed, err
+}
+
+// clean is Clean but also returns the size of the files left in dir. The
+// file named skip is neither counted nor removed.
+func (p CachePolicy) clean(dir Dir, skip string) ([]string, int64, error) {
+	type entry struct {
+		name  string
+		size  int64
+		mtime time.Time
+	}
+	var (
+		files []entry
+		total int64
+	)
+	err := dir.Walk(func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == "." && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() || name == skip {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, entry{name, info.Size(), info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mtime.Before(files[j].mtime) })
+
+	var (
+		removed []string
+		parents = make(map[string]bool)
+		cutoff  = time.Now().Add(-p.MaxAge)
+	)
+	for _, f := range files {
+		expired := p.MaxAge > 0 && f.mtime.Before(cutoff)
+		if !expired && (p.MaxBytes <= 0 || total <= p.MaxBytes) {
+			continue
+		}
+		full := filepath.Join(string(dir), filepath.FromSlash(f.name))
+		if err = remove(full); err != nil {
+			return removed, total, err
+		}
+		removed = append(removed, full)
+		total -= f.size
+		parents[path.Dir(f.name)] = true
+	}
+	return removed, total, pruneEmpty(dir, parents)
+}
+
+// GetOrCompute returns the path of the file cached under key in the
+// directory. The key is hashed into a stable file name. On a miss fn is
+// called to produce the contents, which are written atomically so that
+// concurrent readers only ever see a complete file. If fn fails nothing is
+// cached. See CacheStore for a cache with a size limit.
+func (d Dir) GetOrCompute(key string, fn func(w io.Writer) error) (string, error) {
+	return (&CacheStore{Dir: d}).GetOrCompute(key, fn)
+}
+
+// CacheStore is a cache of files keyed by strings in a directory. It is
+// safe for concurrent use. The zero value of every field other than Dir is
+// ready to use.
+type CacheStore struct {
+	// Dir is the directory the files are stored in.
+	Dir Dir
+	// Quota is applied whenever a write would take the store over
+	// Quota.MaxBytes, removing the least recently modified files first.
+	// Files older than Quota.MaxAge are removed at the same time.
+	Quota CachePolicy
+	// Background runs the eviction in a goroutine after the write instead
+	// of before it, so writes never wait for it. The store may then exceed
+	// its quota until the eviction finishes.
+	Background bool
+	// Sharded spreads the files over 256 subdirectories named after the
+	// first two hex digits of their hash, so stores with hundreds of
+	// thousands of entries do not end up with one huge directory. A
+	// sharded store also keeps an index of its keys in a file named
+	// .index so that List does not have to walk the tree.
+	Sharded bool
+
+	mu    sync.Mutex
+	used  int64
+	known bool
+	wg    sync.WaitGroup
+
+	// records and live count the lines in the index and the keys they
+	// describe, once indexed is set, so appendIndex knows when to compact.
+	records, live int
+	indexed       bool
+}
+
+// Path returns the file that key is cached in. The file may not exist.
+func (s *CacheStore) Path(key string) string {
+	h := cacheHash(key)
+	if s.Sharded {
+		return filepath.Join(string(s.Dir), h[:2], h)
+	}
+	return filepath.Join(string(s.Dir), h)
+}
+
+func cacheHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the path of the file cached under key, if there is one.
+func (s *CacheStore) Get(key string) (string, bool) {
+	path := s.Path(key)
+	_, err := fileSystem().Stat(path)
+	return path, err == nil
+}
+
+// Put stores data under key, replacing any previous value, and returns the
+// path of the file. The file is written atomically.
+func (s *CacheStore) Put(key string, data []byte) (string, error) {
+	n := int64(len(data))
+	path := s.Path(key)
+	if !s.Background {
+		// A replaced file frees its own size.
+		if err := s.evict(n - fileSize(path)); err != nil {
+			return "", err
+		}
+	}
+	// The eviction may have removed the old file, so it is measured again.
+	old, replaced := fileSize(path), exists(path)
+	err := writeFileAtomic(path, data, 0644, 0755)
+	audit(OpWrite, path, n, 0644, err)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.used += n - old
+	if s.Sharded {
+		err = s.appendIndex(CacheEntry{Key: key, Path: path, Size: n, Modified: time.Now()}, replaced)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	if s.Background {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.evict(0)
+		}()
+	}
+	return path, nil
+}
+
+// Writer returns a writer whose contents are stored under key by Put when
+// it is closed.
+func (s *CacheStore) Writer(key string) io.WriteCloser {
+	return &cacheWriter{s: s, key: key}
+}
+
+// GetOrCompute returns the path of the file cached under key. On a miss fn
+// is called to produce the contents, which are stored with Put. If fn fails
+// nothing is cached.
+func (s *CacheStore) GetOrCompute(key string, fn func(w io.Writer) error) (string, error) {
+	if path, ok := s.Get(key); ok {
+		return path, nil
+	}
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return "", err
+	}
+	return s.Put(key, buf.Bytes())
+}
+
+// Wait blocks until the evictions started in the background have finished.
+func (s *CacheStore) Wait() { s.wg.Wait() }
+
+// evict applies the quota if adding n bytes would exceed it. The size of
+// the store is only measured when it is not known or might be too large, so
+// writes that fit do not walk the directory.
+func (s *CacheStore) evict(n int64) error {
+	if s.Quota.MaxBytes <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.known && s.used+max(n, 0) <= s.Quota.MaxBytes {
+		return nil
+	}
+	p := s.Quota
+	// Leave room for the new file. A limit of zero would disable it, so a
+	// file larger than the quota empties the store down to 1 byte.
+	p.MaxBytes = max(p.MaxBytes-max(n, 0), 1)
+	removed, used, err := p.clean(s.Dir, s.indexName())
+	if err != nil {
+		s.known = false
+		return err
+	}
+	s.used, s.known = used, true
+	if s.Sharded && len(removed) > 0 {
+		return s.compact()
+	}
+	return nil
+}
+
+// cacheIndexName is the index of a sharded CacheStore.
+const cacheIndexName = ".index"
+
+// The index is compacted once it holds cacheIndexSlack times more records
+// than live keys, and at least cacheIndexMin records, so that rewriting the
+// same keys does not grow it without limit.
+const (
+	cacheIndexSlack = 4
+	cacheIndexMin   = 64
+)
+
+func (s *CacheStore) indexName() string {
+	if s.Sharded {
+		return cacheIndexName
+	}
+	return ""
+}
+
+// CacheEntry is a file listed in the index of a sharded CacheStore.
+type CacheEntry struct {
+	Key      string
+	Path     string
+	Size     int64
+	Modified time.Time
+}
+
+// List returns the entries of a sharded store from its index, in the order
+// they were written. Files removed by another process or by hand are
+// listed until the next eviction or Compact.
+func (s *CacheStore) List() ([]CacheEntry, error) {
+	if !s.Sharded {
+		return nil, errors.New("xdg: List requires a sharded CacheStore")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, _, err := s.readIndex()
+	return entries, err
+}
+
+// Compact rewrites the index of a sharded store, dropping the entries whose
+// files no longer exist.
+func (s *CacheStore) Compact() error {
+	if !s.Sharded {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compact()
+}
+
+func (s *CacheStore) compact() error {
+	entries, _, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	var (
+		buf  bytes.Buffer
+		live int
+	)
+	for _, e := range entries {
+		if _, err = fileSystem().Stat(e.Path); err == nil {
+			buf.WriteString(s.indexLine(e))
+			live++
+		}
+	}
+	path := filepath.Join(string(s.Dir), cacheIndexName)
+	err = writeFileAtomic(path, buf.Bytes(), 0644, 0755)
+	audit(OpWrite, path, int64(buf.Len()), 0644, err)
+	if err == nil {
+		s.records, s.live, s.indexed = live, live, true
+	}
+	return err
+}
+
+// readIndex parses the index, keeping the last entry written for each key,
+// and also returns the number of records in it. Lines are
+// "<hash> <size> <unix nanoseconds> <quoted key>".
+func (s *CacheStore) readIndex() ([]CacheEntry, int, error) {
+	data, err := s.Dir.ReadFile(cacheIndexName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+	var (
+		all  []CacheEntry
+		last = make(map[string]int)
+	)
+	for _, line := range strings.Split(string(data), "\n") {
+		f := strings.SplitN(line, " ", 4)
+		if len(f) != 4 {
+			continue
+		}
+		size, err1 := strconv.ParseInt(f[1], 10, 64)
+		nsec, err2 := strconv.ParseInt(f[2], 10, 64)
+		key, err3 := strconv.Unquote(f[3])
+		if err1 != nil || err2 != nil || err3 != nil || cacheHash(key) != f[0] {
+			continue
+		}
+		last[key] = len(all)
+		all = append(all, CacheEntry{Key: key, Path: s.Path(key), Size: size, Modified: time.Unix(0, nsec)})
+	}
+	entries := make([]CacheEntry, 0, len(last))
+	for i, e := range all {
+		if last[e.Key] == i {
+			entries = append(entries, e)
+		}
+	}
+	return entries, len(all), nil
+}
+
+func (s *CacheStore) indexLine(e CacheEntry) string {
+	return fmt.Sprintf("%s %d %d %s\n", cacheHash(e.Key), e.Size, e.Modified.UnixNano(), strconv.Quote(e.Key))
+}
+
+// appendIndex adds e to the index, where replaced tells whether e.Key
+// already had a file. On the host file system the line is appended so that
+// other processes writing to the store do not lose entries. The index is
+// compacted when it has grown too far past the number of keys.
+func (s *CacheStore) appendIndex(e CacheEntry, replaced bool) error {
+	if !s.indexed {
+		entries, records, err := s.readIndex()
+		if err != nil {
+			return err
+		}
+		s.records, s.live, s.indexed = records, len(entries), true
+	}
+	path := filepath.Join(string(s.Dir), cacheIndexName)
+	line := s.indexLine(e)
+	var err error
+	if !isOSFileSystem() {
+		var data []byte
+		data, err = s.Dir.ReadFile(cacheIndexName)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		err = writeFile(path, append(data, line...), 0644)
+	} else {
+		var f *os.File
+		f, err = os.OpenFile(longPath(path), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err == nil {
+			_, err = f.WriteString(line)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		audit(OpWrite, path, int64(len(line)), 0644, err)
+	}
+	if err != nil {
+		return err
+	}
+	s.records++
+	if !replaced {
+		s.live++
+	}
+	if s.records >= cacheIndexMin && s.records > cacheIndexSlack*s.live {
+		return s.compact()
+	}
+	return nil
+}
+
+// fileSize returns the size of the file at path, or 0 if it cannot be
+// read.
+func fileSize(path string) int64 {
+	info, err := fileSystem().Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+type cacheWriter struct {
+	bytes.Buffer
+	s      *CacheStore
+	key    string
+	closed bool
+}
+
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fs.ErrClosed
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *cacheWriter) Close() error {
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+	_, err := w.s.Put(w.key, w.Bytes())
+	return err
+}
+
+// pruneEmpty removes the directories in names, and then their parents, if
+// they are empty. The root "." is never removed.
+func pruneEmpty(dir Dir, names map[string]bool) error {
+	for len(names) > 0 {
+		next := make(map[string]bool)
+		for name := range names {
+			if name == "." {
+				continue
+			}
+			entries, err := dir.ReadDir(name)
+			if err != nil || len(entries) > 0 {
+				continue
+			}
+			if err = remove(filepath.Join(string(dir), filepath.FromSlash(name))); err != nil {
+				return err
+			}
+			next[path.Dir(name)] = true
+		}
+		names = next
+	}
+	return nil
+}
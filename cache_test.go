@@ -0,0 +1,259 @@
+package xdg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCacheFile(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	writeTestFile(t, path, string(make([]byte, size)))
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCachePolicy_Clean(t *testing.T) {
+	d := Dir(t.TempDir())
+	writeCacheFile(t, filepath.Join(string(d), "old", "a"), 10, 48*time.Hour)
+	writeCacheFile(t, filepath.Join(string(d), "b"), 10, 3*time.Hour)
+	writeCacheFile(t, filepath.Join(string(d), "c"), 10, 2*time.Hour)
+	writeCacheFile(t, filepath.Join(string(d), "d"), 10, time.Hour)
+
+	removed, err := CachePolicy{MaxAge: 24 * time.Hour}.Clean(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{filepath.Join(string(d), "old", "a")}, removed)
+	if d.Append("old").Exists() {
+		t.Error("empty directory should be removed")
+	}
+
+	removed, err = CachePolicy{MaxBytes: 15}.Clean(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{filepath.Join(string(d), "b"), filepath.Join(string(d), "c")}, removed)
+	if !d.Append("d").Exists() || !d.Exists() {
+		t.Error("newest file and the cache directory should be kept")
+	}
+
+	removed, err = CachePolicy{}.Clean(d)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("zero policy should remove nothing, got %v, %v", removed, err)
+	}
+	if _, err = (CachePolicy{MaxAge: time.Hour}).Clean(d.Append("missing")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCleanCache(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(cacheHomeKey, tmp)
+	writeCacheFile(t, filepath.Join(tmp, "app", "x"), 1, 2*time.Hour)
+	removed, err := New("app").CleanCache(CachePolicy{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{filepath.Join(tmp, "app", "x")}, removed)
+}
+
+func TestGetOrCompute(t *testing.T) {
+	d := Dir(t.TempDir())
+	calls := 0
+	compute := func(w io.Writer) error {
+		calls++
+		_, err := io.WriteString(w, "result")
+		return err
+	}
+	p1, err := d.GetOrCompute("https://example.com/a", compute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := d.GetOrCompute("https://example.com/a", compute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, p1, p2)
+	eq(t, 1, calls)
+	eq(t, string(d), filepath.Dir(p1))
+	b, err := os.ReadFile(p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "result", string(b))
+
+	p3, err := d.GetOrCompute("https://example.com/b", compute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p3 == p1 {
+		t.Error("different keys should not share a file")
+	}
+
+	fail := errors.New("fail")
+	_, err = d.GetOrCompute("c", func(io.Writer) error { return fail })
+	if !errors.Is(err, fail) {
+		t.Errorf("expected compute error, got %v", err)
+	}
+	entries, _ := d.ReadDir(".")
+	eq(t, 2, len(entries))
+}
+
+func TestCacheStoreQuota(t *testing.T) {
+	for _, background := range []bool{false, true} {
+		d := Dir(t.TempDir())
+		writeCacheFile(t, filepath.Join(string(d), "old"), 10, 2*time.Hour)
+		writeCacheFile(t, filepath.Join(string(d), "older"), 10, 3*time.Hour)
+		s := &CacheStore{Dir: d, Quota: CachePolicy{MaxBytes: 25}, Background: background}
+
+		path, err := s.Put("a", make([]byte, 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Wait()
+		if d.Append("older").Exists() || !d.Append("old").Exists() {
+			t.Errorf("background=%v: expected only the oldest file to be evicted", background)
+		}
+		if got, ok := s.Get("a"); !ok || got != path {
+			t.Errorf("background=%v: expected a hit for a, got %q, %v", background, got, ok)
+		}
+
+		w := s.Writer("b")
+		if _, err = w.Write(make([]byte, 10)); err != nil {
+			t.Fatal(err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		s.Wait()
+		if d.Append("old").Exists() {
+			t.Errorf("background=%v: expected old to be evicted", background)
+		}
+		if _, ok := s.Get("b"); !ok {
+			t.Errorf("background=%v: expected a hit for b", background)
+		}
+		entries, _ := d.ReadDir(".")
+		eq(t, 2, len(entries))
+	}
+}
+
+func TestCacheStoreLargeFile(t *testing.T) {
+	d := Dir(t.TempDir())
+	s := &CacheStore{Dir: d, Quota: CachePolicy{MaxBytes: 5}}
+	if _, err := s.Put("a", []byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Put("b", []byte("too large")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("a"); ok {
+		t.Error("a should be evicted to make room")
+	}
+	if _, ok := s.Get("b"); !ok {
+		t.Error("the file being written is always kept")
+	}
+}
+
+func TestCacheStoreSharded(t *testing.T) {
+	d := Dir(t.TempDir())
+	s := &CacheStore{Dir: d, Sharded: true, Quota: CachePolicy{MaxBytes: 25}}
+	for _, key := range []string{"a", "b", "a"} {
+		if _, err := s.Put(key, make([]byte, 10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := s.Path("a")
+	eq(t, filepath.Join(string(d), filepath.Base(path)[:2], filepath.Base(path)), path)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(entries))
+	eq(t, "b", entries[0].Key)
+	eq(t, "a", entries[1].Key)
+	eq(t, int64(10), entries[1].Size)
+
+	// "b" is the oldest file and is evicted, which compacts the index.
+	old := time.Now().Add(-time.Hour)
+	if err = os.Chtimes(s.Path("b"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Put(`c "quoted"`, make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err = s.List(); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(entries))
+	eq(t, "a", entries[0].Key)
+	eq(t, `c "quoted"`, entries[1].Key)
+	if !d.Append(cacheIndexName).Exists() {
+		t.Error("the index must not be evicted")
+	}
+
+	if err = os.Remove(s.Path("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ = s.List(); len(entries) != 1 {
+		t.Errorf("expected compact to drop the removed file, got %+v", entries)
+	}
+	if _, err = (&CacheStore{Dir: d}).List(); err == nil {
+		t.Error("expected an error listing an unsharded store")
+	}
+}
+
+func TestCacheStoreReplace(t *testing.T) {
+	d := Dir(t.TempDir())
+	s := &CacheStore{Dir: d, Quota: CachePolicy{MaxBytes: 25}}
+	if _, err := s.Put("b", make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := s.Put("a", make([]byte, 10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eq(t, int64(20), s.used)
+	if _, ok := s.Get("b"); !ok {
+		t.Error("replacing a should not evict b")
+	}
+}
+
+func TestCacheStoreIndexGrowth(t *testing.T) {
+	d := Dir(t.TempDir())
+	s := &CacheStore{Dir: d, Sharded: true}
+	for i := 0; i < 10*cacheIndexMin; i++ {
+		if _, err := s.Put("a", []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Put("b", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := d.ReadFile(cacheIndexName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines >= cacheIndexMin {
+		t.Errorf("index should be compacted, has %d lines", lines)
+	}
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(entries))
+}
@@ -0,0 +1,98 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	caseMu    sync.Mutex
+	caseCache = make(map[string]bool)
+	// caseProbe is replaced in tests, since case-insensitive directories
+	// cannot be created on most Linux systems.
+	caseProbe = probeCase
+)
+
+// caseInsensitive reports whether the host file system matches names in dir
+// without regard to case, as the default volumes on macOS and Windows do.
+// The answer is probed once per directory because a case-sensitive volume
+// can be mounted on those systems and the reverse on Linux. Directories on
+// a FileSystem set with SetFileSystem are treated as case-sensitive.
+func caseInsensitive(dir string) bool {
+	if !isOSFileSystem() {
+		return false
+	}
+	caseMu.Lock()
+	defer caseMu.Unlock()
+	v, ok := caseCache[dir]
+	if !ok {
+		v = caseProbe(dir)
+		caseCache[dir] = v
+	}
+	return v
+}
+
+// probeCase looks up an entry of dir with the case of its letters swapped.
+// If dir has no entry with letters in its name, dir itself and then each
+// of its parents are looked up in the directory above, and when nothing on
+// the way has letters the platform default is used.
+func probeCase(dir string) bool {
+	if f, err := os.Open(longPath(dir)); err == nil {
+		names, _ := f.Readdirnames(32)
+		f.Close()
+		for _, name := range names {
+			if same, ok := sameSwapped(dir, name); ok {
+				return same
+			}
+		}
+	}
+	for p := filepath.Clean(dir); ; {
+		parent := filepath.Dir(p)
+		if parent == p {
+			break
+		}
+		if same, ok := sameSwapped(parent, filepath.Base(p)); ok {
+			return same
+		}
+		p = parent
+	}
+	return runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+}
+
+// sameSwapped reports whether name in dir is also found with the case of
+// its letters swapped. ok is false if the probe says nothing, because name
+// has no letters or cannot be found itself.
+func sameSwapped(dir, name string) (same, ok bool) {
+	swapped := swapCase(name)
+	if swapped == name {
+		return false, false
+	}
+	a, err := os.Stat(longPath(filepath.Join(dir, name)))
+	if err != nil {
+		return false, false
+	}
+	b, err := os.Stat(longPath(filepath.Join(dir, swapped)))
+	if err != nil {
+		return false, errors.Is(err, fs.ErrNotExist)
+	}
+	return os.SameFile(a, b), true
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// foldName is the key names are compared by in a case-insensitive
+// directory.
+func foldName(name string) string { return strings.ToLower(name) }
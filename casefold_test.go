@@ -0,0 +1,89 @@
+package xdg
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withCaseProbe makes the directories for which insensitive returns true
+// behave as if they were on a case-insensitive file system.
+func withCaseProbe(t *testing.T, insensitive func(dir string) bool) {
+	t.Helper()
+	old := caseProbe
+	reset := func() {
+		caseMu.Lock()
+		caseCache = make(map[string]bool)
+		caseMu.Unlock()
+	}
+	caseProbe = insensitive
+	reset()
+	t.Cleanup(func() {
+		caseProbe = old
+		reset()
+	})
+}
+
+func TestProbeCase(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "sub", "abc"))
+	_, err := os.Stat(filepath.Join(dir, "sub", "ABC"))
+	eq(t, err == nil, probeCase(filepath.Join(dir, "sub")))
+
+	// No entry has letters in its name, so dir is probed in its parent.
+	digits := filepath.Join(dir, "sub", "digits")
+	writeTestFile(t, filepath.Join(digits, "123"))
+	eq(t, err == nil, probeCase(digits))
+	eq(t, "aBC.d", swapCase("Abc.D"))
+}
+
+func TestUnionFSCaseInsensitive(t *testing.T) {
+	tmp := t.TempDir()
+	upper, lower := filepath.Join(tmp, "upper"), filepath.Join(tmp, "lower")
+	writeTestFile(t, filepath.Join(upper, "Theme.css"))
+	writeTestFile(t, filepath.Join(lower, "theme.css"))
+	writeTestFile(t, filepath.Join(lower, "extra"))
+	names := func() []string {
+		entries, err := fs.ReadDir(newUnionFS([]string{upper, lower}), ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	withCaseProbe(t, func(string) bool { return false })
+	arrEq(t, []string{"Theme.css", "extra", "theme.css"}, names())
+
+	withCaseProbe(t, func(dir string) bool { return strings.HasPrefix(dir, upper) })
+	arrEq(t, []string{"Theme.css", "extra"}, names())
+}
+
+func TestIndexCaseInsensitive(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(dataHomeKey, filepath.Join(tmp, "home"))
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "usr"))
+	home := filepath.Join(tmp, "home", "icons")
+	writeTestFile(t, filepath.Join(home, "App.png"))
+	writeTestFile(t, filepath.Join(tmp, "usr", "icons", "app.png"))
+	withCaseProbe(t, func(dir string) bool { return dir == home })
+
+	ix, err := New("").BuildIndex(KindData, "icons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{"App.png"}, ix.Names())
+	for _, name := range []string{"App.png", "app.png", "APP.PNG"} {
+		r, ok := ix.Lookup(name)
+		if !ok {
+			t.Fatalf("%s not found", name)
+		}
+		eq(t, filepath.Join(home, "App.png"), r.Path)
+	}
+}
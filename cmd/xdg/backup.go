@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+)
+
+func runBackup(c *cli, args []string) error {
+	fs := c.flags("backup")
+	out := fs.String("o", "", "write the archive to `file` (default <app>-backup.tar.gz)")
+	kinds := fs.String("kind", "", "comma separated `list` of directories to back up (default config,data,state)")
+	app, err := c.appArg(fs, args)
+	if err != nil {
+		return err
+	}
+	ks, err := parseKinds(*kinds)
+	if err != nil {
+		return err
+	}
+	if len(*out) == 0 {
+		*out = app + "-backup.tar.gz"
+	}
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	err = xdg.New(app).Export(f, ks...)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+	fmt.Fprintln(c.stdout, *out)
+	return nil
+}
+
+func runRestore(c *cli, args []string) error {
+	fs := c.flags("restore")
+	in := fs.String("i", "", "read the archive from `file`")
+	kinds := fs.String("kind", "", "comma separated `list` of directories to restore (default all in the archive)")
+	overwrite := fs.Bool("overwrite", false, "empty each restored directory first")
+	merge := fs.Bool("merge", false, "replace existing files but keep the ones not in the archive")
+	dryRun := fs.Bool("dry-run", false, "print what would change without changing anything")
+	app, err := c.appArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(*in) == 0 || *overwrite && *merge {
+		fs.Usage()
+		return errUsage
+	}
+	opts := xdg.RestoreOptions{DryRun: *dryRun}
+	if opts.Kinds, err = parseKinds(*kinds); err != nil {
+		return err
+	}
+	switch {
+	case *overwrite:
+		opts.Policy = xdg.RestoreOverwrite
+	case *merge:
+		opts.Policy = xdg.RestoreMerge
+	}
+	if *dryRun {
+		opts.Report = func(op, path string) { fmt.Fprintf(c.stdout, "%-6s %s\n", op, path) }
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xdg.New(app).Import(f, opts)
+}
+
+// parseKinds parses a comma separated list of kind names.
+func parseKinds(list string) ([]xdg.Kind, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+	var kinds []xdg.Kind
+	for _, name := range strings.Split(list, ",") {
+		var k xdg.Kind
+		if err := k.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return nil, err
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestBackupRestore(t *testing.T) {
+	x := xdgtest.SandboxApp(t, "myapp")
+	conf := filepath.Join(x.Config(), "settings.toml")
+	state := filepath.Join(x.State(), "history")
+	xdgtest.WriteFile(t, conf, "a = 1\n")
+	xdgtest.WriteFile(t, state, "one\n")
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+
+	out, _, code := runCLI(t, "backup", "-o", archive, "--kind", "config,state", "myapp")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if out != archive+"\n" {
+		t.Errorf("got %q", out)
+	}
+	if _, _, code = runCLI(t, "backup", "-o", archive, "myapp"); code != 1 {
+		t.Error("backup should not replace an existing file")
+	}
+
+	xdgtest.WriteFile(t, conf, "a = 2\n")
+	extra := filepath.Join(x.Config(), "extra")
+	xdgtest.WriteFile(t, extra, "")
+	out, _, code = runCLI(t, "restore", "myapp", "-i", archive, "--overwrite", "--dry-run", "--kind", "config")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if !strings.Contains(out, "remove "+extra+"\n") || !strings.Contains(out, "write  "+conf+"\n") || strings.Contains(out, state) {
+		t.Errorf("wrong dry run output:\n%s", out)
+	}
+	if b, _ := os.ReadFile(conf); string(b) != "a = 2\n" {
+		t.Error("dry run changed a file")
+	}
+
+	if _, _, code = runCLI(t, "restore", "-i", archive, "--merge", "myapp"); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if b, _ := os.ReadFile(conf); string(b) != "a = 1\n" {
+		t.Errorf("got %q", b)
+	}
+	if _, err := os.Stat(extra); err != nil {
+		t.Error("merge should keep files not in the archive")
+	}
+	if _, _, code = runCLI(t, "restore", "-i", archive, "--merge", "--overwrite", "myapp"); code != 2 {
+		t.Errorf("expected a usage error, got %d", code)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+// diffContext is the number of unchanged lines around each hunk.
+const diffContext = 3
+
+func runDiff(c *cli, args []string) error {
+	fs := c.flags("diff")
+	keys := fs.Bool("keys", false, "compare settings key by key, for JSON and .ini style files")
+	pos, err := c.parse(fs, args, 3)
+	if err != nil {
+		return err
+	}
+	kind, app, file := pos[0], pos[1], pos[2]
+	x := xdg.New(app)
+	var home string
+	var dirs []string
+	switch kind {
+	case "config":
+		home, dirs = x.Config(), x.ConfigDirs()
+	case "data":
+		home, dirs = x.Data(), x.DataDirs()
+	default:
+		fs.Usage()
+		return errUsage
+	}
+	userPath := filepath.Join(home, file)
+	user, err := readOptional(userPath)
+	if err != nil {
+		return err
+	}
+	sysPath := "/dev/null"
+	var sys []byte
+	for _, d := range dirs {
+		p := filepath.Join(d, file)
+		if sys, err = readOptional(p); err != nil {
+			return err
+		} else if sys != nil {
+			sysPath = p
+			break
+		}
+	}
+	if user == nil && sys == nil {
+		return fmt.Errorf("%s: %w", file, os.ErrNotExist)
+	}
+	if user == nil {
+		userPath = "/dev/null"
+	}
+	if *keys {
+		return diffKeys(c.stdout, file, sys, user)
+	}
+	unifiedDiff(c.stdout, sysPath, userPath, splitLines(sys), splitLines(user))
+	return nil
+}
+
+// readOptional reads path, returning nil without an error if it does not
+// exist.
+func readOptional(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if b == nil && err == nil {
+		b = []byte{}
+	}
+	return b, err
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// unifiedDiff writes the differences between a and b in unified format.
+// Config files are small, so the longest common subsequence is computed
+// directly.
+func unifiedDiff(w io.Writer, nameA, nameB string, a, b []string) {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	type op struct {
+		kind byte // ' ', '-' or '+'
+		line string
+		i, j int // positions in a and b before the op
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i], i, j})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	header := false
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// Grow the hunk until the changes are more than two contexts apart.
+		start := max(k-diffContext, 0)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = run
+		}
+		if !header {
+			fmt.Fprintf(w, "--- %s\n+++ %s\n", nameA, nameB)
+			header = true
+		}
+		var na, nb int
+		for _, o := range ops[start:end] {
+			if o.kind != '+' {
+				na++
+			}
+			if o.kind != '-' {
+				nb++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(ops[start].i, na), hunkRange(ops[start].j, nb))
+		for _, o := range ops[start:end] {
+			fmt.Fprintf(w, "%c%s", o.kind, o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				fmt.Fprint(w, "\n\\ No newline at end of file\n")
+			}
+		}
+		k = end
+	}
+}
+
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// diffKeys compares the settings of two files key by key. JSON objects are
+// flattened into dotted paths and key files into group/key pairs.
+func diffKeys(w io.Writer, file string, sys, user []byte) error {
+	flatten := flattenKeyFile
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		flatten = flattenJSON
+	}
+	a, err := flatten(sys)
+	if err != nil {
+		return fmt.Errorf("system copy: %w", err)
+	}
+	b, err := flatten(user)
+	if err != nil {
+		return fmt.Errorf("user copy: %w", err)
+	}
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		if inA && inB && va == vb {
+			continue
+		}
+		if inA {
+			fmt.Fprintf(w, "-%s = %s\n", k, va)
+		}
+		if inB {
+			fmt.Fprintf(w, "+%s = %s\n", k, vb)
+		}
+	}
+	return nil
+}
+
+func flattenJSON(data []byte) (map[string]string, error) {
+	out := make(map[string]string)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return out, nil
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			for k, child := range m {
+				if len(prefix) > 0 {
+					k = prefix + "." + k
+				}
+				walk(k, child)
+			}
+			return
+		}
+		b, _ := json.Marshal(v)
+		out[prefix] = string(b)
+	}
+	walk("", v)
+	return out, nil
+}
+
+func flattenKeyFile(data []byte) (map[string]string, error) {
+	f, err := keyfile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	for _, g := range f.Groups() {
+		for _, k := range g.Keys() {
+			v, _ := g.Raw(k)
+			out[g.Name()+"/"+k] = v
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestDiff(t *testing.T) {
+	x := xdgtest.SandboxApp(t, "myapp")
+	xdgtest.WriteFile(t, filepath.Join(x.ConfigDirs()[0], "app.ini"), "[main]\na=1\nb=2\nc=3\n")
+	xdgtest.WriteFile(t, filepath.Join(x.Config(), "app.ini"), "[main]\na=1\nb=5\nc=3\nd=4\n")
+
+	out, _, code := runCLI(t, "diff", "config", "myapp", "app.ini")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	want := "--- " + filepath.Join(x.ConfigDirs()[0], "app.ini") + "\n" +
+		"+++ " + filepath.Join(x.Config(), "app.ini") + "\n" +
+		"@@ -1,4 +1,5 @@\n" +
+		" [main]\n a=1\n-b=2\n+b=5\n c=3\n+d=4\n"
+	if out != want {
+		t.Errorf("wrong diff:\n%s\nwant:\n%s", out, want)
+	}
+
+	out, _, code = runCLI(t, "diff", "--keys", "config", "myapp", "app.ini")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if want = "-main/b = 2\n+main/b = 5\n+main/d = 4\n"; out != want {
+		t.Errorf("wrong key diff:\n%s\nwant:\n%s", out, want)
+	}
+
+	xdgtest.WriteFile(t, filepath.Join(x.DataDirs()[0], "s.json"), `{"a":{"b":1,"c":[1]},"d":true}`)
+	xdgtest.WriteFile(t, filepath.Join(x.Data(), "s.json"), `{"a":{"b":2,"c":[1]}}`)
+	out, _, code = runCLI(t, "diff", "data", "myapp", "s.json", "--keys")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if want = "-a.b = 1\n+a.b = 2\n-d = true\n"; out != want {
+		t.Errorf("wrong json key diff:\n%s\nwant:\n%s", out, want)
+	}
+
+	// Identical files print nothing.
+	xdgtest.WriteFile(t, filepath.Join(x.Config(), "app.ini"), "[main]\na=1\nb=2\nc=3\n")
+	if out, _, _ = runCLI(t, "diff", "config", "myapp", "app.ini"); out != "" {
+		t.Errorf("expected no output, got\n%s", out)
+	}
+	// Only a system copy: everything is removed relative to /dev/null.
+	xdgtest.WriteFile(t, filepath.Join(x.ConfigDirs()[0], "only"), "x\n")
+	out, _, _ = runCLI(t, "diff", "config", "myapp", "only")
+	if want = "--- " + filepath.Join(x.ConfigDirs()[0], "only") + "\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n"; out != want {
+		t.Errorf("wrong diff:\n%s\nwant:\n%s", out, want)
+	}
+	if _, _, code = runCLI(t, "diff", "config", "myapp", "missing"); code != 1 {
+		t.Errorf("expected an error for a missing file, got %d", code)
+	}
+	if _, _, code = runCLI(t, "diff", "cache", "myapp", "x"); code == 0 {
+		t.Error("expected a usage error")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+func runEdit(c *cli, args []string) error {
+	fs := c.flags("edit")
+	validate := fs.Bool("validate", false, "check that the file still parses after editing")
+	pos, err := c.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	app, file := pos[0], pos[1]
+	path, err := userConfigFile(xdg.New(app), file)
+	if err != nil {
+		return err
+	}
+	editor := strings.Fields(editorCommand())
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", editor[0], err)
+	}
+	if !*validate {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = validateConfig(file, data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// userConfigFile returns the path of file in the config home of x. If the
+// user has no copy yet it is created from the first system copy in
+// $XDG_CONFIG_DIRS, or empty if there is none, so the editor always opens
+// the file that will take precedence.
+func userConfigFile(x *xdg.XDG, file string) (string, error) {
+	if !filepath.IsLocal(file) {
+		return "", fmt.Errorf("%s: %w", file, xdg.ErrInvalidPath)
+	}
+	home, err := x.ConfigE()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(home, file)
+	if _, err = os.Stat(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return path, err
+	}
+	var defaults []byte
+	for _, d := range x.ConfigDirs() {
+		if defaults, err = readOptional(filepath.Join(d, file)); err != nil {
+			return "", err
+		} else if defaults != nil {
+			break
+		}
+	}
+	if err = xdg.Dir(home).WriteFileAtomic(file, defaults, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// editorCommand returns the editor to run, in the same order git uses.
+func editorCommand() string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(key)); len(e) > 0 {
+			return e
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// validateConfig parses data with the codec matching the extension of file.
+// Files in a format that is not known are accepted as is.
+func validateConfig(file string, data []byte) error {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		var v any
+		return json.Unmarshal(data, &v)
+	case ".ini", ".conf", ".desktop", ".list":
+		_, err := keyfile.Parse(bytes.NewReader(data))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test editor is a shell script")
+	}
+	x := xdgtest.SandboxApp(t, "myapp")
+	editor := filepath.Join(t.TempDir(), "editor")
+	xdgtest.WriteFile(t, editor, "#!/bin/sh\necho \"$APPEND\" >> \"$1\"\n")
+	if err := os.Chmod(editor, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", editor)
+	t.Setenv("APPEND", "b=2")
+	xdgtest.WriteFile(t, filepath.Join(x.ConfigDirs()[0], "app.ini"), "[main]\na=1\n")
+
+	if _, _, code := runCLI(t, "edit", "--validate", "myapp", "app.ini"); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	user := filepath.Join(x.Config(), "app.ini")
+	b, err := os.ReadFile(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[main]\na=1\nb=2\n" {
+		t.Errorf("user copy should start from the system default, got %q", b)
+	}
+
+	// No default anywhere: the editor starts from an empty file.
+	t.Setenv("APPEND", `{"a":1}`)
+	if _, _, code := runCLI(t, "edit", "myapp", "new.json", "--validate"); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if b, _ = os.ReadFile(filepath.Join(x.Config(), "new.json")); string(b) != "{\"a\":1}\n" {
+		t.Errorf("got %q", b)
+	}
+
+	t.Setenv("APPEND", "{")
+	if _, errOut, code := runCLI(t, "edit", "--validate", "myapp", "new.json"); code != 1 {
+		t.Errorf("expected validation to fail, got %d: %s", code, errOut)
+	}
+	if _, _, code := runCLI(t, "edit", "myapp", "../escape"); code != 1 {
+		t.Errorf("expected an error for a path outside the config dir, got %d", code)
+	}
+}
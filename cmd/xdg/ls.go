@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// lsEntry is a file printed by ls.
+type lsEntry struct {
+	Kind    xdg.Kind    `json:"kind"`
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"modTime"`
+}
+
+func runLs(c *cli, args []string) error {
+	fs := c.flags("ls")
+	kind := fs.String("kind", "", "only list the config, data, cache, state, or runtime directory")
+	long := fs.Bool("long", false, "print the mode, size, and modification time of each file")
+	asJSON := fs.Bool("json", false, "print the files as a JSON array")
+	app, err := c.appArg(fs, args)
+	if err != nil {
+		return err
+	}
+	kinds := xdg.Kinds
+	if len(*kind) > 0 {
+		var k xdg.Kind
+		if err = k.UnmarshalText([]byte(*kind)); err != nil {
+			return err
+		}
+		kinds = []xdg.Kind{k}
+	}
+	entries, err := listFiles(xdg.New(app), kinds)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	for _, e := range entries {
+		if *long {
+			fmt.Fprintf(c.stdout, "%-8s %s %10d %s %s\n", e.Kind, e.Mode, e.Size, e.ModTime.Format(time.DateTime), e.Path)
+		} else {
+			fmt.Fprintln(c.stdout, e.Path)
+		}
+	}
+	return nil
+}
+
+// listFiles walks the directories of the given kinds. Directories that
+// cannot be resolved or do not exist are skipped, and a directory shared by
+// several kinds, as on macOS, is only listed once.
+func listFiles(x *xdg.XDG, kinds []xdg.Kind) ([]lsEntry, error) {
+	entries := []lsEntry{}
+	seen := make(map[string]bool)
+	for _, kind := range kinds {
+		dir, err := x.Lookup(kind)
+		if err != nil || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		err = xdg.Dir(dir).Walk(func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if name == "." && errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipAll
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			entries = append(entries, lsEntry{
+				Kind:    kind,
+				Path:    filepath.Join(dir, filepath.FromSlash(name)),
+				Size:    info.Size(),
+				Mode:    info.Mode(),
+				ModTime: info.ModTime(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
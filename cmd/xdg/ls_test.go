@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestLs(t *testing.T) {
+	x := xdgtest.SandboxApp(t, "myapp")
+	conf := filepath.Join(x.Config(), "settings.toml")
+	cache := filepath.Join(x.Cache(), "sub", "blob")
+	xdgtest.WriteFile(t, conf, "a = 1\n")
+	xdgtest.WriteFile(t, cache, "0123456789")
+
+	out, _, code := runCLI(t, "ls", "myapp")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if out != conf+"\n"+cache+"\n" {
+		t.Errorf("wrong output:\n%s", out)
+	}
+	out, _, code = runCLI(t, "ls", "myapp", "--kind", "cache", "--long")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if strings.Contains(out, conf) || !strings.HasPrefix(out, "cache") || !strings.Contains(out, " 10 ") {
+		t.Errorf("wrong long output:\n%s", out)
+	}
+
+	out, _, code = runCLI(t, "ls", "--json", "myapp")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var entries []struct {
+		Kind string `json:"kind"`
+		Path string `json:"path"`
+		Size int64  `json:"size"`
+	}
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].Kind != "cache" || entries[1].Size != 10 {
+		t.Errorf("wrong entries %+v", entries)
+	}
+	if _, _, code = runCLI(t, "ls", "--kind", "nope", "myapp"); code != 1 {
+		t.Errorf("expected an error for a bad kind, got %d", code)
+	}
+}
@@ -0,0 +1,299 @@
+// Command xdg prints the directories an application gets from the xdg
+// package, so shell scripts and Makefiles can use the same answers as Go
+// programs.
+//
+// Usage:
+//
+//	xdg <command> [flags] <app>
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/harrybrwn/xdg"
+)
+
+type command struct {
+	usage string
+	help  string
+	run   func(c *cli, args []string) error
+}
+
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"config":      dirCommand("config", (*xdg.XDG).ConfigE),
+		"data":        dirCommand("data", (*xdg.XDG).DataE),
+		"cache":       dirCommand("cache", (*xdg.XDG).CacheE),
+		"state":       dirCommand("state", (*xdg.XDG).StateE),
+		"runtime":     dirCommand("runtime", (*xdg.XDG).RuntimeE),
+		"config-dirs": listCommand("config-dirs", (*xdg.XDG).ConfigDirs),
+		"data-dirs":   listCommand("data-dirs", (*xdg.XDG).DataDirs),
+		"doctor": {
+			usage: "doctor <app>",
+			help:  "check the environment for problems finding app's files",
+			run:   runDoctor,
+		},
+		"dirs": {
+			usage: "dirs [--json] <app>",
+			help:  "print every directory for app, with --json using the keys of xdg.Paths",
+			run:   runDirs,
+		},
+		"backup": {
+			usage: "backup [-o file] [--kind list] <app>",
+			help:  "archive app's directories",
+			run:   runBackup,
+		},
+		"restore": {
+			usage: "restore -i file [--kind list] [--merge|--overwrite] [--dry-run] <app>",
+			help:  "restore app's directories from a backup",
+			run:   runRestore,
+		},
+		"diff": {
+			usage: "diff [--keys] config|data <app> <file>",
+			help:  "show how the user's copy of file differs from the system default",
+			run:   runDiff,
+		},
+		"edit": {
+			usage: "edit [--validate] <app> <file>",
+			help:  "open app's config file in $VISUAL or $EDITOR",
+			run:   runEdit,
+		},
+		"ls": {
+			usage: "ls [--kind name] [--long] [--json] <app>",
+			help:  "list the files in app's directories",
+			run:   runLs,
+		},
+		"env": {
+			usage: "env [--shell name]",
+			help:  "print the resolved XDG variables, as code for a shell with --shell",
+			run:   runEnv,
+		},
+	}
+}
+
+// errUsage is returned by commands that were called incorrectly. The usage
+// has already been printed.
+var errUsage = errors.New("usage")
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		c.usage()
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "xdg: unknown command %q\n", args[0])
+		c.usage()
+		return 2
+	}
+	err := cmd.run(c, args[1:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "xdg: %v\n", err)
+		return 1
+	}
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "Usage: xdg <command> [flags] <app>")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %-24s %s\n", commands[name].usage, commands[name].help)
+	}
+}
+
+// flags returns a flag set for a command that prints its usage to stderr.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	cmd := commands[name]
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: xdg %s\n\n%s\n", cmd.usage, cmd.help)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// appArg parses flags and returns the single application name argument.
+func (c *cli) appArg(fs *flag.FlagSet, args []string) (string, error) {
+	pos, err := c.parse(fs, args, 1)
+	if err != nil {
+		return "", err
+	}
+	return pos[0], nil
+}
+
+// parse parses flags that may come before or after the positional
+// arguments and returns the positional arguments, of which there must be
+// exactly n.
+func (c *cli) parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(pos) != n {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return pos, nil
+}
+
+func dirCommand(name string, get func(*xdg.XDG) (string, error)) *command {
+	return &command{
+		usage: name + " <app>",
+		help:  "print the " + name + " directory for app",
+		run: func(c *cli, args []string) error {
+			app, err := c.appArg(c.flags(name), args)
+			if err != nil {
+				return err
+			}
+			dir, err := get(xdg.New(app))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(c.stdout, dir)
+			return nil
+		},
+	}
+}
+
+func listCommand(name string, get func(*xdg.XDG) []string) *command {
+	return &command{
+		usage: name + " <app>",
+		help:  "print the " + name + " search path for app, one per line",
+		run: func(c *cli, args []string) error {
+			app, err := c.appArg(c.flags(name), args)
+			if err != nil {
+				return err
+			}
+			for _, dir := range get(xdg.New(app)) {
+				fmt.Fprintln(c.stdout, dir)
+			}
+			return nil
+		},
+	}
+}
+
+// dirsJSON is the output of dirs --json: the keys of xdg.Paths, with
+// runtimeDir always present so every directory is listed.
+type dirsJSON struct {
+	xdg.Paths
+	RuntimeDir string `json:"runtimeDir"`
+}
+
+func runDirs(c *cli, args []string) error {
+	fs := c.flags("dirs")
+	asJSON := fs.Bool("json", false, "print the directories as a JSON object with the keys configHome, dataHome, cacheHome, stateHome, runtimeDir, configDirs and dataDirs")
+	app, err := c.appArg(fs, args)
+	if err != nil {
+		return err
+	}
+	x := xdg.New(app)
+	if *asJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		p := x.Paths()
+		return enc.Encode(dirsJSON{Paths: p, RuntimeDir: p.RuntimeDir})
+	}
+	for _, d := range []struct{ name, dir string }{
+		{"config", x.Config()},
+		{"data", x.Data()},
+		{"cache", x.Cache()},
+		{"state", x.State()},
+		{"runtime", x.Runtime()},
+	} {
+		fmt.Fprintf(c.stdout, "%-12s%s\n", d.name, d.dir)
+	}
+	for _, dir := range x.ConfigDirs() {
+		fmt.Fprintf(c.stdout, "%-12s%s\n", "config-dirs", dir)
+	}
+	for _, dir := range x.DataDirs() {
+		fmt.Fprintf(c.stdout, "%-12s%s\n", "data-dirs", dir)
+	}
+	return nil
+}
+
+func runEnv(c *cli, args []string) error {
+	fs := c.flags("env")
+	shell := fs.String("shell", "", "print code for sh, bash, zsh, fish, or powershell")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if len(*shell) == 0 {
+		for _, kv := range xdg.Environ() {
+			fmt.Fprintln(c.stdout, kv)
+		}
+		return nil
+	}
+	script, err := xdg.Script(*shell)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(c.stdout, script)
+	return err
+}
+
+func runDoctor(c *cli, args []string) error {
+	app, err := c.appArg(c.flags("doctor"), args)
+	if err != nil {
+		return err
+	}
+	findings := xdg.Doctor(app)
+	if len(findings) == 0 {
+		fmt.Fprintln(c.stdout, "no problems found")
+		return nil
+	}
+	var errs int
+	for _, f := range findings {
+		fmt.Fprintln(c.stdout, f)
+		if f.Severity >= xdg.SeverityError {
+			errs++
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf("%d error(s) found", errs)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func runCLI(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return out.String(), errOut.String(), code
+}
+
+func TestDirCommands(t *testing.T) {
+	x := xdgtest.SandboxApp(t, "myapp")
+	for _, tt := range []struct{ cmd, want string }{
+		{"config", x.Config()},
+		{"data", x.Data()},
+		{"cache", x.Cache()},
+		{"state", x.State()},
+		{"runtime", x.Runtime()},
+		{"config-dirs", x.ConfigDirs()[0]},
+		{"data-dirs", x.DataDirs()[0]},
+	} {
+		out, _, code := runCLI(t, tt.cmd, "myapp")
+		if code != 0 {
+			t.Errorf("%s: exit code %d", tt.cmd, code)
+		}
+		if out != tt.want+"\n" {
+			t.Errorf("%s: got %q, want %q", tt.cmd, out, tt.want)
+		}
+	}
+	out, _, code := runCLI(t, "dirs", "myapp")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if !strings.Contains(out, "config      "+x.Config()+"\n") ||
+		!strings.Contains(out, "data-dirs   "+x.DataDirs()[0]+"\n") {
+		t.Errorf("wrong output:\n%s", out)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	xdgtest.Sandbox(t)
+	if _, stderr, code := runCLI(t); code != 2 || !strings.Contains(stderr, "Usage: xdg") {
+		t.Errorf("expected usage, got %d %q", code, stderr)
+	}
+	if _, _, code := runCLI(t, "help"); code != 0 {
+		t.Errorf("help should succeed, got %d", code)
+	}
+	if _, stderr, code := runCLI(t, "nope"); code != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Errorf("expected unknown command, got %d %q", code, stderr)
+	}
+	if _, _, code := runCLI(t, "config"); code != 2 {
+		t.Errorf("missing app should be a usage error, got %d", code)
+	}
+	if _, _, code := runCLI(t, "config", "-h"); code != 2 {
+		t.Errorf("-h should print usage, got %d", code)
+	}
+}
+
+func TestRuntimeUnset(t *testing.T) {
+	xdgtest.Sandbox(t)
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	os.Unsetenv("XDG_RUNTIME_DIR")
+	_, stderr, code := runCLI(t, "runtime", "myapp")
+	if code != 1 || !strings.Contains(stderr, "XDG_RUNTIME_DIR") {
+		t.Errorf("expected runtime error, got %d %q", code, stderr)
+	}
+}
+
+func TestDirsJSON(t *testing.T) {
+	x := xdgtest.SandboxApp(t, "myapp")
+	out, _, code := runCLI(t, "dirs", "--json", "myapp")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var got xdg.Paths
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, x.Paths()) {
+		t.Errorf("wrong output %+v", got)
+	}
+	t.Setenv("XDG_CONFIG_DIRS", "/etc/xdg")
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	os.Unsetenv("XDG_RUNTIME_DIR")
+	out, _, _ = runCLI(t, "dirs", "-json", "myapp")
+	if !strings.Contains(out, `"runtimeDir": ""`) {
+		t.Errorf("expected empty runtime dir:\n%s", out)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	xdgtest.Sandbox(t)
+	out, _, code := runCLI(t, "doctor", "myapp")
+	if code != 0 || out != "no problems found\n" {
+		t.Errorf("expected no problems, got %d %q", code, out)
+	}
+	t.Setenv("XDG_CACHE_HOME", "relative")
+	out, _, code = runCLI(t, "doctor", "myapp")
+	if code != 0 || !strings.HasPrefix(out, "warning: XDG_CACHE_HOME:") {
+		t.Errorf("expected a warning, got %d %q", code, out)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir()+"/missing")
+	_, stderr, code := runCLI(t, "doctor", "myapp")
+	if code != 1 || !strings.Contains(stderr, "1 error(s) found") {
+		t.Errorf("expected an error, got %d %q", code, stderr)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	xdgtest.Sandbox(t)
+	config := os.Getenv("XDG_CONFIG_HOME")
+	out, _, code := runCLI(t, "env")
+	if code != 0 || !strings.HasPrefix(out, "XDG_CONFIG_HOME="+config+"\n") {
+		t.Errorf("wrong output %d %q", code, out)
+	}
+	out, _, code = runCLI(t, "env", "--shell", "fish")
+	if code != 0 || !strings.HasPrefix(out, "set -gx XDG_CONFIG_HOME '"+config+"'\n") {
+		t.Errorf("wrong output %d %q", code, out)
+	}
+	if _, stderr, code := runCLI(t, "env", "--shell", "tcsh"); code != 1 || !strings.Contains(stderr, "unsupported shell") {
+		t.Errorf("expected an error, got %d %q", code, stderr)
+	}
+	if _, _, code := runCLI(t, "env", "myapp"); code != 2 {
+		t.Errorf("extra arguments should be a usage error, got %d", code)
+	}
+}
@@ -0,0 +1,101 @@
+package xdg
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// completionDir is a shell's completion directory relative to a data
+// directory, and how the shell expects a command's completion file to be
+// named.
+type completionDir struct {
+	dir    string
+	prefix string
+	suffix string
+}
+
+var completionDirs = map[string]completionDir{
+	"bash": {dir: filepath.Join("bash-completion", "completions")},
+	"zsh":  {dir: filepath.Join("zsh", "site-functions"), prefix: "_"},
+	"fish": {dir: filepath.Join("fish", "vendor_completions.d"), suffix: ".fish"},
+}
+
+// CompletionDir returns the user's completion directory for shell. See
+// (*XDG).CompletionDir.
+func CompletionDir(shell string) (string, error) { return baseXdg().CompletionDir(shell) }
+
+// CompletionDirs returns every completion directory for shell. See
+// (*XDG).CompletionDirs.
+func CompletionDirs(shell string) ([]string, error) { return baseXdg().CompletionDirs(shell) }
+
+// InstallCompletion installs the completion script of the command name for
+// shell. See (*XDG).InstallCompletion.
+func InstallCompletion(shell, name string, content []byte) (string, error) {
+	return baseXdg().InstallCompletion(shell, name, content)
+}
+
+// CompletionDir returns the directory in $XDG_DATA_HOME where completions
+// for shell, one of "bash", "zsh", or "fish", are installed per user:
+//
+//	bash  $XDG_DATA_HOME/bash-completion/completions
+//	zsh   $XDG_DATA_HOME/zsh/site-functions
+//	fish  $XDG_DATA_HOME/fish/vendor_completions.d
+//
+// bash-completion and fish search these directories by default. zsh only
+// does if the directory has been added to $fpath.
+func (xdg *XDG) CompletionDir(shell string) (string, error) {
+	c, err := completionFor(shell)
+	if err != nil {
+		return "", err
+	}
+	base := xdg.baseDir(dataHomeKey)
+	if len(base) == 0 {
+		return "", ErrNoHome
+	}
+	return filepath.Join(base, c.dir), nil
+}
+
+// CompletionDirs returns the completion directories for shell in
+// $XDG_DATA_HOME followed by those in $XDG_DATA_DIRS, where packages
+// install system wide completions.
+func (xdg *XDG) CompletionDirs(shell string) ([]string, error) {
+	c, err := completionFor(shell)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	if base := xdg.baseDir(dataHomeKey); len(base) > 0 {
+		dirs = append(dirs, filepath.Join(base, c.dir))
+	}
+	return append(dirs, joinName(xdg.baseDirs(dataDirsKey), c.dir)...), nil
+}
+
+// InstallCompletion writes the completion script of the command name into
+// the user's completion directory for shell, using the file name the shell
+// expects: name for bash, _name for zsh, and name.fish for fish. It returns
+// the path of the written file.
+func (xdg *XDG) InstallCompletion(shell, name string, content []byte) (string, error) {
+	dir, err := xdg.CompletionDir(shell)
+	if err != nil {
+		return "", err
+	}
+	if len(name) == 0 || !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+		return "", &fs.PathError{Op: "install", Path: name, Err: ErrInvalidPath}
+	}
+	c, _ := completionFor(shell)
+	file := c.prefix + name + c.suffix
+	if err = xdg.writeFileAtomicIn(KindData, dir, file, content, 0644); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, file), nil
+}
+
+func completionFor(shell string) (completionDir, error) {
+	c, ok := completionDirs[strings.ToLower(shell)]
+	if !ok {
+		return c, fmt.Errorf("xdg: no completion directory for shell %q", shell)
+	}
+	return c, nil
+}
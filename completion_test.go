@@ -0,0 +1,64 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompletionDir(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	data := filepath.Join(tmp, "data")
+	t.Setenv(dataHomeKey, data)
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "usr")+string(filepath.ListSeparator)+filepath.Join(tmp, "local"))
+	for shell, want := range map[string]string{
+		"bash": filepath.Join(data, "bash-completion", "completions"),
+		"zsh":  filepath.Join(data, "zsh", "site-functions"),
+		"Fish": filepath.Join(data, "fish", "vendor_completions.d"),
+	} {
+		dir, err := CompletionDir(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, want, dir)
+	}
+	if _, err := CompletionDir("tcsh"); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+	dirs, err := New("app").CompletionDirs("zsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{
+		filepath.Join(data, "zsh", "site-functions"),
+		filepath.Join(tmp, "usr", "zsh", "site-functions"),
+		filepath.Join(tmp, "local", "zsh", "site-functions"),
+	}, dirs)
+}
+
+func TestInstallCompletion(t *testing.T) {
+	unsetAll()
+	data := t.TempDir()
+	t.Setenv(dataHomeKey, data)
+	for shell, file := range map[string]string{
+		"bash": filepath.Join(data, "bash-completion", "completions", "mytool"),
+		"zsh":  filepath.Join(data, "zsh", "site-functions", "_mytool"),
+		"fish": filepath.Join(data, "fish", "vendor_completions.d", "mytool.fish"),
+	} {
+		path, err := InstallCompletion(shell, "mytool", []byte("# "+shell))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, file, path)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, "# "+shell, string(b))
+	}
+	if _, err := InstallCompletion("bash", "../evil", nil); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package xdg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Migrations upgrades config files written by older versions of an
+// application. Each migration converts a file from one schema version to
+// the next. The zero value is ready to use.
+type Migrations struct {
+	// Version returns the schema version of a config file. By default the
+	// file is decoded as a JSON object and its "version" member is used,
+	// with a missing member meaning version 0.
+	Version func(data []byte) (int, error)
+
+	steps map[int]func(old []byte) ([]byte, error)
+}
+
+// Add registers fn to convert files of version from to version from+1. The
+// migrated data must report the new version. Add panics if a migration from
+// the same version was already added.
+func (m *Migrations) Add(from int, fn func(old []byte) ([]byte, error)) *Migrations {
+	if m.steps == nil {
+		m.steps = make(map[int]func([]byte) ([]byte, error))
+	}
+	if _, ok := m.steps[from]; ok {
+		panic(fmt.Sprintf("xdg: migration from version %d added twice", from))
+	}
+	m.steps[from] = fn
+	return m
+}
+
+// Apply runs the migrations needed to bring data up to date, returning the
+// upgraded data and the version it started at.
+func (m *Migrations) Apply(data []byte) ([]byte, int, error) {
+	version := m.Version
+	if version == nil {
+		version = jsonVersion
+	}
+	v, err := version(data)
+	if err != nil {
+		return data, 0, err
+	}
+	from := v
+	for {
+		fn, ok := m.steps[v]
+		if !ok {
+			return data, from, nil
+		}
+		next, err := fn(data)
+		if err != nil {
+			return data, from, fmt.Errorf("xdg: migrating config from version %d: %w", v, err)
+		}
+		got, err := version(next)
+		if err != nil {
+			return data, from, fmt.Errorf("xdg: migrating config from version %d: %w", v, err)
+		}
+		if got != v+1 {
+			return data, from, fmt.Errorf("xdg: migration from version %d produced version %d", v, got)
+		}
+		data, v = next, got
+	}
+}
+
+func jsonVersion(data []byte) (int, error) {
+	var doc struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("xdg: reading config version: %w", err)
+	}
+	return doc.Version, nil
+}
+
+// LoadAndMigrate loads file from the config search path of the application
+// name and upgrades it. See (*XDG).LoadAndMigrate.
+func LoadAndMigrate(name, file string, m *Migrations) ([]byte, string, error) {
+	return newXdg(name).LoadAndMigrate(file, m)
+}
+
+// LoadAndMigrate reads the copy of file that takes precedence on the config
+// search path and applies m to it. If any migration ran, the upgraded data
+// is written atomically to the file in the config home: a copy found there
+// is first backed up next to it as <file>.v<version>.bak, while a system
+// wide copy is left untouched and shadowed by the new file. It returns the
+// up to date contents and the path they are stored at. A nil m means there
+// are no migrations and the file is returned as it is. The error wraps
+// fs.ErrNotExist if there is no copy of file.
+func (xdg *XDG) LoadAndMigrate(file string, m *Migrations) ([]byte, string, error) {
+	path, err := xdg.SearchConfigFile(file)
+	if err != nil {
+		return nil, "", err
+	}
+	src := Dir(filepath.Dir(path))
+	old, err := src.ReadFile(filepath.Base(path))
+	if err != nil {
+		return nil, path, err
+	}
+	if m == nil {
+		return old, path, nil
+	}
+	data, from, err := m.Apply(old)
+	if err != nil {
+		return nil, path, fmt.Errorf("%s: %w", path, err)
+	}
+	if m.steps[from] == nil {
+		// Already up to date.
+		return data, path, nil
+	}
+	dir, err := xdg.ConfigE()
+	if err != nil {
+		return nil, path, err
+	}
+	home, err := Dir(dir).join(file)
+	if err != nil {
+		return nil, path, err
+	}
+	perm := fs.FileMode(0644)
+	if info, err := src.Stat(filepath.Base(path)); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if home == path {
+		backup := fmt.Sprintf("%s.v%d.bak", file, from)
+		if err = xdg.writeFileAtomicIn(KindConfig, dir, backup, old, perm); err != nil {
+			return nil, path, err
+		}
+	}
+	if err = xdg.writeFileAtomicIn(KindConfig, dir, file, data, perm); err != nil {
+		return nil, path, err
+	}
+	return data, home, nil
+}
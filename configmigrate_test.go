@@ -0,0 +1,111 @@
+package xdg
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testMigrations() *Migrations {
+	m := new(Migrations)
+	m.Add(0, func(old []byte) ([]byte, error) {
+		return bytes.Replace(old, []byte(`"colour"`), []byte(`"version": 1, "color"`), 1), nil
+	}).Add(1, func(old []byte) ([]byte, error) {
+		return bytes.Replace(old, []byte(`"version": 1`), []byte(`"version": 2`), 1), nil
+	})
+	return m
+}
+
+func TestLoadAndMigrate(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	x := New("app")
+	user := filepath.Join(x.Config(), "app.json")
+	system := filepath.Join(tmp, "etc", "app", "app.json")
+	const v0 = `{"colour": "red"}`
+	const v2 = `{"version": 2, "color": "red"}`
+
+	writeTestFile(t, system, v0)
+	data, path, err := x.LoadAndMigrate("app.json", testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, v2, string(data))
+	eq(t, user, path)
+	if b, _ := os.ReadFile(system); string(b) != v0 {
+		t.Errorf("system file was modified: %s", b)
+	}
+	if exists(user + ".v0.bak") {
+		t.Error("a system file should not be backed up")
+	}
+
+	writeTestFile(t, user, v0)
+	if data, path, err = LoadAndMigrate("app", "app.json", testMigrations()); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, v2, string(data))
+	eq(t, user, path)
+	b, err := os.ReadFile(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, v2, string(b))
+	if b, _ = os.ReadFile(user + ".v0.bak"); string(b) != v0 {
+		t.Errorf("wrong backup %q", b)
+	}
+
+	// Up to date files are left alone.
+	if err = os.Remove(user + ".v0.bak"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _, err = x.LoadAndMigrate("app.json", testMigrations()); err != nil || string(data) != v2 {
+		t.Fatalf("expected %s, got %s, %v", v2, data, err)
+	}
+	if exists(user + ".v2.bak") {
+		t.Error("nothing should be backed up without a migration")
+	}
+
+	writeTestFile(t, user, "not json")
+	if data, path, err = x.LoadAndMigrate("app.json", nil); err != nil || string(data) != "not json" || path != user {
+		t.Errorf("nil migrations should load %s as is, got %s from %s, %v", user, data, path, err)
+	}
+
+	if _, _, err = x.LoadAndMigrate("missing.json", testMigrations()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestMigrationsApply(t *testing.T) {
+	m := testMigrations()
+	data, from, err := m.Apply([]byte(`{"version": 1, "color": "red"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 1, from)
+	eq(t, `{"version": 2, "color": "red"}`, string(data))
+
+	bad := new(Migrations).Add(0, func(old []byte) ([]byte, error) { return old, nil })
+	if _, _, err = bad.Apply([]byte(`{}`)); err == nil || !strings.Contains(err.Error(), "produced version 0") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+	if _, _, err = m.Apply([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+	custom := &Migrations{Version: func(data []byte) (int, error) { return len(data), nil }}
+	custom.Add(1, func(old []byte) ([]byte, error) { return append(old, 'x'), nil })
+	if data, _, err = custom.Apply([]byte("a")); err != nil || string(data) != "ax" {
+		t.Errorf("expected ax, got %q, %v", data, err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a duplicate migration")
+		}
+	}()
+	m.Add(0, nil)
+}
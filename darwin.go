@@ -0,0 +1,47 @@
+package xdg
+
+import "path/filepath"
+
+const (
+	darwinAppSupport = "Library/Application Support"
+	darwinCaches     = "Library/Caches"
+	darwinSystemDirs = "/Library/Application Support"
+)
+
+// WithNativeDarwin makes defaults follow the macOS file system layout
+// instead of the XDG one when running on darwin:
+//
+//	XDG_CONFIG_HOME  ~/Library/Application Support
+//	XDG_DATA_HOME    ~/Library/Application Support
+//	XDG_STATE_HOME   ~/Library/Application Support
+//	XDG_CACHE_HOME   ~/Library/Caches
+//	XDG_CONFIG_DIRS  /Library/Application Support
+//	XDG_DATA_DIRS    /Library/Application Support
+//
+// Explicitly set XDG variables are still honored. The option has no effect
+// on other platforms.
+func WithNativeDarwin() Option {
+	return func(xdg *XDG) { xdg.nativeDarwin = true }
+}
+
+func (xdg *XDG) darwinNative() bool {
+	return xdg.nativeDarwin && xdg.goos == "darwin"
+}
+
+func darwinBase(home, key string) string {
+	switch key {
+	case configHomeKey, dataHomeKey, stateHomeKey:
+		return filepath.Join(home, darwinAppSupport)
+	case cacheHomeKey:
+		return filepath.Join(home, darwinCaches)
+	}
+	return ""
+}
+
+func darwinDirs(key string) []string {
+	switch key {
+	case configDirsKey, dataDirsKey:
+		return []string{darwinSystemDirs}
+	}
+	return nil
+}
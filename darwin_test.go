@@ -0,0 +1,28 @@
+package xdg
+
+import "testing"
+
+func TestNativeDarwin(t *testing.T) {
+	unsetAll()
+	x := NewXDG(NewDirFinder("app"), WithHomeDir("/Users/t"), WithNativeDarwin())
+	x.goos = "darwin"
+	eq(t, "/Users/t/Library/Application Support/app", x.Config())
+	eq(t, "/Users/t/Library/Application Support/app", x.Data())
+	eq(t, "/Users/t/Library/Application Support/app", x.State())
+	eq(t, "/Users/t/Library/Caches/app", x.Cache())
+	eq(t, "", x.Runtime())
+	eq(t, "/Users/t/.app", x.getDir("unknown"))
+	arrEq(t, []string{"/Library/Application Support/app"}, x.ConfigDirs())
+	arrEq(t, []string{"/Library/Application Support/app"}, x.DataDirs())
+
+	t.Setenv(cacheHomeKey, "/Users/t/.cache")
+	eq(t, "/Users/t/.cache/app", x.Cache())
+
+	// only on darwin
+	x.goos = "linux"
+	eq(t, "/Users/t/.config/app", x.Config())
+	// and only when asked for
+	x = NewXDG(NewDirFinder("app"), WithHomeDir("/Users/t"))
+	x.goos = "darwin"
+	eq(t, "/Users/t/.config/app", x.Config())
+}
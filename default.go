@@ -0,0 +1,74 @@
+package xdg
+
+import "sync"
+
+var (
+	defaultMu  sync.RWMutex
+	defaultXDG *XDG
+)
+
+// SetAppName configures the package default instance used by ConfigFile,
+// DataFile, CacheFile, StateFile, and Default, so programs only name
+// themselves once. The options are applied to the instance. It is safe to
+// call concurrently with the functions that read the default, which keep
+// using the instance they started with.
+func SetAppName(name string, opts ...Option) {
+	x := New(name, opts...)
+	defaultMu.Lock()
+	defaultXDG = x
+	defaultMu.Unlock()
+}
+
+// Default returns the instance configured with SetAppName, or nil if it
+// has not been called.
+func Default() *XDG {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultXDG
+}
+
+// ConfigFile returns the path of file in the default application's config
+// directory. The error wraps ErrNoAppName before SetAppName is called.
+func ConfigFile(file string) (string, error) { return defaultFile(file, (*XDG).ConfigFile) }
+
+// DataFile returns the path of file in the default application's data
+// directory.
+func DataFile(file string) (string, error) { return defaultFile(file, (*XDG).DataFile) }
+
+// CacheFile returns the path of file in the default application's cache
+// directory.
+func CacheFile(file string) (string, error) { return defaultFile(file, (*XDG).CacheFile) }
+
+// StateFile returns the path of file in the default application's state
+// directory.
+func StateFile(file string) (string, error) { return defaultFile(file, (*XDG).StateFile) }
+
+func defaultFile(file string, get func(*XDG, string) (string, error)) (string, error) {
+	x := Default()
+	if x == nil {
+		return "", ErrNoAppName
+	}
+	return get(x, file)
+}
+
+// ConfigFile returns the path of file in the config directory. The name
+// must be relative and stay inside the directory, otherwise the error wraps
+// ErrInvalidPath. Nothing is created.
+func (xdg *XDG) ConfigFile(file string) (string, error) { return xdg.file(configHomeKey, file) }
+
+// DataFile returns the path of file in the data directory.
+func (xdg *XDG) DataFile(file string) (string, error) { return xdg.file(dataHomeKey, file) }
+
+// CacheFile returns the path of file in the cache directory.
+func (xdg *XDG) CacheFile(file string) (string, error) { return xdg.file(cacheHomeKey, file) }
+
+// StateFile returns the path of file in the state directory.
+func (xdg *XDG) StateFile(file string) (string, error) { return xdg.file(stateHomeKey, file) }
+
+func (xdg *XDG) file(key, file string) (string, error) {
+	dir, err := xdg.getDirE(key)
+	if err != nil {
+		return "", err
+	}
+	return Dir(dir).join(file)
+}
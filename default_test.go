@@ -0,0 +1,55 @@
+package xdg
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSetAppName(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(configHomeKey, filepath.Join(tmp, "config"))
+	t.Setenv(stateHomeKey, filepath.Join(tmp, "state"))
+	t.Cleanup(func() { defaultXDG = nil })
+
+	if _, err := ConfigFile("settings.toml"); !errors.Is(err, ErrNoAppName) {
+		t.Errorf("expected ErrNoAppName, got %v", err)
+	}
+	if Default() != nil {
+		t.Error("expected no default instance")
+	}
+	SetAppName("myapp", WithProfile("work"))
+	p, err := ConfigFile("settings.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(tmp, "config", "myapp", "profiles", "work", "settings.toml"), p)
+	if p, err = StateFile("sub/history"); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(tmp, "state", "myapp", "profiles", "work", "sub", "history"), p)
+	if _, err = DataFile("../escape"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+	// The name-taking functions are unaffected.
+	eq(t, filepath.Join(tmp, "config", "other"), Config("other"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetAppName("myapp")
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := CacheFile("x"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	eq(t, filepath.Join(tmp, "config", "myapp"), Default().Config())
+}
@@ -0,0 +1,342 @@
+// Package desktop reads and writes desktop entries, the .desktop files
+// that describe applications to menus and launchers, and finds them on the
+// XDG data search path.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/desktop-entry-spec/latest/
+package desktop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/keyfile"
+	xlocale "github.com/harrybrwn/xdg/locale"
+)
+
+const (
+	// MainGroup is the group holding the entry's keys.
+	MainGroup = "Desktop Entry"
+	// Extension is the file name extension of desktop entries.
+	Extension = ".desktop"
+	// Dir is the directory, relative to each data directory, that
+	// applications are installed in.
+	Dir = "applications"
+)
+
+// Entry types.
+const (
+	Application = "Application"
+	Link        = "Link"
+	Directory   = "Directory"
+)
+
+// Entry is a desktop entry.
+type Entry struct {
+	// ID is the desktop file ID, the path of the file relative to the
+	// applications directory with '/' replaced by '-'. It is empty for
+	// entries not found with Find or All.
+	ID string
+	// Path is the file the entry was loaded from.
+	Path string
+	// Locale selects the translation returned by the localized getters. It
+	// defaults to the LC_MESSAGES locale.
+	Locale string
+	// File is the underlying key file. Edits to it are kept when the entry
+	// is written out.
+	File *keyfile.File
+}
+
+// New returns an entry of the given type and name.
+func New(typ, name string) *Entry {
+	f := keyfile.New()
+	g := f.AddGroup(MainGroup)
+	g.Set("Type", typ)
+	g.Set("Name", name)
+	return &Entry{File: f}
+}
+
+// Parse reads a desktop entry from r. The file must have a [Desktop Entry]
+// group with a Type and Name.
+func Parse(r io.Reader) (*Entry, error) {
+	f, err := keyfile.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	groups := f.Groups()
+	if len(groups) == 0 || groups[0].Name() != MainGroup {
+		return nil, errors.New("desktop: first group must be [" + MainGroup + "]")
+	}
+	e := &Entry{File: f}
+	for _, key := range []string{"Type", "Name"} {
+		if !groups[0].Has(key) {
+			return nil, fmt.Errorf("desktop: missing required key %s", key)
+		}
+	}
+	return e, nil
+}
+
+// Load reads the desktop entry at path.
+func Load(path string) (*Entry, error) {
+	data, err := xdg.Dir(filepath.Dir(path)).ReadFile(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	e, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	e.Path = path
+	return e, nil
+}
+
+// Group returns the [Desktop Entry] group.
+func (e *Entry) Group() *keyfile.Group { return e.File.Group(MainGroup) }
+
+func (e *Entry) locale() string {
+	if len(e.Locale) > 0 {
+		return e.Locale
+	}
+	return xlocale.Messages()
+}
+
+func (e *Entry) str(key string) string {
+	v, _ := e.Group().Value(key)
+	return v
+}
+
+func (e *Entry) localeStr(key string) string {
+	v, _ := e.Group().LocaleValue(key, e.locale())
+	return v
+}
+
+func (e *Entry) boolean(key string) bool {
+	v, _ := e.Group().Bool(key)
+	return v
+}
+
+func (e *Entry) Type() string           { return e.str("Type") }
+func (e *Entry) Name() string           { return e.localeStr("Name") }
+func (e *Entry) GenericName() string    { return e.localeStr("GenericName") }
+func (e *Entry) Comment() string        { return e.localeStr("Comment") }
+func (e *Entry) Icon() string           { return e.localeStr("Icon") }
+func (e *Entry) Exec() string           { return e.str("Exec") }
+func (e *Entry) TryExec() string        { return e.str("TryExec") }
+func (e *Entry) WorkingDir() string     { return e.str("Path") }
+func (e *Entry) URL() string            { return e.str("URL") }
+func (e *Entry) StartupWMClass() string { return e.str("StartupWMClass") }
+func (e *Entry) Terminal() bool         { return e.boolean("Terminal") }
+func (e *Entry) NoDisplay() bool        { return e.boolean("NoDisplay") }
+func (e *Entry) Hidden() bool           { return e.boolean("Hidden") }
+func (e *Entry) StartupNotify() bool    { return e.boolean("StartupNotify") }
+func (e *Entry) DBusActivatable() bool  { return e.boolean("DBusActivatable") }
+func (e *Entry) Categories() []string   { return e.Group().List("Categories") }
+func (e *Entry) MimeTypes() []string    { return e.Group().List("MimeType") }
+func (e *Entry) OnlyShowIn() []string   { return e.Group().List("OnlyShowIn") }
+func (e *Entry) NotShowIn() []string    { return e.Group().List("NotShowIn") }
+func (e *Entry) Implements() []string   { return e.Group().List("Implements") }
+
+// Keywords returns the localized search keywords.
+func (e *Entry) Keywords() []string { return e.Group().LocaleList("Keywords", e.locale()) }
+
+// ShowIn reports whether the entry should be shown in the desktop
+// environment named by one of the entries of $XDG_CURRENT_DESKTOP.
+func (e *Entry) ShowIn(desktops []string) bool {
+	if e.NoDisplay() || e.Hidden() {
+		return false
+	}
+	if only := e.OnlyShowIn(); len(only) > 0 {
+		return intersects(only, desktops)
+	}
+	return !intersects(e.NotShowIn(), desktops)
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Action is an additional action of an application, shown for example in
+// a launcher's context menu.
+type Action struct {
+	ID    string
+	entry *Entry
+	group *keyfile.Group
+}
+
+// Actions returns the actions listed in the Actions key that have a
+// [Desktop Action <id>] group.
+func (e *Entry) Actions() []Action {
+	var actions []Action
+	for _, id := range e.Group().List("Actions") {
+		if g := e.File.Group("Desktop Action " + id); g != nil {
+			actions = append(actions, Action{ID: id, entry: e, group: g})
+		}
+	}
+	return actions
+}
+
+// AddAction adds an action to the entry and lists it in the Actions key.
+func (e *Entry) AddAction(id, name, exec string) Action {
+	g := e.File.Group("Desktop Action " + id)
+	if g == nil {
+		g = e.File.AddGroup("Desktop Action " + id)
+		e.Group().SetList("Actions", append(e.Group().List("Actions"), id))
+	}
+	g.Set("Name", name)
+	g.Set("Exec", exec)
+	return Action{ID: id, entry: e, group: g}
+}
+
+// Group returns the [Desktop Action <id>] group.
+func (a Action) Group() *keyfile.Group { return a.group }
+
+func (a Action) Name() string {
+	v, _ := a.group.LocaleValue("Name", a.entry.locale())
+	return v
+}
+
+func (a Action) Icon() string {
+	v, _ := a.group.LocaleValue("Icon", a.entry.locale())
+	return v
+}
+
+func (a Action) Exec() string {
+	v, _ := a.group.Value("Exec")
+	return v
+}
+
+// WriteTo writes the entry in the desktop entry format.
+func (e *Entry) WriteTo(w io.Writer) (int64, error) { return e.File.WriteTo(w) }
+
+// Bytes returns the entry in the desktop entry format.
+func (e *Entry) Bytes() []byte { return e.File.Bytes() }
+
+// Save writes the entry to path atomically.
+func (e *Entry) Save(path string) error {
+	return xdg.Dir(filepath.Dir(path)).WriteFileAtomic(filepath.Base(path), e.Bytes(), 0644)
+}
+
+// Install writes the entry to $XDG_DATA_HOME/applications/<id>.desktop.
+// The id must be a plain file name.
+func (e *Entry) Install(id string) (string, error) {
+	id = strings.TrimSuffix(id, Extension)
+	if len(id) == 0 || !filepath.IsLocal(id) || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("desktop: invalid desktop file id %q", id)
+	}
+	dir, err := xdg.DataE(Dir)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(dir, id+Extension)
+	if err = e.Save(p); err != nil {
+		return "", err
+	}
+	e.ID, e.Path = id+Extension, p
+	return p, nil
+}
+
+// Find returns the entry with the desktop file ID id, such as
+// "org.gnome.Files.desktop", from the first data directory that has it.
+// Files that fail to parse are skipped. The error wraps fs.ErrNotExist if
+// there is no such entry or it is Hidden.
+func Find(id string) (*Entry, error) {
+	if !strings.HasSuffix(id, Extension) {
+		id += Extension
+	}
+	for dir := range xdg.SearchPathData(Dir) {
+		var found string
+		err := xdg.Dir(dir).Walk(func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if name == "." {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() && fileID(name) == id {
+				found = name
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			continue
+		}
+		e, err := Load(filepath.Join(dir, filepath.FromSlash(found)))
+		if err != nil {
+			// Like All, files that fail to parse are skipped.
+			continue
+		}
+		if e.Hidden() {
+			break
+		}
+		e.ID = id
+		return e, nil
+	}
+	return nil, &fs.PathError{Op: "find", Path: id, Err: fs.ErrNotExist}
+}
+
+// All returns every installed entry sorted by ID. When several data
+// directories have the same ID the one with the highest precedence is used,
+// and Hidden entries hide the ID altogether. Files that fail to parse are
+// skipped.
+func All() ([]*Entry, error) {
+	seen := make(map[string]*Entry)
+	for dir := range xdg.SearchPathData(Dir) {
+		err := xdg.Dir(dir).Walk(func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if name == "." {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || path.Ext(name) != Extension {
+				return nil
+			}
+			id := fileID(name)
+			if _, ok := seen[id]; ok {
+				return nil
+			}
+			e, err := Load(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				return nil
+			}
+			e.ID = id
+			seen[id] = e
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	entries := make([]*Entry, 0, len(seen))
+	for _, e := range seen {
+		if !e.Hidden() {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// fileID converts a slash separated path relative to an applications
+// directory into a desktop file ID.
+func fileID(name string) string { return strings.ReplaceAll(name, "/", "-") }
@@ -0,0 +1,184 @@
+package desktop
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+const filesEntry = `[Desktop Entry]
+Type=Application
+Name=Files
+Name[de]=Dateien
+Comment=Access and organize files
+Icon=org.gnome.Files
+Exec=nautilus --new-window %U
+Terminal=false
+Categories=GNOME;GTK;Utility;
+Keywords=folder;manager;
+Keywords[de]=Ordner;Verwaltung;
+MimeType=inode/directory;
+Actions=new-window;missing;
+
+[Desktop Action new-window]
+Name=New Window
+Name[de]=Neues Fenster
+Exec=nautilus --new-window
+`
+
+func TestParse(t *testing.T) {
+	e, err := Parse(strings.NewReader(filesEntry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Locale = "C"
+	if e.Type() != Application || e.Name() != "Files" || e.Exec() != "nautilus --new-window %U" {
+		t.Errorf("unexpected entry %q %q %q", e.Type(), e.Name(), e.Exec())
+	}
+	if e.Terminal() || e.Hidden() || e.NoDisplay() {
+		t.Error("wrong boolean keys")
+	}
+	if !reflect.DeepEqual(e.Categories(), []string{"GNOME", "GTK", "Utility"}) {
+		t.Errorf("wrong categories %q", e.Categories())
+	}
+	if !reflect.DeepEqual(e.MimeTypes(), []string{"inode/directory"}) {
+		t.Errorf("wrong mime types %q", e.MimeTypes())
+	}
+	e.Locale = "de_DE.UTF-8"
+	if e.Name() != "Dateien" || e.Comment() != "Access and organize files" {
+		t.Errorf("wrong localized values %q %q", e.Name(), e.Comment())
+	}
+	if !reflect.DeepEqual(e.Keywords(), []string{"Ordner", "Verwaltung"}) {
+		t.Errorf("wrong keywords %q", e.Keywords())
+	}
+	actions := e.Actions()
+	if len(actions) != 1 || actions[0].ID != "new-window" || actions[0].Name() != "Neues Fenster" || actions[0].Exec() != "nautilus --new-window" {
+		t.Errorf("wrong actions %+v", actions)
+	}
+	if string(e.Bytes()) != filesEntry {
+		t.Errorf("round trip changed the file:\n%s", e.Bytes())
+	}
+
+	for _, bad := range []string{"", "[Other]\nType=Application\nName=x\n", "[Desktop Entry]\nName=x\n"} {
+		if _, err = Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestShowIn(t *testing.T) {
+	e := New(Application, "x")
+	if !e.ShowIn([]string{"GNOME"}) {
+		t.Error("should be shown everywhere")
+	}
+	e.Group().SetList("OnlyShowIn", []string{"KDE"})
+	if e.ShowIn([]string{"GNOME"}) || !e.ShowIn([]string{"ubuntu", "KDE"}) {
+		t.Error("OnlyShowIn not applied")
+	}
+	e.Group().Delete("OnlyShowIn")
+	e.Group().SetList("NotShowIn", []string{"GNOME"})
+	if e.ShowIn([]string{"GNOME"}) || !e.ShowIn([]string{"KDE"}) {
+		t.Error("NotShowIn not applied")
+	}
+	e.Group().SetBool("NoDisplay", true)
+	if e.ShowIn(nil) {
+		t.Error("NoDisplay entries should be hidden")
+	}
+}
+
+func TestNewAndInstall(t *testing.T) {
+	xdgtest.Sandbox(t)
+	e := New(Application, "Tool")
+	e.Group().Set("Exec", "tool %f")
+	a := e.AddAction("quit", "Quit", "tool --quit")
+	e.AddAction("quit", "Quit now", "tool --quit")
+	if a.Group().Name() != "Desktop Action quit" || len(e.Actions()) != 1 || e.Actions()[0].Name() != "Quit now" {
+		t.Errorf("wrong actions %+v", e.Actions())
+	}
+	p, err := e.Install("com.example.Tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := os.Getenv("XDG_DATA_HOME")
+	if p != filepath.Join(data, Dir, "com.example.Tool.desktop") || e.ID != "com.example.Tool.desktop" {
+		t.Errorf("wrong install path %q, id %q", p, e.ID)
+	}
+	got, err := Find("com.example.Tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Exec() != "tool %f" || got.Path != p {
+		t.Errorf("wrong entry %q from %q", got.Exec(), got.Path)
+	}
+}
+
+func TestFind_Malformed(t *testing.T) {
+	xdgtest.Sandbox(t)
+	home := filepath.Join(os.Getenv("XDG_DATA_HOME"), Dir)
+	system := filepath.Join(strings.Split(os.Getenv("XDG_DATA_DIRS"), string(filepath.ListSeparator))[0], Dir)
+	xdgtest.WriteFile(t, filepath.Join(home, "org.gnome.Files.desktop"), "nope")
+	xdgtest.WriteFile(t, filepath.Join(system, "org.gnome.Files.desktop"), filesEntry)
+	e, err := Find("org.gnome.Files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Path != filepath.Join(system, "org.gnome.Files.desktop") {
+		t.Errorf("expected the system entry, got %s", e.Path)
+	}
+}
+
+func TestInstall_InvalidID(t *testing.T) {
+	xdgtest.Sandbox(t)
+	e := New(Application, "Tool")
+	for _, id := range []string{"", ".desktop", "../evil", "kde/tool", `kde\tool`, "/abs/tool", ".."} {
+		if p, err := e.Install(id); err == nil {
+			t.Errorf("expected an error for %q, installed %q", id, p)
+		}
+	}
+}
+
+func TestFindAndAll(t *testing.T) {
+	xdgtest.Sandbox(t)
+	home := filepath.Join(os.Getenv("XDG_DATA_HOME"), Dir)
+	system := filepath.Join(strings.Split(os.Getenv("XDG_DATA_DIRS"), string(filepath.ListSeparator))[0], Dir)
+	xdgtest.WriteFile(t, filepath.Join(system, "org.gnome.Files.desktop"), filesEntry)
+	xdgtest.WriteFile(t, filepath.Join(system, "kde", "dolphin.desktop"), "[Desktop Entry]\nType=Application\nName=Dolphin\n")
+	xdgtest.WriteFile(t, filepath.Join(system, "gone.desktop"), "[Desktop Entry]\nType=Application\nName=Gone\n")
+	xdgtest.WriteFile(t, filepath.Join(system, "broken.desktop"), "nope")
+	xdgtest.WriteFile(t, filepath.Join(home, "org.gnome.Files.desktop"), "[Desktop Entry]\nType=Application\nName=My Files\n")
+	xdgtest.WriteFile(t, filepath.Join(home, "gone.desktop"), "[Desktop Entry]\nType=Application\nName=Gone\nHidden=true\n")
+
+	e, err := Find("org.gnome.Files.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Locale = "C"
+	if e.Name() != "My Files" {
+		t.Errorf("user entry should win, got %q", e.Name())
+	}
+	e, err = Find("kde-dolphin.desktop")
+	if err != nil || e.Path != filepath.Join(system, "kde", "dolphin.desktop") {
+		t.Errorf("subdirectory entry: %v, %v", e, err)
+	}
+	if _, err = Find("gone"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("hidden entry should not be found, got %v", err)
+	}
+
+	all, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range all {
+		ids = append(ids, e.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"kde-dolphin.desktop", "org.gnome.Files.desktop"}) {
+		t.Errorf("wrong ids %q", ids)
+	}
+}
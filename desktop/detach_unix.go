@@ -0,0 +1,17 @@
+//go:build unix
+
+package desktop
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in its own session so it outlives the terminal of the
+// calling program.
+func detach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
@@ -0,0 +1,212 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/harrybrwn/xdg/startup"
+)
+
+// ErrNoExec is returned when launching an entry without an Exec key.
+var ErrNoExec = errors.New("desktop: entry has no Exec key")
+
+// SplitExec splits the value of an Exec key into arguments following the
+// quoting rules of the spec. Field codes are left in place. The second
+// result reports, for every argument, whether it was quoted.
+func SplitExec(s string) (args []string, quoted []bool, err error) {
+	var (
+		cur    strings.Builder
+		inArg  bool
+		inQuot bool
+		wasQuo bool
+	)
+	flush := func() {
+		if inArg {
+			args = append(args, cur.String())
+			quoted = append(quoted, wasQuo)
+		}
+		cur.Reset()
+		inArg, wasQuo = false, false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQuot && c == '\\' && i+1 < len(s) && strings.IndexByte("\"`$\\", s[i+1]) >= 0:
+			i++
+			cur.WriteByte(s[i])
+		case inQuot && c == '"':
+			inQuot = false
+		case inQuot:
+			cur.WriteByte(c)
+		case c == '"':
+			inQuot, inArg, wasQuo = true, true, true
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+		default:
+			inArg = true
+			cur.WriteByte(c)
+		}
+	}
+	if inQuot {
+		return nil, nil, errors.New("desktop: unterminated quote in Exec")
+	}
+	flush()
+	return args, quoted, nil
+}
+
+// BuildCommand expands the field codes of the Exec key with the given files
+// or URLs and returns the command to run:
+//
+//	%f  the first argument as a local file path
+//	%F  every argument as a local file path
+//	%u  the first argument
+//	%U  every argument
+//	%i  --icon <Icon> if the entry has an icon
+//	%c  the translated Name
+//	%k  the path of the desktop file
+//	%%  a literal '%'
+//
+// file:// URLs are converted to paths for %f and %F and arguments that are
+// remote URLs are dropped there. The deprecated codes are removed. The
+// command runs in the entry's Path if it sets one.
+func (e *Entry) BuildCommand(args ...string) (*exec.Cmd, error) {
+	argv, err := e.expand(args)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = e.WorkingDir()
+	return cmd, nil
+}
+
+// Launch starts the application in its own session without waiting for it
+// to exit. If the Exec key only accepts a single file or URL (%f or %u) and
+// several are given, one instance is started for each. token is handed to
+// the application so its window can take focus; tokens inherited from the
+// environment are never passed on, so a zero token sends none. ctx is only
+// checked before each instance is started: the application outlives it.
+func (e *Entry) Launch(ctx context.Context, token startup.Token, args ...string) error {
+	groups := [][]string{args}
+	if len(args) > 1 && e.singleArg() {
+		groups = groups[:0]
+		for _, a := range args {
+			groups = append(groups, []string{a})
+		}
+	}
+	for _, g := range groups {
+		argv, err := e.expand(g)
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Dir = e.WorkingDir()
+		token.Apply(cmd)
+		detach(cmd)
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = cmd.Start(); err != nil {
+			return err
+		}
+		go cmd.Wait()
+	}
+	return nil
+}
+
+func (e *Entry) singleArg() bool {
+	s := e.Exec()
+	return (strings.Contains(s, "%f") || strings.Contains(s, "%u")) &&
+		!strings.Contains(s, "%F") && !strings.Contains(s, "%U")
+}
+
+func (e *Entry) expand(args []string) ([]string, error) {
+	raw := e.Exec()
+	if len(raw) == 0 {
+		return nil, ErrNoExec
+	}
+	words, quoted, err := SplitExec(raw)
+	if err != nil {
+		return nil, err
+	}
+	var argv []string
+	for i, w := range words {
+		if quoted[i] {
+			argv = append(argv, strings.ReplaceAll(w, "%%", "%"))
+			continue
+		}
+		switch w {
+		case "%F":
+			argv = append(argv, localPaths(args)...)
+			continue
+		case "%U":
+			argv = append(argv, args...)
+			continue
+		case "%i":
+			if icon := e.Icon(); len(icon) > 0 {
+				argv = append(argv, "--icon", icon)
+			}
+			continue
+		}
+		var (
+			b    strings.Builder
+			drop bool
+		)
+		for j := 0; j < len(w); j++ {
+			if w[j] != '%' || j+1 == len(w) {
+				b.WriteByte(w[j])
+				continue
+			}
+			j++
+			switch w[j] {
+			case '%':
+				b.WriteByte('%')
+			case 'f':
+				if p := localPaths(args); len(p) > 0 {
+					b.WriteString(p[0])
+				} else {
+					drop = true
+				}
+			case 'u':
+				if len(args) > 0 {
+					b.WriteString(args[0])
+				} else {
+					drop = true
+				}
+			case 'c':
+				b.WriteString(e.Name())
+			case 'k':
+				b.WriteString(e.Path)
+			default:
+				// Deprecated or unknown codes expand to nothing.
+				drop = true
+			}
+		}
+		if drop && b.Len() == 0 {
+			continue
+		}
+		argv = append(argv, b.String())
+	}
+	if len(argv) == 0 {
+		return nil, ErrNoExec
+	}
+	return argv, nil
+}
+
+// localPaths converts file:// URLs to paths and drops other URLs.
+func localPaths(args []string) []string {
+	paths := make([]string, 0, len(args))
+	for _, a := range args {
+		u, err := url.Parse(a)
+		switch {
+		case err != nil || len(u.Scheme) <= 1:
+			// Plain paths, including windows drive letters.
+			paths = append(paths, a)
+		case u.Scheme == "file":
+			paths = append(paths, u.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,132 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/harrybrwn/xdg/startup"
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestSplitExec(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		args   []string
+		quoted []bool
+	}{
+		{"vim %f", []string{"vim", "%f"}, []bool{false, false}},
+		{`"/opt/My App/app" --x  "a \"b\" \$c \\d"`, []string{"/opt/My App/app", "--x", `a "b" $c \d`}, []bool{true, false, true}},
+		{"sh -c \"echo \\`date\\`\"", []string{"sh", "-c", "echo `date`"}, []bool{false, false, true}},
+		{`app ""`, []string{"app", ""}, []bool{false, true}},
+	} {
+		args, quoted, err := SplitExec(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(args, tt.args) || !reflect.DeepEqual(quoted, tt.quoted) {
+			t.Errorf("SplitExec(%q) = %q %v, want %q %v", tt.in, args, quoted, tt.args, tt.quoted)
+		}
+	}
+	if _, _, err := SplitExec(`app "open`); err == nil {
+		t.Error("expected unterminated quote error")
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	e := New(Application, "Viewer")
+	e.Locale = "C"
+	e.Path = "/usr/share/applications/viewer.desktop"
+	e.Group().Set("Icon", "viewer")
+	files := []string{"/tmp/a b.png", "file:///tmp/c.png", "https://example.com/d.png"}
+	for _, tt := range []struct {
+		exec string
+		args []string
+		want []string
+	}{
+		{"viewer %F", files, []string{"viewer", "/tmp/a b.png", "/tmp/c.png"}},
+		{"viewer %U", files, []string{"viewer", "/tmp/a b.png", "file:///tmp/c.png", "https://example.com/d.png"}},
+		{"viewer %f", files, []string{"viewer", "/tmp/a b.png"}},
+		{"viewer --file=%u", files[1:], []string{"viewer", "--file=file:///tmp/c.png"}},
+		{"viewer %f", nil, []string{"viewer"}},
+		{"viewer %i --name %c %k", nil, []string{"viewer", "--icon", "viewer", "--name", "Viewer", e.Path}},
+		{`viewer 100%% "50%%" %d %m`, nil, []string{"viewer", "100%", "50%"}},
+	} {
+		e.Group().Set("Exec", tt.exec)
+		cmd, err := e.BuildCommand(tt.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cmd.Args, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.exec, cmd.Args, tt.want)
+		}
+	}
+
+	e.Group().Set("Path", "/srv")
+	cmd, _ := e.BuildCommand()
+	if cmd.Dir != "/srv" {
+		t.Errorf("wrong working dir %q", cmd.Dir)
+	}
+	e.Group().Delete("Exec")
+	if _, err := e.BuildCommand(); !errors.Is(err, ErrNoExec) {
+		t.Errorf("expected ErrNoExec, got %v", err)
+	}
+	if err := e.Launch(context.Background(), startup.Token{}); !errors.Is(err, ErrNoExec) {
+		t.Errorf("expected ErrNoExec, got %v", err)
+	}
+}
+
+func TestLaunch(t *testing.T) {
+	e := New(Application, "true")
+	e.Group().Set("Exec", "true %f")
+	if !e.singleArg() {
+		t.Error("single file codes should launch one instance per file")
+	}
+	if err := e.Launch(context.Background(), startup.Token{}, "a", "b"); err != nil {
+		t.Skip("cannot run true:", err)
+	}
+	e.Group().Set("Exec", "true %F")
+	if e.singleArg() {
+		t.Error("list codes take every file")
+	}
+}
+
+func TestLaunchToken(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test application is a shell script")
+	}
+	tmp := t.TempDir()
+	script := filepath.Join(tmp, "app")
+	out := filepath.Join(tmp, "app.out")
+	xdgtest.WriteFile(t, script, "#!/bin/sh\necho \"[$DESKTOP_STARTUP_ID] [$XDG_ACTIVATION_TOKEN]\" > "+out+".tmp && mv "+out+".tmp "+out+"\n")
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(startup.ActivationTokenKey, "stale")
+	e := New(Application, "app")
+	e.Group().Set("Exec", script)
+
+	if err := e.Launch(context.Background(), startup.FromString("tok")); err != nil {
+		t.Fatal(err)
+	}
+	if got := waitFile(t, out); got != "[tok] [tok]" {
+		t.Errorf("application got %q", got)
+	}
+	os.Remove(out)
+	if err := e.Launch(context.Background(), startup.Token{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := waitFile(t, out); got != "[] []" {
+		t.Errorf("inherited token was passed on: %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := e.Launch(ctx, startup.Token{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package desktop
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+const (
+	mimeAppsFile      = "mimeapps.list"
+	defaultAppsGroup  = "Default Applications"
+	addedAssocGroup   = "Added Associations"
+	removedAssocGroup = "Removed Associations"
+)
+
+// MimeAppsFiles returns the mimeapps.list files that exist, from highest to
+// lowest precedence. Desktop specific files, such as gnome-mimeapps.list
+// for XDG_CURRENT_DESKTOP=GNOME, come before the generic file in each
+// directory.
+func MimeAppsFiles() []string {
+	var dirs []string
+	for d := range xdg.SearchPathConfig("") {
+		dirs = append(dirs, d)
+	}
+	for d := range xdg.SearchPathData(Dir) {
+		dirs = append(dirs, d)
+	}
+	var names []string
+	for _, d := range CurrentDesktops() {
+		names = append(names, strings.ToLower(d)+"-"+mimeAppsFile)
+	}
+	names = append(names, mimeAppsFile)
+	var files []string
+	for _, dir := range dirs {
+		for _, name := range names {
+			if _, err := xdg.Dir(dir).Stat(name); err == nil {
+				files = append(files, filepath.Join(dir, name))
+			}
+		}
+	}
+	return files
+}
+
+// CurrentDesktops returns the entries of $XDG_CURRENT_DESKTOP.
+func CurrentDesktops() []string {
+	var desktops []string
+	for _, d := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if len(d) > 0 {
+			desktops = append(desktops, d)
+		}
+	}
+	return desktops
+}
+
+type mimeApps struct {
+	defaults, added, removed map[string][]string
+}
+
+func loadMimeApps(path string) *mimeApps {
+	data, err := xdg.Dir(filepath.Dir(path)).ReadFile(filepath.Base(path))
+	if err != nil {
+		return nil
+	}
+	f, err := keyfile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	read := func(group string) map[string][]string {
+		m := make(map[string][]string)
+		if g := f.Group(group); g != nil {
+			for _, k := range g.Keys() {
+				m[k] = g.List(k)
+			}
+		}
+		return m
+	}
+	return &mimeApps{
+		defaults: read(defaultAppsGroup),
+		added:    read(addedAssocGroup),
+		removed:  read(removedAssocGroup),
+	}
+}
+
+// DefaultApp returns the default application for mimeType. It is the first
+// installed entry listed under [Default Applications] in the mimeapps.list
+// files, and when there is none, the most preferred application returned by
+// AppsFor. The error wraps fs.ErrNotExist if no application handles the
+// type.
+func DefaultApp(mimeType string) (*Entry, error) {
+	removed := make(map[string]bool)
+	for _, path := range MimeAppsFiles() {
+		m := loadMimeApps(path)
+		if m == nil {
+			continue
+		}
+		for _, id := range m.removed[mimeType] {
+			removed[id] = true
+		}
+		for _, id := range m.defaults[mimeType] {
+			if removed[id] {
+				continue
+			}
+			if e, err := Find(id); err == nil {
+				return e, nil
+			}
+		}
+	}
+	apps, err := AppsFor(mimeType)
+	if err != nil {
+		return nil, err
+	}
+	if len(apps) == 0 {
+		return nil, &fs.PathError{Op: "default", Path: mimeType, Err: fs.ErrNotExist}
+	}
+	return apps[0], nil
+}
+
+// AppsFor returns the applications that can open mimeType in order of
+// preference: first those under [Added Associations] in the mimeapps.list
+// files, then every installed application listing the type in its MimeType
+// key. Applications under [Removed Associations] are left out, unless a
+// file with higher precedence added them back.
+func AppsFor(mimeType string) ([]*Entry, error) {
+	var (
+		ids     []string
+		seen    = make(map[string]bool)
+		removed = make(map[string]bool)
+	)
+	add := func(id string) {
+		if !strings.HasSuffix(id, Extension) {
+			id += Extension
+		}
+		if !seen[id] && !removed[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, path := range MimeAppsFiles() {
+		m := loadMimeApps(path)
+		if m == nil {
+			continue
+		}
+		for _, id := range m.added[mimeType] {
+			add(id)
+		}
+		for _, id := range m.removed[mimeType] {
+			removed[id] = true
+		}
+	}
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Entry, len(all))
+	for _, e := range all {
+		byID[e.ID] = e
+		for _, t := range e.MimeTypes() {
+			if t == mimeType {
+				add(e.ID)
+				break
+			}
+		}
+	}
+	apps := make([]*Entry, 0, len(ids))
+	for _, id := range ids {
+		if e, ok := byID[id]; ok {
+			apps = append(apps, e)
+		}
+	}
+	return apps, nil
+}
@@ -0,0 +1,79 @@
+package desktop
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func appIDs(apps []*Entry) []string {
+	var ids []string
+	for _, e := range apps {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestMimeApps(t *testing.T) {
+	xdgtest.Sandbox(t)
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")
+	config := os.Getenv("XDG_CONFIG_HOME")
+	sysConfig := os.Getenv("XDG_CONFIG_DIRS")
+	apps := filepath.Join(strings.Split(os.Getenv("XDG_DATA_DIRS"), string(filepath.ListSeparator))[0], Dir)
+	entry := func(name, mime string) string {
+		return "[Desktop Entry]\nType=Application\nName=" + name + "\nMimeType=" + mime + "\n"
+	}
+	xdgtest.WriteFile(t, filepath.Join(apps, "gimp.desktop"), entry("GIMP", "image/png;image/jpeg;"))
+	xdgtest.WriteFile(t, filepath.Join(apps, "eog.desktop"), entry("Eye of GNOME", "image/png;"))
+	xdgtest.WriteFile(t, filepath.Join(apps, "viewer.desktop"), entry("Viewer", "image/jpeg;"))
+	xdgtest.WriteFile(t, filepath.Join(apps, "paint.desktop"), entry("Paint", "text/plain;"))
+	xdgtest.WriteFile(t, filepath.Join(apps, "gnome-mimeapps.list"), "[Default Applications]\nimage/png=eog.desktop\n")
+	xdgtest.WriteFile(t, filepath.Join(sysConfig, "mimeapps.list"), "[Added Associations]\nimage/png=paint.desktop;\n")
+	xdgtest.WriteFile(t, filepath.Join(config, "mimeapps.list"), "[Removed Associations]\nimage/jpeg=gimp.desktop;\n")
+
+	files := MimeAppsFiles()
+	want := []string{
+		filepath.Join(config, "mimeapps.list"),
+		filepath.Join(sysConfig, "mimeapps.list"),
+		filepath.Join(apps, "gnome-mimeapps.list"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("got %q, want %q", files, want)
+	}
+
+	list, err := AppsFor("image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := appIDs(list); !reflect.DeepEqual(got, []string{"paint.desktop", "eog.desktop", "gimp.desktop"}) {
+		t.Errorf("wrong png apps %q", got)
+	}
+	list, _ = AppsFor("image/jpeg")
+	if got := appIDs(list); !reflect.DeepEqual(got, []string{"viewer.desktop"}) {
+		t.Errorf("wrong jpeg apps %q", got)
+	}
+
+	e, err := DefaultApp("image/png")
+	if err != nil || e.ID != "eog.desktop" {
+		t.Errorf("default png app %v, %v", e, err)
+	}
+	e, err = DefaultApp("image/jpeg")
+	if err != nil || e.ID != "viewer.desktop" {
+		t.Errorf("default jpeg app %v, %v", e, err)
+	}
+
+	xdgtest.WriteFile(t, filepath.Join(config, "gnome-mimeapps.list"), "[Default Applications]\nimage/png=missing.desktop;gimp.desktop\n")
+	e, err = DefaultApp("image/png")
+	if err != nil || e.ID != "gimp.desktop" {
+		t.Errorf("user default png app %v, %v", e, err)
+	}
+	if _, err = DefaultApp("video/mp4"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
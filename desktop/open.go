@@ -0,0 +1,68 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/harrybrwn/xdg/mime"
+	"github.com/harrybrwn/xdg/startup"
+)
+
+// Open opens a file or URL with the user's preferred application, like
+// xdg-open. On freedesktop systems the MIME type of a file, or
+// x-scheme-handler/<scheme> for a URL, is looked up with DefaultApp and the
+// application is launched. When no default is configured, and on macOS and
+// windows, the platform's opener is used instead: xdg-open, open, or the
+// shell's file protocol handler that start uses. The application runs
+// detached and Open does not wait for it. token is forwarded as in
+// (*Entry).Launch; pass startup.Consume() to hand on the token this process
+// was started with.
+func Open(target string, token startup.Token) error {
+	if runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		if typ, err := targetType(target); err == nil {
+			app, err := DefaultApp(typ)
+			if err == nil {
+				return app.Launch(context.Background(), token, target)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	cmd := openCommand(target)
+	token.Apply(cmd)
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// targetType returns the MIME type used to pick the application for
+// target.
+func targetType(target string) (string, error) {
+	path := target
+	if u, err := url.Parse(target); err == nil && len(u.Scheme) > 1 {
+		if u.Scheme != "file" {
+			return "x-scheme-handler/" + u.Scheme, nil
+		}
+		path = filepath.FromSlash(u.Path)
+	}
+	return mime.TypeOf(path)
+}
+
+func openCommand(target string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target)
+	case "windows":
+		// Unlike "cmd /c start" this does not need shell quoting.
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	}
+	return exec.Command("xdg-open", target)
+}
@@ -0,0 +1,84 @@
+package desktop
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harrybrwn/xdg/startup"
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+// writeScript writes a shell script that records its arguments in out.
+func writeScript(t *testing.T, path, out string) {
+	t.Helper()
+	xdgtest.WriteFile(t, path, "#!/bin/sh\necho \"$@\" > "+out+".tmp && mv "+out+".tmp "+out+"\n")
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFile(t *testing.T, path string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if b, err := os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(b))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s was not written", path)
+	return ""
+}
+
+func TestOpen(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("uses the freedesktop handlers")
+	}
+	xdgtest.Sandbox(t)
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "bin")
+	out := filepath.Join(tmp, "handler.out")
+	writeScript(t, filepath.Join(bin, "handler"), out)
+	apps := filepath.Join(os.Getenv("XDG_DATA_HOME"), Dir)
+	xdgtest.WriteFile(t, filepath.Join(apps, "handler.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Handler\nExec="+filepath.Join(bin, "handler")+" %u\nMimeType=x-scheme-handler/testproto;\n")
+	xdgtest.WriteFile(t, filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "mimeapps.list"),
+		"[Default Applications]\nx-scheme-handler/testproto=handler.desktop\n")
+
+	if err := Open("testproto://some/thing", startup.Token{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := waitFile(t, out); got != "testproto://some/thing" {
+		t.Errorf("handler got %q", got)
+	}
+
+	// Without a default the platform opener is used.
+	fallback := filepath.Join(tmp, "xdg-open.out")
+	writeScript(t, filepath.Join(bin, "xdg-open"), fallback)
+	t.Setenv("PATH", bin+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	if err := Open("otherproto://x", startup.Token{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := waitFile(t, fallback); got != "otherproto://x" {
+		t.Errorf("xdg-open got %q", got)
+	}
+}
+
+func TestTargetType(t *testing.T) {
+	for target, want := range map[string]string{
+		"https://example.com/":  "x-scheme-handler/https",
+		"mailto:me@example.com": "x-scheme-handler/mailto",
+	} {
+		typ, err := targetType(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != want {
+			t.Errorf("targetType(%q): expected %s, got %s", target, want, typ)
+		}
+	}
+}
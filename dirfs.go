@@ -0,0 +1,63 @@
+package xdg
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+var (
+	_ fs.FS         = Dir("")
+	_ fs.ReadDirFS  = Dir("")
+	_ fs.StatFS     = Dir("")
+	_ fs.GlobFS     = Dir("")
+	_ fs.ReadFileFS = Dir("")
+)
+
+// Open opens the named file for reading. Names are slash separated and
+// relative to the directory, following the fs.FS rules.
+func (d Dir) Open(name string) (fs.File, error) { return d.fs().Open(name) }
+
+// ReadDir reads the named directory and returns its entries sorted by name.
+func (d Dir) ReadDir(name string) ([]fs.DirEntry, error) { return fs.ReadDir(d.fs(), name) }
+
+// ReadFile reads the named file and returns its contents.
+func (d Dir) ReadFile(name string) ([]byte, error) { return fs.ReadFile(d.fs(), name) }
+
+// WriteFile writes data to the file name inside the directory, creating it
+// and any missing parent directories with mode 0755. Names that are
+// absolute or escape the directory are rejected with ErrInvalidPath.
+func (d Dir) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	path, err := d.join(name)
+	if err != nil {
+		return err
+	}
+	if err = mkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFile(path, data, perm)
+}
+
+// Remove removes the file or empty directory name inside the directory.
+// Names that are absolute or escape the directory are rejected with
+// ErrInvalidPath.
+func (d Dir) Remove(name string) error {
+	path, err := d.join(name)
+	if err != nil {
+		return err
+	}
+	return remove(path)
+}
+
+// Stat returns a FileInfo describing the named file.
+func (d Dir) Stat(name string) (fs.FileInfo, error) { return fs.Stat(d.fs(), name) }
+
+// Glob returns the names of all files in the directory matching pattern,
+// relative to the directory. See path.Match for the pattern syntax.
+func (d Dir) Glob(pattern string) ([]string, error) { return fs.Glob(d.fs(), pattern) }
+
+// Walk walks the file tree inside the directory, calling fn for each file
+// or directory including the directory itself as ".". Paths passed to fn are
+// slash separated and relative to the directory. See fs.WalkDir.
+func (d Dir) Walk(fn fs.WalkDirFunc) error { return fs.WalkDir(d.fs(), ".", fn) }
+
+func (d Dir) fs() fs.FS { return dirFS{dir: string(d), fsys: fileSystem()} }
@@ -0,0 +1,75 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDirFS(t *testing.T) {
+	d := Dir(t.TempDir())
+	writeTestFile(t, filepath.Join(string(d), "config.toml"))
+	writeTestFile(t, filepath.Join(string(d), "themes", "dark.css"))
+	writeTestFile(t, filepath.Join(string(d), "themes", "light.css"))
+	if err := fstest.TestFS(d, "config.toml", "themes/dark.css", "themes/light.css"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.ReadFile(d, "themes/dark.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(string(d), "themes", "dark.css"), string(b))
+	matches, err := d.Glob("themes/*.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{"themes/dark.css", "themes/light.css"}, matches)
+	var walked []string
+	err = d.Walk(func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.IsDir() {
+			walked = append(walked, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{"config.toml", "themes/dark.css", "themes/light.css"}, walked)
+	if _, err = d.Open("../escape"); err == nil {
+		t.Error("expected invalid path error")
+	}
+}
+
+func TestDirFiles(t *testing.T) {
+	d := Dir(t.TempDir())
+	if err := d.WriteFile("plugins/a/manifest.json", []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := d.ReadFile("plugins/a/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "{}", string(b))
+	if err = d.Remove("plugins/a/manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.ReadFile("plugins/a/manifest.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+	for _, name := range []string{"../escape", "a/../../escape", "/etc/passwd"} {
+		if err = d.WriteFile(name, nil, 0644); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("WriteFile(%q): expected ErrInvalidPath, got %v", name, err)
+		}
+		if err = d.Remove(name); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Remove(%q): expected ErrInvalidPath, got %v", name, err)
+		}
+		if _, err = d.ReadFile(name); err == nil {
+			t.Errorf("ReadFile(%q): expected error", name)
+		}
+	}
+}
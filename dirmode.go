@@ -0,0 +1,70 @@
+package xdg
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// WithDirMode sets the mode that directories of the given kind are created
+// with by Create and by the functions of this package that write files in
+// them, such as EnsureConfigFile, InstallAssets, Import or Migrate,
+// replacing the default from DirMode. The methods of Dir do not know which
+// kind of directory they are in and create missing parents with 0755, so
+// call Create before writing through a Dir when the mode matters.
+func WithDirMode(kind Kind, perm fs.FileMode) Option {
+	return func(xdg *XDG) {
+		if xdg.dirModes == nil {
+			xdg.dirModes = make(map[Kind]fs.FileMode)
+		}
+		xdg.dirModes[kind] = perm.Perm()
+	}
+}
+
+// DirMode returns the mode directories of the given kind are created with.
+// State and runtime directories default to 0700, since they hold history,
+// sockets and similar data only the user should see, and the runtime
+// directory spec requires it. The others default to 0755. Use WithDirMode to
+// change the policy.
+func (xdg *XDG) DirMode(kind Kind) fs.FileMode {
+	if perm, ok := xdg.dirModes[kind]; ok {
+		return perm
+	}
+	switch kind {
+	case KindState, KindRuntime:
+		return 0700
+	}
+	return 0755
+}
+
+// Create creates the application's directory of the given kind. See
+// (*XDG).Create.
+func Create(kind Kind, name string) (Dir, error) { return newXdg(name).Create(kind) }
+
+// Create creates the directory of the given kind, and any missing parents,
+// with DirMode(kind) and returns it.
+func (xdg *XDG) Create(kind Kind) (Dir, error) {
+	dir, err := xdg.Lookup(kind)
+	if err != nil {
+		return "", err
+	}
+	return Dir(dir), Dir(dir).CreateMode(xdg.DirMode(kind))
+}
+
+// dirModeFor returns the DirMode of the kind whose directory holds path, or
+// 0755 if it is in none of them. The runtime directory is left out since
+// resolving it may create the fallback directory.
+func (xdg *XDG) dirModeFor(path string) fs.FileMode {
+	for _, kind := range Kinds {
+		if kind == KindRuntime {
+			continue
+		}
+		dir, err := xdg.Lookup(kind)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && filepath.IsLocal(rel) {
+			return xdg.DirMode(kind)
+		}
+	}
+	return 0755
+}
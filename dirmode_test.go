@@ -0,0 +1,93 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCreateMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions on windows")
+	}
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(runtimeDirKey, filepath.Join(tmp, "run"))
+
+	d := Dir(filepath.Join(tmp, "a", "b"))
+	if err := d.CreateMode(0750); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{filepath.Join(tmp, "a"), string(d)} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, os.FileMode(0750), info.Mode().Perm())
+	}
+
+	x := New("app", WithDirMode(KindConfig, 0700), WithDirMode(KindState, 0750))
+	for kind, want := range map[Kind]os.FileMode{
+		KindConfig:  0700,
+		KindData:    0755,
+		KindCache:   0755,
+		KindState:   0750,
+		KindRuntime: 0700,
+	} {
+		eq(t, want, x.DirMode(kind))
+		dir, err := x.Create(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(string(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, want, info.Mode().Perm())
+	}
+	eq(t, os.FileMode(0700), New("app").DirMode(KindState))
+	if _, err := Create(Kind(0), "app"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+func TestDirModeHelpers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions on windows")
+	}
+	unsetAll()
+	home := legacyTree(t)
+	x := New("app", WithHomeDir(home), WithDirMode(KindConfig, 0700), WithDirMode(KindData, 0750))
+	mode := func(p string) os.FileMode {
+		t.Helper()
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	if _, _, err := x.EnsureConfigFile("sub/settings.toml", nil); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, os.FileMode(0700), mode(x.Config()))
+	eq(t, os.FileMode(0700), mode(filepath.Join(x.Config(), "sub")))
+
+	w, err := x.DataOverlay().Create("themes/dark.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, os.FileMode(0750), mode(filepath.Join(x.Data(), "themes")))
+
+	_, err = x.Migrate(MigrateOptions{Target: func(string) Dir { return x.DataDir() }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, os.FileMode(0750), mode(filepath.Join(x.Data(), "db")))
+	eq(t, os.FileMode(0755), New("app").dirModeFor(filepath.Join(home, "elsewhere")))
+}
@@ -0,0 +1,117 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Severity is how serious a Finding is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Finding is a problem reported by Doctor.
+type Finding struct {
+	Severity Severity
+	// Subject is the environment variable or path the finding is about.
+	Subject string
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Subject, f.Message)
+}
+
+// Doctor checks the environment of the application name. See
+// (*XDG).Doctor.
+func Doctor(name string) []Finding { return newXdg(name).Doctor() }
+
+// Doctor checks the environment against the XDG Base Directory
+// Specification and reports anything that could keep files from being found
+// or written: relative or empty variables, a missing home directory, a
+// missing or insecure $XDG_RUNTIME_DIR, and config or data directories that
+// exist but cannot be read.
+func (xdg *XDG) Doctor() []Finding {
+	var findings []Finding
+	add := func(sev Severity, subject, format string, args ...any) {
+		findings = append(findings, Finding{Severity: sev, Subject: subject, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, key := range []string{configHomeKey, dataHomeKey, stateHomeKey, cacheHomeKey, runtimeDirKey} {
+		val, ok := xdg.lookupEnv(key)
+		switch {
+		case !ok:
+		case len(val) == 0:
+			add(SeverityWarning, key, "is set but empty and is treated as unset")
+		case !filepath.IsAbs(val):
+			add(SeverityWarning, key, "is a relative path (%s), the spec says it must be ignored", val)
+		}
+	}
+	for _, key := range []string{configDirsKey, dataDirsKey} {
+		val, ok := xdg.lookupEnv(key)
+		if !ok {
+			continue
+		}
+		for _, p := range filepath.SplitList(val) {
+			if !filepath.IsAbs(p) {
+				add(SeverityWarning, key, "contains a relative path (%s), the spec says it must be ignored", p)
+			}
+		}
+	}
+	if _, err := xdg.Home(); err != nil {
+		add(SeverityError, "HOME", "could not find the home directory: %v", err)
+	}
+	if dir, ok := xdg.lookupEnv(runtimeDirKey); !ok {
+		add(SeverityWarning, runtimeDirKey, "is not set, sockets and other runtime files have nowhere to go")
+	} else if filepath.IsAbs(dir) {
+		if err := ValidateRuntimeDir(dir); err != nil {
+			add(SeverityError, runtimeDirKey, "%v", err)
+		}
+	}
+	dirs := append(xdg.configSearchPath(), xdg.dataSearchPath()...)
+	for _, dir := range dirs {
+		if len(dir) == 0 {
+			continue
+		}
+		info, err := os.Stat(longPath(dir))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			add(SeverityError, dir, "%v", err)
+			continue
+		}
+		if !info.IsDir() {
+			add(SeverityError, dir, "is not a directory")
+			continue
+		}
+		f, err := os.Open(longPath(dir))
+		if err == nil {
+			_, err = f.Readdirnames(1)
+			f.Close()
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			add(SeverityError, dir, "cannot be read: %v", err)
+		}
+	}
+	return findings
+}
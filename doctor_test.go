@@ -0,0 +1,57 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	root := t.TempDir()
+	run := filepath.Join(root, "run")
+	if err := os.Mkdir(run, 0700); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"HOME":        root,
+		runtimeDirKey: run,
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	x := New("app", WithEnviron(lookup))
+	if f := x.Doctor(); len(f) != 0 {
+		t.Errorf("expected no findings, got %v", f)
+	}
+
+	env[configHomeKey] = "relative/config"
+	env[cacheHomeKey] = ""
+	env[dataDirsKey] = "/usr/share:share"
+	env[configDirsKey] = filepath.Join(root, "etc")
+	writeTestFile(t, filepath.Join(root, "etc", "app"))
+	if err := os.Chmod(run, 0755); err != nil {
+		t.Fatal(err)
+	}
+	findings := New("app", WithEnviron(lookup)).Doctor()
+	var out []string
+	for _, f := range findings {
+		out = append(out, f.String())
+	}
+	want := []string{
+		"warning: XDG_CONFIG_HOME: is a relative path (relative/config), the spec says it must be ignored",
+		"warning: XDG_CACHE_HOME: is set but empty and is treated as unset",
+		"warning: XDG_DATA_DIRS: contains a relative path (share), the spec says it must be ignored",
+		"error: XDG_RUNTIME_DIR: xdg: insecure permissions: runtime dir " + run + " has mode 0755, expected 0700",
+		"error: " + filepath.Join(root, "etc", "app") + ": is not a directory",
+	}
+	if strings.Join(out, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(out, "\n"), strings.Join(want, "\n"))
+	}
+
+	findings = New("app", WithEnviron(func(string) (string, bool) { return "", false })).Doctor()
+	eq(t, 2, len(findings))
+	eq(t, SeverityError, findings[0].Severity)
+	eq(t, "HOME", findings[0].Subject)
+	eq(t, runtimeDirKey, findings[1].Subject)
+	eq(t, "info", SeverityInfo.String())
+	eq(t, "Severity(7)", Severity(7).String())
+}
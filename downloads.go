@@ -0,0 +1,141 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// partialExts are the suffixes browsers give downloads in progress. Chrome
+// writes "name.crdownload" and renames it when done, while Firefox writes
+// "name.part" next to an empty placeholder called "name".
+var partialExts = []string{".part", ".crdownload", ".download", ".partial"}
+
+// WatchDownloads reports completed downloads in the user's downloads
+// directory. See (*XDG).WatchDownloads.
+func WatchDownloads(ctx context.Context, glob string) (<-chan string, error) {
+	return baseXdg().WatchDownloads(ctx, glob)
+}
+
+// WatchDownloads polls the downloads directory, UserDirDownload, and sends
+// the path of every file whose name matches glob once it has finished
+// downloading. See path.Match for the pattern syntax; an empty glob matches
+// every file. Files in progress, those with a browser's partial suffix or
+// a partial sibling, are held back until the browser renames or removes
+// the partial file, and a file is only reported after its size and
+// modification time stop changing for one poll. Files that exist when
+// watching starts are not reported. The channel is closed when ctx is done.
+func (xdg *XDG) WatchDownloads(ctx context.Context, glob string) (<-chan string, error) {
+	if len(glob) == 0 {
+		glob = "*"
+	}
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, err
+	}
+	dir := xdg.UserDir(UserDirDownload)
+	if len(dir) == 0 {
+		return nil, errors.New("xdg: no downloads directory")
+	}
+	seen, err := scanDownloads(Dir(dir), glob)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("xdg: watch downloads: %w", err)
+	}
+	files := make(chan string)
+	go func() {
+		defer close(files)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		pending := make(map[string]fileState)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur, err := scanDownloads(Dir(dir), glob)
+			if err != nil {
+				continue
+			}
+			for name, st := range cur {
+				if seen[name] == st {
+					continue
+				}
+				if pending[name] != st {
+					// New or still growing, check again next time.
+					pending[name] = st
+					continue
+				}
+				delete(pending, name)
+				seen[name] = st
+				select {
+				case files <- st.path:
+				case <-ctx.Done():
+					return
+				}
+			}
+			for name := range seen {
+				if _, ok := cur[name]; !ok {
+					delete(seen, name)
+				}
+			}
+			for name := range pending {
+				if _, ok := cur[name]; !ok {
+					delete(pending, name)
+				}
+			}
+		}
+	}()
+	return files, nil
+}
+
+// scanDownloads returns the state of the completed files in dir that match
+// glob.
+func scanDownloads(dir Dir, glob string) (map[string]fileState, error) {
+	entries, err := dir.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	files := make(map[string]fileState)
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || isPartial(name) || hasPartial(names, name) {
+			continue
+		}
+		if ok, _ := path.Match(glob, name); !ok {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files[name] = fileState{path: filepath.Join(string(dir), name), size: info.Size(), mtime: info.ModTime()}
+	}
+	return files, nil
+}
+
+func isPartial(name string) bool {
+	for _, ext := range partialExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPartial(names map[string]bool, name string) bool {
+	for _, ext := range partialExts {
+		if names[name+ext] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package xdg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func nextDownload(t *testing.T, files <-chan string) string {
+	t.Helper()
+	select {
+	case f := <-files:
+		return f
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a download")
+		return ""
+	}
+}
+
+func TestWatchDownloads(t *testing.T) {
+	old := watchInterval
+	watchInterval = 5 * time.Millisecond
+	t.Cleanup(func() { watchInterval = old })
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configHomeKey, filepath.Join(tmp, ".config"))
+	dir := filepath.Join(tmp, "Downloads")
+	writeTestFile(t, filepath.Join(dir, "old.pdf"), "old")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	files, err := WatchDownloads(ctx, "*.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Firefox: an empty placeholder next to the partial file.
+	writeTestFile(t, filepath.Join(dir, "a.pdf"), "")
+	writeTestFile(t, filepath.Join(dir, "a.pdf.part"), "partial")
+	// Chrome: a partial file renamed when done.
+	writeTestFile(t, filepath.Join(dir, "b.pdf.crdownload"), "partial")
+	writeTestFile(t, filepath.Join(dir, "c.txt"), "not matched")
+	time.Sleep(10 * watchInterval)
+	select {
+	case f := <-files:
+		t.Fatalf("unexpected download %s", f)
+	default:
+	}
+
+	if err = os.Rename(filepath.Join(dir, "b.pdf.crdownload"), filepath.Join(dir, "b.pdf")); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(dir, "b.pdf"), nextDownload(t, files))
+
+	writeTestFile(t, filepath.Join(dir, "a.pdf"), "done")
+	if err = os.Remove(filepath.Join(dir, "a.pdf.part")); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(dir, "a.pdf"), nextDownload(t, files))
+
+	cancel()
+	for range files {
+	}
+
+	if _, err = WatchDownloads(context.Background(), "["); err == nil {
+		t.Error("expected an error for a bad pattern")
+	}
+}
@@ -0,0 +1,156 @@
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// envKeys are the variables Environ exports, in order.
+var envKeys = []string{
+	configHomeKey,
+	dataHomeKey,
+	stateHomeKey,
+	cacheHomeKey,
+	runtimeDirKey,
+	configDirsKey,
+	dataDirsKey,
+}
+
+// Environ returns the resolved XDG variables. See (*XDG).Environ.
+func Environ() []string { return baseXdg().Environ() }
+
+// EnvMap returns the resolved XDG variables. See (*XDG).EnvMap.
+func EnvMap() map[string]string { return baseXdg().EnvMap() }
+
+// Environ returns the XDG base directory variables in "KEY=value" form, in
+// the format of os.Environ. Every variable has the base directory this
+// package resolved for it, including computed defaults, so a child process
+// that reads them sees the same base directories. In the portable, override
+// and systemd modes the application's own directory is exported instead;
+// a child that appends its name to it ends up in a directory below it.
+// Variables that cannot be resolved, such as an unset $XDG_RUNTIME_DIR
+// without a fallback, are left out.
+func (xdg *XDG) Environ() []string {
+	m := xdg.EnvMap()
+	env := make([]string, 0, len(m))
+	for _, key := range envKeys {
+		if val, ok := m[key]; ok {
+			env = append(env, key+"="+val)
+		}
+	}
+	return env
+}
+
+// EnvMap is like Environ but returns the variables in a map.
+func (xdg *XDG) EnvMap() map[string]string {
+	m := make(map[string]string, len(envKeys))
+	for _, key := range envKeys {
+		var val string
+		if key == configDirsKey || key == dataDirsKey {
+			val = strings.Join(xdg.baseDirs(key), string(filepath.ListSeparator))
+		} else {
+			val = xdg.envBase(key)
+		}
+		if len(val) > 0 {
+			m[key] = val
+		}
+	}
+	return m
+}
+
+// envBase returns the value exported for one of the home variables: the
+// base directory without the application name, except that directories
+// from the portable, override and systemd modes, which already belong to
+// the application, are exported as they are.
+func (xdg *XDG) envBase(key string) string {
+	if len(xdg.root) == 0 {
+		if p, ok := xdg.portableDir(key); ok {
+			return p
+		}
+		if p, ok, err := xdg.override(key); ok {
+			if err != nil {
+				return ""
+			}
+			return p
+		}
+		if p, ok := xdg.systemdDir(key); ok {
+			return p
+		}
+	}
+	return xdg.baseDir(key)
+}
+
+// ApplyToCmd sets the resolved XDG variables in the environment of cmd. See
+// (*XDG).ApplyToCmd.
+func ApplyToCmd(cmd *exec.Cmd) { baseXdg().ApplyToCmd(cmd) }
+
+// ApplyToCmd merges the variables returned by Environ into cmd.Env,
+// replacing any values already there. A nil cmd.Env starts from the current
+// process's environment, as exec.Cmd would have used it.
+func (xdg *XDG) ApplyToCmd(cmd *exec.Cmd) {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	set := xdg.Environ()
+	merged := make([]string, 0, len(env)+len(set))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if !hasEnvKey(set, key) {
+			merged = append(merged, kv)
+		}
+	}
+	cmd.Env = append(merged, set...)
+}
+
+// hasEnvKey reports whether env sets key. Keys are case insensitive on
+// windows.
+func hasEnvKey(env []string, key string) bool {
+	for _, kv := range env {
+		k, _, _ := strings.Cut(kv, "=")
+		if k == key || runtime.GOOS == "windows" && strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Script returns shell code setting the resolved XDG variables. See
+// (*XDG).Script.
+func Script(shell string) (string, error) { return baseXdg().Script(shell) }
+
+// Script returns code that exports the variables returned by Environ for
+// shell, which is one of "sh", "bash", "zsh", "ksh", "dash", "fish",
+// "powershell", or "pwsh". Values are quoted so the snippet can be passed
+// to eval or sourced as is:
+//
+//	eval "$(xdg env --shell bash)"
+func (xdg *XDG) Script(shell string) (string, error) {
+	var line func(key, val string) string
+	switch strings.ToLower(shell) {
+	case "sh", "bash", "zsh", "ksh", "dash":
+		line = func(key, val string) string {
+			return "export " + key + "='" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+		}
+	case "fish":
+		r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		line = func(key, val string) string { return "set -gx " + key + " '" + r.Replace(val) + "'" }
+	case "powershell", "pwsh":
+		line = func(key, val string) string {
+			return "$env:" + key + " = '" + strings.ReplaceAll(val, "'", "''") + "'"
+		}
+	default:
+		return "", fmt.Errorf("xdg: unsupported shell %q", shell)
+	}
+	var b strings.Builder
+	for _, kv := range xdg.Environ() {
+		key, val, _ := strings.Cut(kv, "=")
+		b.WriteString(line(key, val))
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,160 @@
+package xdg
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnviron(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(cacheHomeKey, "/tmp/cache")
+	t.Setenv(dataDirsKey, "/opt/share"+string(filepath.ListSeparator)+"/usr/share")
+	x := New("app", WithGOOS("linux"), WithHomeDir(home))
+	m := x.EnvMap()
+	eq(t, filepath.Join(home, ".config"), m[configHomeKey])
+	eq(t, filepath.Join(home, ".local", "share"), m[dataHomeKey])
+	eq(t, filepath.Join(home, ".local", "state"), m[stateHomeKey])
+	eq(t, "/tmp/cache", m[cacheHomeKey])
+	eq(t, "/etc/xdg", m[configDirsKey])
+	eq(t, "/opt/share"+string(filepath.ListSeparator)+"/usr/share", m[dataDirsKey])
+	if _, ok := m[runtimeDirKey]; ok {
+		t.Error("an unset runtime dir should be left out")
+	}
+	arrEq(t, []string{
+		configHomeKey + "=" + filepath.Join(home, ".config"),
+		dataHomeKey + "=" + filepath.Join(home, ".local", "share"),
+		stateHomeKey + "=" + filepath.Join(home, ".local", "state"),
+		cacheHomeKey + "=/tmp/cache",
+		configDirsKey + "=/etc/xdg",
+		dataDirsKey + "=/opt/share" + string(filepath.ListSeparator) + "/usr/share",
+	}, x.Environ())
+
+	t.Setenv(runtimeDirKey, "/run/user/1000")
+	eq(t, "/run/user/1000", EnvMap()[runtimeDirKey])
+}
+
+func TestEnviron_Modes(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(configHomeKey, "/tmp/config")
+	portable := filepath.Join(home, "usb")
+	m := New("app", WithGOOS("linux"), WithHomeDir(home), WithPortable(portable)).EnvMap()
+	eq(t, filepath.Join(portable, "config"), m[configHomeKey])
+	eq(t, filepath.Join(portable, "cache"), m[cacheHomeKey])
+
+	// An app named like a base component is not stripped from it.
+	m = New("config", WithGOOS("linux"), WithHomeDir(home), WithPortable(portable)).EnvMap()
+	eq(t, filepath.Join(portable, "config"), m[configHomeKey])
+
+	t.Setenv(systemdStateKey, "/var/lib/app")
+	t.Setenv(systemdCacheKey, "/var/cache/svc")
+	m = New("app", WithGOOS("linux"), WithHomeDir(home), WithSystemdDirs()).EnvMap()
+	eq(t, "/var/lib/app", m[stateHomeKey])
+	eq(t, "/var/cache/svc", m[cacheHomeKey])
+	eq(t, "/tmp/config", m[configHomeKey])
+}
+
+func TestEnviron_Vendor(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(cacheHomeKey, "/tmp/cache")
+	x := NewXDG(NewDirFinderVendor("acme", "tool"), WithGOOS("linux"), WithHomeDir(home))
+	m := x.EnvMap()
+	eq(t, filepath.Join(home, ".config"), m[configHomeKey])
+	eq(t, filepath.Join(home, ".local", "share"), m[dataHomeKey])
+	eq(t, filepath.Join(home, ".local", "state"), m[stateHomeKey])
+	eq(t, "/tmp/cache", m[cacheHomeKey])
+	eq(t, filepath.Join(home, ".config", "acme", "tool"), x.Config())
+}
+
+func TestApplyToCmd(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(configHomeKey, "/tmp/config")
+	x := New("app", WithGOOS("linux"), WithHomeDir(home))
+
+	cmd := exec.Command("env")
+	cmd.Env = []string{"PATH=/bin", configHomeKey + "=/stale", "OTHER=1"}
+	x.ApplyToCmd(cmd)
+	eq(t, "/bin", lookupCmdEnv(cmd.Env, "PATH"))
+	eq(t, "1", lookupCmdEnv(cmd.Env, "OTHER"))
+	eq(t, "/tmp/config", lookupCmdEnv(cmd.Env, configHomeKey))
+	eq(t, filepath.Join(home, ".cache"), lookupCmdEnv(cmd.Env, cacheHomeKey))
+	n := 0
+	for _, kv := range cmd.Env {
+		if strings.HasPrefix(kv, configHomeKey+"=") {
+			n++
+		}
+	}
+	eq(t, 1, n)
+
+	t.Setenv("XDG_TEST_INHERITED", "yes")
+	cmd = exec.Command("env")
+	ApplyToCmd(cmd)
+	eq(t, "yes", lookupCmdEnv(cmd.Env, "XDG_TEST_INHERITED"))
+	eq(t, "/tmp/config", lookupCmdEnv(cmd.Env, configHomeKey))
+}
+
+func lookupCmdEnv(env []string, key string) string {
+	for _, kv := range env {
+		if k, v, _ := strings.Cut(kv, "="); k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestScript(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(configHomeKey, `/tmp/it's a \ test`)
+	x := New("app", WithGOOS("linux"), WithHomeDir(home))
+	for shell, want := range map[string]string{
+		"bash":       `export XDG_CONFIG_HOME='/tmp/it'\''s a \ test'`,
+		"zsh":        `export XDG_CONFIG_HOME='/tmp/it'\''s a \ test'`,
+		"fish":       `set -gx XDG_CONFIG_HOME '/tmp/it\'s a \\ test'`,
+		"PowerShell": `$env:XDG_CONFIG_HOME = '/tmp/it''s a \ test'`,
+	} {
+		script, err := x.Script(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(script, "\n"), "\n")
+		eq(t, len(x.Environ()), len(lines))
+		eq(t, want, lines[0])
+	}
+	if _, err := Script("cmd"); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestScriptSh(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(cacheHomeKey, `/tmp/a 'b' "c" $d`)
+	// $d is expanded once when the directory is resolved but the shell must
+	// not expand the result again.
+	t.Setenv("d", "$e")
+	script, err := Script("sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(sh, "-c", script+`printf %s "$XDG_CACHE_HOME"`).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, `/tmp/a 'b' "c" $e`, string(out))
+}
@@ -0,0 +1,25 @@
+package xdg
+
+import "errors"
+
+var (
+	// ErrNoHome is returned when the home directory cannot be determined.
+	ErrNoHome = errors.New("xdg: could not find home directory")
+	// ErrRuntimeDirUnset is returned when $XDG_RUNTIME_DIR is not set and
+	// there is no fallback.
+	ErrRuntimeDirUnset = errors.New("xdg: XDG_RUNTIME_DIR is not set")
+	// ErrInsecurePermissions is returned when a directory that must be
+	// private is accessible to, or owned by, other users.
+	ErrInsecurePermissions = errors.New("xdg: insecure permissions")
+	// ErrNotDirectory is returned when a directory was expected but
+	// something else exists at the path.
+	ErrNotDirectory = errors.New("xdg: not a directory")
+	// ErrInvalidPath is returned for relative names that are absolute or
+	// would escape the directory they are joined to.
+	ErrInvalidPath = errors.New("xdg: invalid path")
+	// ErrLocked is returned when a lock file is held by another process.
+	ErrLocked = errors.New("xdg: locked by another process")
+	// ErrNoAppName is returned by the functions that use the default
+	// instance before SetAppName has been called.
+	ErrNoAppName = errors.New("xdg: no application name, call SetAppName first")
+)
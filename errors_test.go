@@ -0,0 +1,29 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv(runtimeDirKey, base)
+	x := New("app")
+
+	if _, err := x.RuntimeSubdir(".."); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+	other := t.TempDir()
+	t.Setenv(runtimeDirKey, other)
+	if err := os.WriteFile(filepath.Join(other, "app"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.RuntimeSubdir(); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("expected ErrNotDirectory, got %v", err)
+	}
+	if err := Dir(base).WriteFileAtomic("../escape", nil, 0644); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package xdg
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSystem is the set of file system operations used by Dir and the file
+// helpers in this package. Paths are native, not slash separated fs.FS
+// names.
+type FileSystem interface {
+	Open(name string) (fs.File, error)
+	Stat(name string) (fs.FileInfo, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Remove(name string) error
+}
+
+var (
+	fsysMu sync.RWMutex
+	fsys   FileSystem = osFileSystem{}
+)
+
+// SetFileSystem replaces the file system used by the package, for example
+// with an in-memory implementation in tests. Passing nil restores the host
+// file system.
+func SetFileSystem(f FileSystem) {
+	if f == nil {
+		f = osFileSystem{}
+	}
+	fsysMu.Lock()
+	fsys = f
+	fsysMu.Unlock()
+}
+
+func fileSystem() FileSystem {
+	fsysMu.RLock()
+	defer fsysMu.RUnlock()
+	return fsys
+}
+
+func isOSFileSystem() bool {
+	_, ok := fileSystem().(osFileSystem)
+	return ok
+}
+
+// OSFileSystem returns the host file system.
+func OSFileSystem() FileSystem { return osFileSystem{} }
+
+type osFileSystem struct{}
+
+func (osFileSystem) Open(name string) (fs.File, error)     { return os.Open(longPath(name)) }
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) { return os.Stat(longPath(name)) }
+func (osFileSystem) Remove(name string) error              { return os.Remove(longPath(name)) }
+
+func (osFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(longPath(path), perm)
+}
+
+func (osFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(longPath(name), data, perm)
+}
+
+// PrefixFileSystem returns a file system that places every path under
+// prefix on the host file system, like the DESTDIR convention used when
+// building packages. Directories still resolve to their usual locations but
+// are read and written below prefix.
+func PrefixFileSystem(prefix string) FileSystem { return prefixFileSystem(prefix) }
+
+type prefixFileSystem string
+
+func (p prefixFileSystem) path(name string) string {
+	return filepath.Join(string(p), name)
+}
+
+func (p prefixFileSystem) Open(name string) (fs.File, error) {
+	return osFileSystem{}.Open(p.path(name))
+}
+
+func (p prefixFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return osFileSystem{}.Stat(p.path(name))
+}
+
+func (p prefixFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return osFileSystem{}.MkdirAll(p.path(path), perm)
+}
+
+func (p prefixFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return osFileSystem{}.WriteFile(p.path(name), data, perm)
+}
+
+func (p prefixFileSystem) Remove(name string) error {
+	return osFileSystem{}.Remove(p.path(name))
+}
+
+// dirFS adapts a directory on a FileSystem to fs.FS.
+type dirFS struct {
+	dir  string
+	fsys FileSystem
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	p, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.fsys.Open(p)
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := d.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.fsys.Stat(p)
+}
+
+func (d dirFS) join(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
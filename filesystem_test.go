@@ -0,0 +1,121 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// memFS is an in-memory FileSystem.
+type memFS struct{ m fstest.MapFS }
+
+func (f memFS) key(name string) string {
+	k := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if len(k) == 0 {
+		return "."
+	}
+	return k
+}
+
+func (f memFS) Open(name string) (fs.File, error)     { return f.m.Open(f.key(name)) }
+func (f memFS) Stat(name string) (fs.FileInfo, error) { return fs.Stat(f.m, f.key(name)) }
+
+func (f memFS) MkdirAll(path string, perm fs.FileMode) error {
+	f.m[f.key(path)] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+	return nil
+}
+
+func (f memFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f.m[f.key(name)] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+func (f memFS) Remove(name string) error {
+	if _, ok := f.m[f.key(name)]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(f.m, f.key(name))
+	return nil
+}
+
+func TestSetFileSystem(t *testing.T) {
+	mem := memFS{m: fstest.MapFS{}}
+	SetFileSystem(mem)
+	defer SetFileSystem(nil)
+
+	t.Setenv(configHomeKey, "/conf")
+	t.Setenv(configDirsKey, "/etc/xdg")
+	d := New("app").ConfigDir()
+	if d.Exists() {
+		t.Fatal("should not exist yet")
+	}
+	if err := d.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Exists() {
+		t.Error("should exist")
+	}
+	if err := d.WriteFileAtomic("settings.toml", []byte("x = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if exists("/conf/app/settings.toml") != true {
+		t.Error("file should be written to the memory file system")
+	}
+	if _, err := os.Stat("/conf/app/settings.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("file should not be on disk")
+	}
+	b, err := fs.ReadFile(d, "settings.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "x = 1", string(b))
+	p, err := SearchConfigFile("app", "settings.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join("/conf", "app", "settings.toml"), p)
+	if err = remove(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.Stat("settings.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+	if _, err = d.Stat("../x"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected invalid path, got %v", err)
+	}
+}
+
+func TestPrefixFileSystem(t *testing.T) {
+	dest := t.TempDir()
+	SetFileSystem(PrefixFileSystem(dest))
+	defer SetFileSystem(nil)
+
+	d := Dir("/usr/share/app")
+	if err := d.WriteFileAtomic("icon.svg", []byte("<svg/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "usr", "share", "app", "icon.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "<svg/>", string(b))
+	if !d.Append("icon.svg").Exists() {
+		t.Error("file should exist under the prefix")
+	}
+	f, err := d.Open("icon.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err = remove(filepath.Join(string(d), "icon.svg")); err != nil {
+		t.Fatal(err)
+	}
+	SetFileSystem(OSFileSystem())
+	if !isOSFileSystem() {
+		t.Error("expected the host file system")
+	}
+}
@@ -0,0 +1,76 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// firstRunMarker is the file in the state directory that records that the
+// application has run before.
+const firstRunMarker = ".first-run"
+
+// FirstRun reports whether this is the first run of the application name.
+// See (*XDG).FirstRun.
+func FirstRun(name string) (bool, error) { return newXdg(name).FirstRun() }
+
+// FirstRun reports whether this is the first time the application has run,
+// so it can write its default configuration or show an introduction. The
+// check creates a marker file in the state directory exclusively, so when
+// several instances start at once exactly one of them gets true; a
+// FileSystem installed with SetFileSystem cannot create files exclusively,
+// so that guarantee only holds on the host file system. Every later call, in
+// any process, returns false until ResetFirstRun is called.
+func (xdg *XDG) FirstRun() (bool, error) {
+	path, err := xdg.StateFile(firstRunMarker)
+	if err != nil {
+		return false, err
+	}
+	if err = mkdirAll(filepath.Dir(path), xdg.DirMode(KindState)); err != nil {
+		return false, err
+	}
+	stamp := time.Now().UTC().Format(time.RFC3339) + "\n"
+	if !isOSFileSystem() {
+		// Other file systems have no exclusive create, so the check is not
+		// atomic there.
+		if exists(path) {
+			return false, nil
+		}
+		if err = writeFile(path, []byte(stamp), 0644); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	f, err := os.OpenFile(longPath(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		audit(OpWrite, path, 0, 0644, err)
+		return false, err
+	}
+	n, err := f.WriteString(stamp)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	audit(OpWrite, path, int64(n), 0644, err)
+	// The marker exists even if writing the date failed, so this is still
+	// the first run.
+	return true, nil
+}
+
+// ResetFirstRun removes the first run marker so the next call to FirstRun
+// returns true again, for example when the one-time setup failed.
+func (xdg *XDG) ResetFirstRun() error {
+	path, err := xdg.StateFile(firstRunMarker)
+	if err != nil {
+		return err
+	}
+	err = remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
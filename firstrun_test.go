@@ -0,0 +1,73 @@
+package xdg
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFirstRun(t *testing.T) {
+	unsetAll()
+	t.Setenv("HOME", t.TempDir())
+	var (
+		wg    sync.WaitGroup
+		first atomic.Int32
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := FirstRun("app")
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				first.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	eq(t, int32(1), first.Load())
+	if ok, err := FirstRun("app"); err != nil || ok {
+		t.Errorf("expected a later run, got %v, %v", ok, err)
+	}
+
+	x := New("app")
+	if err := x.ResetFirstRun(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := x.FirstRun(); err != nil || !ok {
+		t.Errorf("expected a first run after reset, got %v, %v", ok, err)
+	}
+	if ok, _ := FirstRun("other"); !ok {
+		t.Error("applications should not share the marker")
+	}
+}
+
+func TestFirstRun_FileSystem(t *testing.T) {
+	unsetAll()
+	mem := memFS{m: fstest.MapFS{}}
+	SetFileSystem(mem)
+	defer SetFileSystem(nil)
+	t.Setenv(stateHomeKey, "/state")
+	x := New("app")
+	if ok, err := x.FirstRun(); err != nil || !ok {
+		t.Fatalf("expected a first run, got %v, %v", ok, err)
+	}
+	if _, ok := mem.m["state/app/.first-run"]; !ok {
+		t.Error("marker should be written to the installed file system")
+	}
+	if ok, err := x.FirstRun(); err != nil || ok {
+		t.Errorf("expected a later run, got %v, %v", ok, err)
+	}
+	if err := x.ResetFirstRun(); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.ResetFirstRun(); err != nil {
+		t.Errorf("resetting twice should not fail: %v", err)
+	}
+	if ok, _ := x.FirstRun(); !ok {
+		t.Error("expected a first run after reset")
+	}
+}
@@ -0,0 +1,299 @@
+// Package icon finds icons following the Icon Theme Specification.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/icon-theme-spec/latest/
+package icon
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+// DefaultTheme is the theme every other theme falls back to.
+const DefaultTheme = "hicolor"
+
+// Extensions are the icon file extensions in order of preference.
+var Extensions = []string{".png", ".svg", ".xpm"}
+
+// BaseDirs returns the directories searched for themes and icons in order:
+// $HOME/.icons, $XDG_DATA_HOME/icons, each of $XDG_DATA_DIRS/icons, and
+// /usr/share/pixmaps.
+func BaseDirs() []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".icons"))
+	}
+	for d := range xdg.SearchPathData("icons") {
+		dirs = append(dirs, d)
+	}
+	return append(dirs, filepath.FromSlash("/usr/share/pixmaps"))
+}
+
+// Find looks up an icon in theme at the given size and scale. See
+// (*Finder).Find.
+func Find(theme, name string, size, scale int) (string, error) {
+	return NewFinder(theme).Find(name, size, scale)
+}
+
+// Finder looks up icons in a theme. Parsed theme indexes are cached, so a
+// Finder should be reused for many lookups. It is safe for concurrent use.
+type Finder struct {
+	// Theme is the user's selected theme. An empty theme means only
+	// DefaultTheme is searched.
+	Theme string
+	// Dirs are the base directories. They default to BaseDirs.
+	Dirs []string
+
+	mu     sync.Mutex
+	themes map[string]*theme
+}
+
+// NewFinder returns a Finder for theme searching BaseDirs.
+func NewFinder(theme string) *Finder { return &Finder{Theme: theme, Dirs: BaseDirs()} }
+
+// Find returns the file of the icon name best matching size and scale. The
+// selected theme and the themes it inherits from are searched first, then
+// DefaultTheme, and finally the base directories themselves for an
+// unthemed icon. The error wraps fs.ErrNotExist if there is no such icon.
+func (f *Finder) Find(name string, size, scale int) (string, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	seen := make(map[string]bool)
+	if len(f.Theme) > 0 {
+		if p := f.findIn(f.Theme, name, size, scale, seen); len(p) > 0 {
+			return p, nil
+		}
+	}
+	if p := f.findIn(DefaultTheme, name, size, scale, seen); len(p) > 0 {
+		return p, nil
+	}
+	for _, dir := range f.Dirs {
+		for _, ext := range Extensions {
+			if p := filepath.Join(dir, name+ext); exists(p) {
+				return p, nil
+			}
+		}
+	}
+	return "", &fs.PathError{Op: "find", Path: name, Err: fs.ErrNotExist}
+}
+
+func (f *Finder) findIn(name, icon string, size, scale int, seen map[string]bool) string {
+	if seen[name] {
+		return ""
+	}
+	seen[name] = true
+	t := f.theme(name)
+	if t == nil {
+		return ""
+	}
+	if p := f.lookup(t, icon, size, scale); len(p) > 0 {
+		return p
+	}
+	for _, parent := range t.inherits {
+		if p := f.findIn(parent, icon, size, scale, seen); len(p) > 0 {
+			return p
+		}
+	}
+	return ""
+}
+
+// lookup implements LookupIcon from the spec: an exact size match wins,
+// otherwise the closest size is used.
+func (f *Finder) lookup(t *theme, icon string, size, scale int) string {
+	for _, d := range t.dirs {
+		if d.matches(size, scale) {
+			if p := f.file(t, d, icon); len(p) > 0 {
+				return p
+			}
+		}
+	}
+	var (
+		best    string
+		minimal = int(^uint(0) >> 1)
+	)
+	for _, d := range t.dirs {
+		if dist := d.distance(size, scale); dist < minimal {
+			if p := f.file(t, d, icon); len(p) > 0 {
+				best, minimal = p, dist
+			}
+		}
+	}
+	return best
+}
+
+// file returns the first copy of icon in the theme directory d.
+func (f *Finder) file(t *theme, d subdir, icon string) string {
+	for _, base := range f.Dirs {
+		for _, ext := range Extensions {
+			if p := filepath.Join(base, t.name, d.path, icon+ext); exists(p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+func (f *Finder) theme(name string) *theme {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.themes[name]; ok {
+		return t
+	}
+	if f.themes == nil {
+		f.themes = make(map[string]*theme)
+	}
+	var t *theme
+	for _, base := range f.Dirs {
+		data, err := xdg.Dir(filepath.Join(base, name)).ReadFile("index.theme")
+		if err != nil {
+			continue
+		}
+		if t, err = parseTheme(name, data); err == nil {
+			break
+		}
+	}
+	f.themes[name] = t
+	return t
+}
+
+// Types of theme directories.
+const (
+	Fixed     = "Fixed"
+	Scalable  = "Scalable"
+	Threshold = "Threshold"
+)
+
+type theme struct {
+	name     string
+	inherits []string
+	dirs     []subdir
+}
+
+type subdir struct {
+	path      string
+	typ       string
+	size      int
+	scale     int
+	minSize   int
+	maxSize   int
+	threshold int
+}
+
+func parseTheme(name string, data []byte) (*theme, error) {
+	kf, err := keyfile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	g := kf.Group("Icon Theme")
+	if g == nil {
+		return nil, &fs.PathError{Op: "parse", Path: path.Join(name, "index.theme"), Err: fs.ErrInvalid}
+	}
+	t := &theme{name: name}
+	for _, p := range commaList(g, "Inherits") {
+		if p != name {
+			t.inherits = append(t.inherits, p)
+		}
+	}
+	dirs := append(commaList(g, "Directories"), commaList(g, "ScaledDirectories")...)
+	for _, p := range dirs {
+		dg := kf.Group(p)
+		if dg == nil {
+			continue
+		}
+		d := subdir{path: filepath.FromSlash(p), typ: Threshold, scale: 1, threshold: 2}
+		var ok bool
+		if d.size, ok = dg.Int("Size"); !ok {
+			continue
+		}
+		d.minSize, d.maxSize = d.size, d.size
+		if v, ok := dg.Value("Type"); ok {
+			d.typ = v
+		}
+		if v, ok := dg.Int("Scale"); ok && v > 0 {
+			d.scale = v
+		}
+		if v, ok := dg.Int("MinSize"); ok {
+			d.minSize = v
+		}
+		if v, ok := dg.Int("MaxSize"); ok {
+			d.maxSize = v
+		}
+		if v, ok := dg.Int("Threshold"); ok {
+			d.threshold = v
+		}
+		t.dirs = append(t.dirs, d)
+	}
+	return t, nil
+}
+
+func commaList(g *keyfile.Group, key string) []string {
+	v, _ := g.Value(key)
+	var list []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// matches implements DirectoryMatchesSize.
+func (d subdir) matches(size, scale int) bool {
+	if d.scale != scale {
+		return false
+	}
+	switch d.typ {
+	case Fixed:
+		return d.size == size
+	case Scalable:
+		return d.minSize <= size && size <= d.maxSize
+	default:
+		return d.size-d.threshold <= size && size <= d.size+d.threshold
+	}
+}
+
+// distance implements DirectorySizeDistance.
+func (d subdir) distance(size, scale int) int {
+	want := size * scale
+	switch d.typ {
+	case Fixed:
+		return abs(d.size*d.scale - want)
+	case Scalable:
+		return outside(want, d.minSize*d.scale, d.maxSize*d.scale)
+	default:
+		return outside(want, (d.size-d.threshold)*d.scale, (d.size+d.threshold)*d.scale)
+	}
+}
+
+func outside(v, lo, hi int) int {
+	switch {
+	case v < lo:
+		return lo - v
+	case v > hi:
+		return v - hi
+	default:
+		return 0
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func exists(p string) bool {
+	_, err := xdg.Dir(filepath.Dir(p)).Stat(filepath.Base(p))
+	return err == nil
+}
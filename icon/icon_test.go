@@ -0,0 +1,127 @@
+package icon
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+const hicolorIndex = `[Icon Theme]
+Name=Hicolor
+Directories=16x16/apps,48x48/apps,48x48@2/apps,scalable/apps
+
+[16x16/apps]
+Size=16
+Type=Fixed
+
+[48x48/apps]
+Size=48
+Type=Threshold
+
+[48x48@2/apps]
+Size=48
+Scale=2
+Type=Fixed
+
+[scalable/apps]
+Size=128
+MinSize=8
+MaxSize=512
+Type=Scalable
+`
+
+const adwaitaIndex = `[Icon Theme]
+Name=Adwaita
+Inherits=Base
+Directories=32x32/apps
+
+[32x32/apps]
+Size=32
+Type=Fixed
+`
+
+const baseIndex = `[Icon Theme]
+Name=Base
+Inherits=Adwaita
+Directories=24x24/apps
+
+[24x24/apps]
+Size=24
+Type=Fixed
+`
+
+func TestFind(t *testing.T) {
+	user, system := t.TempDir(), t.TempDir()
+	xdgtest.WriteFile(t, filepath.Join(system, "hicolor", "index.theme"), hicolorIndex)
+	xdgtest.WriteFile(t, filepath.Join(user, "Adwaita", "index.theme"), adwaitaIndex)
+	xdgtest.WriteFile(t, filepath.Join(system, "Base", "index.theme"), baseIndex)
+
+	h16 := xdgtest.WriteFile(t, filepath.Join(system, "hicolor", "16x16", "apps", "app.png"), "")
+	xdgtest.WriteFile(t, filepath.Join(system, "hicolor", "48x48", "apps", "app.png"), "")
+	h48x2 := xdgtest.WriteFile(t, filepath.Join(system, "hicolor", "48x48@2", "apps", "app.png"), "")
+	svg := xdgtest.WriteFile(t, filepath.Join(system, "hicolor", "scalable", "apps", "app.svg"), "")
+	a32 := xdgtest.WriteFile(t, filepath.Join(user, "Adwaita", "32x32", "apps", "themed.png"), "")
+	b24 := xdgtest.WriteFile(t, filepath.Join(system, "Base", "24x24", "apps", "inherited.png"), "")
+	user48 := xdgtest.WriteFile(t, filepath.Join(user, "hicolor", "48x48", "apps", "app.png"), "")
+	pixmap := xdgtest.WriteFile(t, filepath.Join(system, "pixmap.xpm"), "")
+
+	f := &Finder{Theme: "Adwaita", Dirs: []string{user, system}}
+	for _, tt := range []struct {
+		name        string
+		size, scale int
+		want        string
+	}{
+		{"app", 16, 1, h16},
+		{"app", 48, 1, user48},
+		{"app", 50, 1, user48},
+		{"app", 48, 2, h48x2},
+		{"app", 256, 1, svg},
+		{"app", 20, 1, svg},
+		{"inherited", 30, 1, b24},
+		{"themed", 48, 1, a32},
+		{"inherited", 24, 1, b24},
+		{"pixmap", 48, 1, pixmap},
+	} {
+		got, err := f.Find(tt.name, tt.size, tt.scale)
+		if err != nil {
+			t.Errorf("Find(%q, %d, %d): %v", tt.name, tt.size, tt.scale, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Find(%q, %d, %d) = %q, want %q", tt.name, tt.size, tt.scale, got, tt.want)
+		}
+	}
+	if _, err := f.Find("missing", 48, 1); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+
+	f = &Finder{Dirs: []string{system}}
+	if got, _ := f.Find("themed", 32, 1); len(got) > 0 {
+		t.Errorf("themed icon should not be found without the theme, got %q", got)
+	}
+}
+
+func TestBaseDirs(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(tmp, "usr"))
+	want := []string{
+		filepath.Join(tmp, ".icons"),
+		filepath.Join(tmp, "data", "icons"),
+		filepath.Join(tmp, "usr", "icons"),
+		filepath.FromSlash("/usr/share/pixmaps"),
+	}
+	got := BaseDirs()
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
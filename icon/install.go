@@ -0,0 +1,122 @@
+package icon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// Image is one resolution of an application icon.
+type Image struct {
+	// Size is the width and height in pixels. Zero means Data is a
+	// scalable SVG.
+	Size int
+	// Scale is the scale factor the image is meant for. Zero means 1.
+	Scale int
+	// Data is the PNG or SVG file.
+	Data []byte
+}
+
+// dir returns the hicolor directory and file name the image is installed
+// as.
+func (img Image) dir(name string) (string, string) {
+	if img.Size == 0 {
+		return filepath.Join("scalable", "apps"), name + ".svg"
+	}
+	sub := strconv.Itoa(img.Size) + "x" + strconv.Itoa(img.Size)
+	if img.Scale > 1 {
+		sub += "@" + strconv.Itoa(img.Scale)
+	}
+	return filepath.Join(sub, "apps"), name + ".png"
+}
+
+// userTheme returns $XDG_DATA_HOME/icons/hicolor.
+func userTheme() (xdg.Dir, error) {
+	dir, err := xdg.DataE("icons")
+	if err != nil {
+		return "", err
+	}
+	return xdg.Dir(filepath.Join(dir, DefaultTheme)), nil
+}
+
+// Install writes the images of the application icon name into the hicolor
+// theme in $XDG_DATA_HOME/icons, at icons/hicolor/<size>x<size>/apps for
+// raster images and icons/hicolor/scalable/apps for an SVG, and returns the
+// written paths. The theme directory's modification time is updated so
+// icon caches notice the change.
+func Install(name string, images ...Image) ([]string, error) {
+	if !validName(name) {
+		return nil, fmt.Errorf("icon: invalid icon name %q", name)
+	}
+	theme, err := userTheme()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, img := range images {
+		if img.Size < 0 || img.Scale < 0 {
+			return paths, fmt.Errorf("icon: invalid size %d@%d", img.Size, img.Scale)
+		}
+		sub, file := img.dir(name)
+		rel := filepath.Join(sub, file)
+		if err = theme.WriteFileAtomic(rel, img.Data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, theme.Append(rel).String())
+	}
+	touch(theme)
+	return paths, nil
+}
+
+// Uninstall removes every image of the icon name from the hicolor theme in
+// $XDG_DATA_HOME/icons and returns the removed paths.
+func Uninstall(name string) ([]string, error) {
+	if !validName(name) {
+		return nil, fmt.Errorf("icon: invalid icon name %q", name)
+	}
+	theme, err := userTheme()
+	if err != nil {
+		return nil, err
+	}
+	sizes, err := theme.ReadDir(".")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, s := range sizes {
+		if !s.IsDir() {
+			continue
+		}
+		for _, ext := range Extensions {
+			rel := filepath.Join(s.Name(), "apps", name+ext)
+			err = theme.Remove(rel)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return removed, err
+			}
+			removed = append(removed, theme.Append(rel).String())
+		}
+	}
+	if len(removed) > 0 {
+		touch(theme)
+	}
+	return removed, nil
+}
+
+func validName(name string) bool {
+	return len(name) > 0 && filepath.IsLocal(name) && filepath.Base(name) == name
+}
+
+func touch(dir xdg.Dir) {
+	now := time.Now()
+	_ = os.Chtimes(dir.String(), now, now)
+}
@@ -0,0 +1,58 @@
+package icon
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestInstall(t *testing.T) {
+	xdgtest.Sandbox(t)
+	theme := filepath.Join(os.Getenv("XDG_DATA_HOME"), "icons", DefaultTheme)
+	paths, err := Install("com.example.App",
+		Image{Size: 48, Data: []byte("png48")},
+		Image{Size: 48, Scale: 2, Data: []byte("png96")},
+		Image{Data: []byte("<svg/>")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(theme, "48x48", "apps", "com.example.App.png"),
+		filepath.Join(theme, "48x48@2", "apps", "com.example.App.png"),
+		filepath.Join(theme, "scalable", "apps", "com.example.App.svg"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %q, want %q", paths, want)
+	}
+	b, err := os.ReadFile(want[2])
+	if err != nil || string(b) != "<svg/>" {
+		t.Errorf("got %q, %v", b, err)
+	}
+
+	if _, err = Install("../x", Image{Size: 16}); err == nil {
+		t.Error("expected invalid name error")
+	}
+	if _, err = Install("x", Image{Size: -1}); err == nil {
+		t.Error("expected invalid size error")
+	}
+
+	removed, err := Uninstall("com.example.App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed %q, want %q", removed, want)
+	}
+	for _, p := range want {
+		if _, err = os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", p)
+		}
+	}
+	if removed, err = Uninstall("com.example.App"); err != nil || len(removed) != 0 {
+		t.Errorf("second uninstall: %q, %v", removed, err)
+	}
+}
@@ -0,0 +1,175 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Index is an in-memory snapshot of the files in a directory across a whole
+// search path, for programs such as launchers that look up thousands of
+// desktop files or icons and cannot afford to probe every directory each
+// time. It is safe for concurrent use.
+type Index struct {
+	dirs []string
+
+	mu    sync.RWMutex
+	files map[string]Resource
+	// folded maps the folded names of files found in case-insensitive
+	// directories to their key in files.
+	folded map[string]string
+	// stamps holds the modification time of every directory seen by the
+	// last refresh. Directories that did not exist have a zero time.
+	stamps map[string]time.Time
+}
+
+// BuildIndex indexes relDir across the search path of the given kind for
+// the application name. See (*XDG).BuildIndex.
+func BuildIndex(kind Kind, name, relDir string) (*Index, error) {
+	return newXdg(name).BuildIndex(kind, relDir)
+}
+
+// BuildIndex snapshots the files below relDir in every directory returned
+// by SearchDirs(kind). Use an instance without an application name to
+// index shared directories such as "applications" or "icons".
+func (xdg *XDG) BuildIndex(kind Kind, relDir string) (*Index, error) {
+	if _, ok := kind.key(); !ok {
+		return nil, fmt.Errorf("xdg: unknown directory kind %v", kind)
+	}
+	var dirs []string
+	for _, d := range xdg.SearchDirs(kind) {
+		dirs = append(dirs, filepath.Join(d, relDir))
+	}
+	ix := &Index{dirs: dirs}
+	return ix, ix.Refresh()
+}
+
+// Lookup returns the copy of the slash separated name, relative to the
+// indexed directory, that takes precedence. Files in directories on a
+// case-insensitive file system are also found by a name that differs in
+// case, as the operating system would find them.
+func (ix *Index) Lookup(name string) (Resource, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	r, ok := ix.files[name]
+	if !ok {
+		if key, found := ix.folded[foldName(name)]; found {
+			r, ok = ix.files[key]
+		}
+	}
+	return r, ok
+}
+
+// Names returns the sorted names of every indexed file.
+func (ix *Index) Names() []string {
+	ix.mu.RLock()
+	names := make([]string, 0, len(ix.files))
+	for name := range ix.files {
+		names = append(names, name)
+	}
+	ix.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Refresh rebuilds the index from disk.
+func (ix *Index) Refresh() error {
+	files := make(map[string]Resource)
+	folded := make(map[string]string)
+	stamps := make(map[string]time.Time)
+	for layer, dir := range ix.dirs {
+		stamps[dir] = time.Time{}
+		fold := caseInsensitive(dir)
+		err := Dir(dir).Walk(func(name string, e fs.DirEntry, err error) error {
+			if err != nil {
+				if name == "." && errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipAll
+				}
+				return err
+			}
+			if e.IsDir() {
+				info, err := e.Info()
+				if err != nil {
+					return err
+				}
+				stamps[filepath.Join(dir, filepath.FromSlash(name))] = info.ModTime()
+				return nil
+			}
+			if _, ok := files[name]; ok {
+				return nil
+			}
+			if _, ok := folded[foldName(name)]; ok {
+				// Shadowed by a file in a higher case-insensitive layer.
+				return nil
+			}
+			files[name] = Resource{Path: filepath.Join(dir, filepath.FromSlash(name)), Layer: layer}
+			if fold {
+				folded[foldName(name)] = name
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	ix.mu.Lock()
+	ix.files, ix.folded, ix.stamps = files, folded, stamps
+	ix.mu.Unlock()
+	return nil
+}
+
+// changed reports whether any directory seen by the last refresh was
+// modified, created or removed since.
+func (ix *Index) changed() bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	for dir, mtime := range ix.stamps {
+		info, err := fileSystem().Stat(dir)
+		if err != nil {
+			if !mtime.IsZero() {
+				return true
+			}
+			continue
+		}
+		if !info.ModTime().Equal(mtime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch keeps the index up to date by polling the indexed directories and
+// refreshing it when files are added or removed. Changes to the contents of
+// existing files do not matter to the index and are not noticed. Calling
+// the returned function stops the watcher.
+func (ix *Index) Watch() func() {
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+		once sync.Once
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if ix.changed() {
+				ix.Refresh()
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() { close(done) })
+		wg.Wait()
+	}
+}
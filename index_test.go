@@ -0,0 +1,61 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuildIndex(t *testing.T) {
+	old := watchInterval
+	watchInterval = 5 * time.Millisecond
+	t.Cleanup(func() { watchInterval = old })
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(dataHomeKey, filepath.Join(tmp, "home"))
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "usr"))
+	user := filepath.Join(tmp, "home", "applications", "editor.desktop")
+	system := filepath.Join(tmp, "usr", "applications", "editor.desktop")
+	nested := filepath.Join(tmp, "usr", "applications", "kde", "viewer.desktop")
+	writeTestFile(t, user)
+	writeTestFile(t, system)
+	writeTestFile(t, nested)
+
+	ix, err := New("").BuildIndex(KindData, "applications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{"editor.desktop", "kde/viewer.desktop"}, ix.Names())
+	r, ok := ix.Lookup("editor.desktop")
+	if !ok {
+		t.Fatal("expected editor.desktop to be indexed")
+	}
+	eq(t, Resource{Path: user, Layer: 0}, r)
+	if r, ok = ix.Lookup("kde/viewer.desktop"); !ok || r.Path != nested || r.Layer != 1 {
+		t.Errorf("wrong nested entry %+v", r)
+	}
+	if _, ok = ix.Lookup("missing.desktop"); ok {
+		t.Error("unexpected entry")
+	}
+
+	stop := ix.Watch()
+	defer stop()
+	added := filepath.Join(tmp, "usr", "applications", "kde", "player.desktop")
+	writeTestFile(t, added)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if r, ok = ix.Lookup("kde/player.desktop"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not pick up the new file")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	eq(t, added, r.Path)
+
+	if _, err = BuildIndex(Kind(0), "", "applications"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
@@ -0,0 +1,152 @@
+// Package gdbus is a minimal session bus client that shells out to the
+// gdbus command line tool, which keeps the module free of a D-Bus library
+// dependency.
+package gdbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/harrybrwn/xdg/internal/sandbox"
+)
+
+// ErrUnavailable is returned when there is no gdbus binary or no session bus
+// to talk to.
+var ErrUnavailable = errors.New("gdbus: session bus unavailable")
+
+var (
+	command  = "gdbus"
+	lookPath = exec.LookPath
+	run      = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, name, args...).CombinedOutput()
+	}
+)
+
+// Error is returned when a method call fails.
+type Error struct {
+	Method string
+	Output string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	out := strings.TrimSpace(e.Output)
+	if len(out) == 0 {
+		return fmt.Sprintf("gdbus: %s: %v", e.Method, e.Err)
+	}
+	return fmt.Sprintf("gdbus: %s: %s", e.Method, out)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Available reports whether method calls can be made.
+func Available() bool {
+	if _, err := lookPath(command); err != nil {
+		return false
+	}
+	if len(os.Getenv("DBUS_SESSION_BUS_ADDRESS")) > 0 {
+		return true
+	}
+	runtime := os.Getenv("XDG_RUNTIME_DIR")
+	if len(runtime) == 0 {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(runtime, "bus"))
+	return err == nil
+}
+
+// Sandboxed reports whether the process is running inside a flatpak or snap
+// sandbox where desktop services should be reached through
+// xdg-desktop-portal.
+func Sandboxed() bool {
+	kind, _ := sandbox.Detect(os.LookupEnv, true)
+	return len(kind) > 0
+}
+
+// Call invokes a method on the session bus. Arguments must already be in
+// GVariant text format, see String, Strings, and Dict.
+func Call(ctx context.Context, dest, path, method string, args ...string) (string, error) {
+	if !Available() {
+		return "", ErrUnavailable
+	}
+	argv := append([]string{
+		"call", "--session",
+		"--dest", dest,
+		"--object-path", path,
+		"--method", method,
+	}, args...)
+	out, err := run(ctx, command, argv...)
+	if err != nil {
+		return "", &Error{Method: method, Output: string(out), Err: err}
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// IsServiceUnknown reports whether err was caused by the destination not
+// being present on the bus.
+func IsServiceUnknown(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	return strings.Contains(e.Output, "ServiceUnknown") ||
+		strings.Contains(e.Output, "UnknownMethod") ||
+		strings.Contains(e.Output, "UnknownInterface")
+}
+
+// String quotes s as a GVariant string literal.
+func String(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// Strings formats a GVariant string array.
+func Strings(list []string) string {
+	if len(list) == 0 {
+		return "@as []"
+	}
+	q := make([]string, len(list))
+	for i, s := range list {
+		q[i] = String(s)
+	}
+	return "[" + strings.Join(q, ", ") + "]"
+}
+
+// Variant wraps a GVariant literal so it can be used as a value in an a{sv}
+// dictionary.
+func Variant(v string) string { return "<" + v + ">" }
+
+// Dict formats an a{sv} dictionary from pairs of keys and variant values.
+// Keys appear in the order given.
+func Dict(pairs ...string) string {
+	if len(pairs) == 0 {
+		return "@a{sv} {}"
+	}
+	items := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		items = append(items, String(pairs[i])+": "+pairs[i+1])
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
@@ -0,0 +1,78 @@
+package gdbus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuoting(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{String("a'b\\c\nd"), `'a\'b\\c\nd'`},
+		{Strings(nil), "@as []"},
+		{Strings([]string{"a", "b"}), "['a', 'b']"},
+		{Dict(), "@a{sv} {}"},
+		{Dict("k", Variant("true")), "{'k': <true>}"},
+	} {
+		if tt.in != tt.want {
+			t.Errorf("got %s, want %s", tt.in, tt.want)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	oldLook, oldRun := lookPath, run
+	t.Cleanup(func() { lookPath, run = oldLook, oldRun })
+	lookPath = func(string) (string, error) { return "/usr/bin/gdbus", nil }
+	var argv []string
+	run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		argv = append([]string{name}, args...)
+		return []byte("(uint32 7,)\n"), nil
+	}
+	if _, err := Call(context.Background(), "d", "/p", "m"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bus"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Call(context.Background(), "d", "/p", "m", "'x'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "(uint32 7,)" {
+		t.Errorf("wrong output %q", out)
+	}
+	want := "gdbus call --session --dest d --object-path /p --method m 'x'"
+	if strings.Join(argv, " ") != want {
+		t.Errorf("got %q, want %q", strings.Join(argv, " "), want)
+	}
+
+	run = func(context.Context, string, ...string) ([]byte, error) {
+		return []byte("Error: GDBus.Error:org.freedesktop.DBus.Error.ServiceUnknown"), errors.New("exit status 1")
+	}
+	_, err = Call(context.Background(), "d", "/p", "m")
+	if !IsServiceUnknown(err) {
+		t.Errorf("expected service unknown error, got %v", err)
+	}
+	if IsServiceUnknown(errors.New("other")) {
+		t.Error("plain errors are not service unknown")
+	}
+}
+
+func TestSandboxed(t *testing.T) {
+	t.Setenv("FLATPAK_ID", "org.example.App")
+	if !Sandboxed() {
+		t.Error("flatpak should be sandboxed")
+	}
+	t.Setenv("FLATPAK_ID", "")
+	t.Setenv("SNAP_NAME", "tool")
+	if !Sandboxed() {
+		t.Error("snap should be sandboxed")
+	}
+}
@@ -0,0 +1,61 @@
+// Package sandbox detects the Flatpak or Snap sandbox the process runs in.
+// It is shared by the xdg package and the session bus client so both agree
+// on when the process is sandboxed.
+package sandbox
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+// Sandbox kinds returned by Detect.
+const (
+	Flatpak = "flatpak"
+	Snap    = "snap"
+)
+
+// infoFile is the file flatpak describes the running application in.
+var infoFile = "/.flatpak-info"
+
+// flatpakInfo returns the application ID from infoFile and whether the file
+// exists. The file describes the current process and cannot change, so it
+// is only read once.
+var flatpakInfo = sync.OnceValues(readInfo)
+
+func readInfo() (string, bool) {
+	f, err := keyfile.Load(infoFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false
+	} else if err != nil {
+		return "", true
+	}
+	if g := f.Group("Application"); g != nil {
+		if id, ok := g.Value("name"); ok {
+			return id, true
+		}
+	}
+	return "", true
+}
+
+// Detect returns the kind of sandbox described by lookupEnv, or the empty
+// string, and the flatpak application ID or the snap name. A flatpak is
+// recognized from $FLATPAK_ID or, when self is set and lookupEnv is the
+// environment of the current process, from /.flatpak-info. A snap is
+// recognized from $SNAP_NAME.
+func Detect(lookupEnv func(string) (string, bool), self bool) (kind, id string) {
+	if id, ok := lookupEnv("FLATPAK_ID"); ok && len(id) > 0 {
+		return Flatpak, id
+	}
+	if self {
+		if id, ok := flatpakInfo(); ok {
+			return Flatpak, id
+		}
+	}
+	if name, ok := lookupEnv("SNAP_NAME"); ok && len(name) > 0 {
+		return Snap, name
+	}
+	return "", ""
+}
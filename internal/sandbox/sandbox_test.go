@@ -0,0 +1,61 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func setInfoFile(t *testing.T, path string) {
+	t.Helper()
+	old := infoFile
+	t.Cleanup(func() {
+		infoFile = old
+		flatpakInfo = sync.OnceValues(readInfo)
+	})
+	infoFile = path
+	flatpakInfo = sync.OnceValues(readInfo)
+}
+
+func TestDetect(t *testing.T) {
+	setInfoFile(t, filepath.Join(t.TempDir(), ".flatpak-info"))
+	env := map[string]string{}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	check := func(wantKind, wantID string) {
+		t.Helper()
+		if kind, id := Detect(lookup, true); kind != wantKind || id != wantID {
+			t.Errorf("got %q %q, want %q %q", kind, id, wantKind, wantID)
+		}
+	}
+	check("", "")
+	env["FLATPAK_ID"] = ""
+	check("", "")
+	env["SNAP_NAME"] = "tool"
+	check(Snap, "tool")
+	env["FLATPAK_ID"] = "org.example.App"
+	check(Flatpak, "org.example.App")
+}
+
+func TestDetect_InfoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".flatpak-info")
+	data := "[Application]\nname=org.example.Info\nruntime=runtime/org.gnome.Platform\n\n[Instance]\nname=other\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setInfoFile(t, path)
+	none := func(string) (string, bool) { return "", false }
+	if kind, id := Detect(none, true); kind != Flatpak || id != "org.example.Info" {
+		t.Errorf("got %q %q", kind, id)
+	}
+	if kind, _ := Detect(none, false); kind != "" {
+		t.Errorf("the info file only describes the current process, got %q", kind)
+	}
+	// The file is read once.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if kind, _ := Detect(none, true); kind != Flatpak {
+		t.Errorf("expected the cached result, got %q", kind)
+	}
+}
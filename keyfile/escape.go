@@ -0,0 +1,111 @@
+package keyfile
+
+import "strings"
+
+// Escape applies the string escape sequences to s: backslash, newline, tab,
+// carriage return, and a leading space.
+func Escape(s string) string {
+	return escape(s, false)
+}
+
+// Unescape reverses Escape. Unknown escape sequences are left untouched.
+func Unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// SplitList splits an escaped list value on unescaped semicolons and
+// unescapes each element. A trailing semicolon is optional.
+func SplitList(s string) []string {
+	var (
+		list []string
+		cur  strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			if s[i+1] == ';' {
+				cur.WriteByte(';')
+			} else {
+				cur.WriteByte(c)
+				cur.WriteByte(s[i+1])
+			}
+			i++
+		case c == ';':
+			list = append(list, Unescape(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		list = append(list, Unescape(cur.String()))
+	}
+	return list
+}
+
+// JoinList escapes each element of list and joins them with semicolons,
+// including the trailing semicolon recommended by the specification.
+func JoinList(list []string) string {
+	var b strings.Builder
+	for _, s := range list {
+		b.WriteString(escape(s, true))
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+func escape(s string, list bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case ' ':
+			if i == 0 {
+				b.WriteString(`\s`)
+			} else {
+				b.WriteByte(c)
+			}
+		case ';':
+			if list {
+				b.WriteString(`\;`)
+			} else {
+				b.WriteByte(c)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
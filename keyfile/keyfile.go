@@ -0,0 +1,405 @@
+// Package keyfile reads and writes the key file format used by the
+// Desktop Entry Specification. The same syntax is used by mimeapps.list,
+// trashinfo files, and icon theme indexes.
+//
+// Files are edited in place: comments, blank lines, and the order of
+// groups and keys are preserved when a file is written back out.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/desktop-entry-spec/latest/basic-format.html
+package keyfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	xlocale "github.com/harrybrwn/xdg/locale"
+)
+
+// File is a parsed key file.
+type File struct {
+	header []string
+	groups []*Group
+}
+
+// Group is a named section of a key file.
+type Group struct {
+	name  string
+	raw   string
+	lines []line
+}
+
+type line struct {
+	key    string // empty for comments and blank lines
+	locale string
+	value  string // escaped value
+	raw    string // original text, empty once the line is modified
+}
+
+// SyntaxError is returned when a file cannot be parsed.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("keyfile: line %d: %s", e.Line, e.Msg)
+}
+
+// New returns an empty file.
+func New() *File { return &File{} }
+
+// Load reads and parses the file at path.
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a key file from r.
+func Parse(r io.Reader) (*File, error) {
+	var (
+		f   File
+		cur *Group
+		n   int
+		sc  = bufio.NewScanner(r)
+	)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		n++
+		raw := sc.Text()
+		text := strings.TrimSpace(raw)
+		switch {
+		case len(text) == 0 || text[0] == '#':
+			if cur == nil {
+				f.header = append(f.header, raw)
+			} else {
+				cur.lines = append(cur.lines, line{raw: raw})
+			}
+		case text[0] == '[':
+			if text[len(text)-1] != ']' || len(text) < 3 {
+				return nil, &SyntaxError{Line: n, Msg: "invalid group header"}
+			}
+			cur = &Group{name: text[1 : len(text)-1], raw: raw}
+			f.groups = append(f.groups, cur)
+		default:
+			if cur == nil {
+				return nil, &SyntaxError{Line: n, Msg: "key outside of a group"}
+			}
+			key, value, ok := strings.Cut(text, "=")
+			if !ok {
+				return nil, &SyntaxError{Line: n, Msg: "expected key=value"}
+			}
+			key = strings.TrimSpace(key)
+			var locale string
+			if i := strings.IndexByte(key, '['); i >= 0 {
+				if key[len(key)-1] != ']' {
+					return nil, &SyntaxError{Line: n, Msg: "invalid locale suffix"}
+				}
+				key, locale = key[:i], key[i+1:len(key)-1]
+			}
+			if len(key) == 0 {
+				return nil, &SyntaxError{Line: n, Msg: "empty key"}
+			}
+			cur.lines = append(cur.lines, line{
+				key:    key,
+				locale: locale,
+				value:  strings.TrimLeft(value, " \t"),
+				raw:    raw,
+			})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Groups returns the groups in file order.
+func (f *File) Groups() []*Group { return f.groups }
+
+// Group returns the first group with the given name or nil if there is no
+// such group.
+func (f *File) Group(name string) *Group {
+	for _, g := range f.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// AddGroup returns the group with the given name, appending a new group to
+// the end of the file if it does not exist.
+func (f *File) AddGroup(name string) *Group {
+	if g := f.Group(name); g != nil {
+		return g
+	}
+	if n := len(f.groups); n > 0 {
+		last := f.groups[n-1]
+		if l := len(last.lines); l == 0 || !last.lines[l-1].blank() {
+			last.lines = append(last.lines, line{})
+		}
+	}
+	g := &Group{name: name}
+	f.groups = append(f.groups, g)
+	return g
+}
+
+// RemoveGroup removes every group with the given name and reports whether
+// any were removed.
+func (f *File) RemoveGroup(name string) bool {
+	groups := f.groups[:0]
+	for _, g := range f.groups {
+		if g.name != name {
+			groups = append(groups, g)
+		}
+	}
+	removed := len(groups) != len(f.groups)
+	f.groups = groups
+	return removed
+}
+
+// WriteTo writes the file to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	for _, h := range f.header {
+		b.WriteString(h)
+		b.WriteByte('\n')
+	}
+	for _, g := range f.groups {
+		if len(g.raw) > 0 {
+			b.WriteString(g.raw)
+		} else {
+			b.WriteString("[" + g.name + "]")
+		}
+		b.WriteByte('\n')
+		for _, l := range g.lines {
+			b.WriteString(l.String())
+			b.WriteByte('\n')
+		}
+	}
+	return b.WriteTo(w)
+}
+
+// Bytes returns the serialized file.
+func (f *File) Bytes() []byte {
+	var b bytes.Buffer
+	_, _ = f.WriteTo(&b)
+	return b.Bytes()
+}
+
+// Save writes the file to path by writing a temporary file in the same
+// directory and renaming it into place.
+func (f *File) Save(path string, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = f.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Name returns the group name.
+func (g *Group) Name() string { return g.name }
+
+// Keys returns the unlocalized keys in the group in file order.
+func (g *Group) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, l := range g.lines {
+		if len(l.key) == 0 || seen[l.key] {
+			continue
+		}
+		seen[l.key] = true
+		keys = append(keys, l.key)
+	}
+	return keys
+}
+
+// Locales returns the locales that key has a localized value for.
+func (g *Group) Locales(key string) []string {
+	var locales []string
+	for _, l := range g.lines {
+		if l.key == key && len(l.locale) > 0 {
+			locales = append(locales, l.locale)
+		}
+	}
+	return locales
+}
+
+// Has reports whether the group has an unlocalized value for key.
+func (g *Group) Has(key string) bool { return g.find(key, "") >= 0 }
+
+// Raw returns the value of key exactly as it appears in the file, without
+// unescaping.
+func (g *Group) Raw(key string) (string, bool) {
+	i := g.find(key, "")
+	if i < 0 {
+		return "", false
+	}
+	return g.lines[i].value, true
+}
+
+// Value returns the unescaped string value of key.
+func (g *Group) Value(key string) (string, bool) {
+	return g.LocaleValue(key, "")
+}
+
+// LocaleValue returns the value of key best matching the locale, falling
+// back to the unlocalized value. The locale uses the lang_COUNTRY@MODIFIER
+// form of the LC_MESSAGES category.
+func (g *Group) LocaleValue(key, locale string) (string, bool) {
+	i := g.findLocale(key, locale)
+	if i < 0 {
+		return "", false
+	}
+	return Unescape(g.lines[i].value), true
+}
+
+// List returns the value of key as a list of strings.
+func (g *Group) List(key string) []string {
+	return g.LocaleList(key, "")
+}
+
+// LocaleList returns the list value of key best matching the locale.
+func (g *Group) LocaleList(key, locale string) []string {
+	i := g.findLocale(key, locale)
+	if i < 0 {
+		return nil
+	}
+	return SplitList(g.lines[i].value)
+}
+
+// Bool returns the boolean value of key. The second result is false if the
+// key is missing or is not "true" or "false".
+func (g *Group) Bool(key string) (value, ok bool) {
+	v, ok := g.Raw(key)
+	switch strings.TrimSpace(v) {
+	case "true":
+		return true, ok
+	case "false":
+		return false, ok
+	}
+	return false, false
+}
+
+// Int returns the integer value of key.
+func (g *Group) Int(key string) (int, bool) {
+	v, ok := g.Raw(key)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	return n, err == nil
+}
+
+// SetRaw sets the value of key without escaping it.
+func (g *Group) SetRaw(key, locale, value string) {
+	l := line{key: key, locale: locale, value: value}
+	if i := g.find(key, locale); i >= 0 {
+		g.lines[i] = l
+		return
+	}
+	at := g.insertPos()
+	g.lines = append(g.lines, line{})
+	copy(g.lines[at+1:], g.lines[at:])
+	g.lines[at] = l
+}
+
+// Set sets the string value of key.
+func (g *Group) Set(key, value string) { g.SetRaw(key, "", Escape(value)) }
+
+// SetLocale sets the localized string value of key.
+func (g *Group) SetLocale(key, locale, value string) { g.SetRaw(key, locale, Escape(value)) }
+
+// SetList sets key to a list of strings.
+func (g *Group) SetList(key string, list []string) { g.SetRaw(key, "", JoinList(list)) }
+
+// SetBool sets key to a boolean.
+func (g *Group) SetBool(key string, v bool) { g.SetRaw(key, "", strconv.FormatBool(v)) }
+
+// SetInt sets key to an integer.
+func (g *Group) SetInt(key string, v int) { g.SetRaw(key, "", strconv.Itoa(v)) }
+
+// Delete removes key and all of its localized values, reporting whether
+// anything was removed.
+func (g *Group) Delete(key string) bool {
+	lines := g.lines[:0]
+	for _, l := range g.lines {
+		if l.key != key {
+			lines = append(lines, l)
+		}
+	}
+	removed := len(lines) != len(g.lines)
+	g.lines = lines
+	return removed
+}
+
+func (g *Group) find(key, locale string) int {
+	for i, l := range g.lines {
+		if l.key == key && l.locale == locale {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Group) findLocale(key, locale string) int {
+	if len(locale) > 0 {
+		if m := xlocale.Match(g.Locales(key), []string{locale}); len(m) > 0 {
+			return g.find(key, m)
+		}
+	}
+	return g.find(key, "")
+}
+
+// insertPos returns the index new keys are inserted at: after the last key
+// or before any trailing blank lines.
+func (g *Group) insertPos() int {
+	for i := len(g.lines) - 1; i >= 0; i-- {
+		if len(g.lines[i].key) > 0 {
+			return i + 1
+		}
+	}
+	i := len(g.lines)
+	for i > 0 && g.lines[i-1].blank() {
+		i--
+	}
+	return i
+}
+
+func (l *line) blank() bool {
+	return len(l.key) == 0 && len(strings.TrimSpace(l.raw)) == 0
+}
+
+func (l *line) String() string {
+	if len(l.raw) > 0 || len(l.key) == 0 {
+		return l.raw
+	}
+	if len(l.locale) > 0 {
+		return l.key + "[" + l.locale + "]=" + l.value
+	}
+	return l.key + "=" + l.value
+}
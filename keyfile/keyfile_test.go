@@ -0,0 +1,191 @@
+package keyfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFile = `# header comment
+[Desktop Entry]
+Type=Application
+Name=Files
+Name[de]=Dateien
+Name[sr@latin]=Datoteke
+Name[sr_YU]=Датотеке
+Comment = Access\sand organize\nfiles
+Keywords=folder;manager;a\;b;
+Terminal=false
+
+# actions
+[Desktop Action new-window]
+Name=New Window
+`
+
+func TestRoundTrip(t *testing.T) {
+	f, err := Parse(strings.NewReader(testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(f.Bytes()); got != testFile {
+		t.Errorf("round trip changed the file:\n%s", got)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	f, err := Parse(strings.NewReader(testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Groups()) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(f.Groups()))
+	}
+	if f.Group("nope") != nil {
+		t.Error("expected nil group")
+	}
+	g := f.Group("Desktop Entry")
+	if g.Name() != "Desktop Entry" {
+		t.Errorf("wrong name %q", g.Name())
+	}
+	want := []string{"Type", "Name", "Comment", "Keywords", "Terminal"}
+	if !reflect.DeepEqual(g.Keys(), want) {
+		t.Errorf("got keys %v, want %v", g.Keys(), want)
+	}
+	for _, tt := range []struct{ locale, want string }{
+		{"", "Files"},
+		{"de_DE.UTF-8", "Dateien"},
+		{"sr_YU@latin", "Датотеке"},
+		{"sr_RS@latin", "Datoteke"},
+		{"fr_FR", "Files"},
+	} {
+		v, ok := g.LocaleValue("Name", tt.locale)
+		if !ok || v != tt.want {
+			t.Errorf("LocaleValue(Name, %q) = %q, want %q", tt.locale, v, tt.want)
+		}
+	}
+	if v, _ := g.Value("Comment"); v != "Access and organize\nfiles" {
+		t.Errorf("wrong comment %q", v)
+	}
+	if l := g.List("Keywords"); !reflect.DeepEqual(l, []string{"folder", "manager", "a;b"}) {
+		t.Errorf("wrong list %q", l)
+	}
+	if v, ok := g.Bool("Terminal"); v || !ok {
+		t.Error("expected Terminal=false")
+	}
+	if _, ok := g.Bool("Name"); ok {
+		t.Error("Name is not a boolean")
+	}
+	if _, ok := g.Value("Missing"); ok {
+		t.Error("expected missing key")
+	}
+	if g.List("Missing") != nil {
+		t.Error("expected nil list")
+	}
+}
+
+func TestModify(t *testing.T) {
+	f, err := Parse(strings.NewReader(testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := f.Group("Desktop Entry")
+	g.Set("Name", " Home")
+	g.SetLocale("Name", "fr", "Fichiers")
+	g.SetList("MimeType", []string{"inode/directory"})
+	g.SetBool("NoDisplay", true)
+	g.SetInt("X-Size", 48)
+	if !g.Delete("Keywords") || g.Delete("Keywords") {
+		t.Error("Delete should only remove existing keys")
+	}
+	if n, ok := g.Int("X-Size"); !ok || n != 48 {
+		t.Errorf("wrong int %d", n)
+	}
+	f.AddGroup("Extra").Set("Key", "v")
+	if !f.RemoveGroup("Desktop Action new-window") {
+		t.Error("expected group to be removed")
+	}
+	want := `# header comment
+[Desktop Entry]
+Type=Application
+Name=\sHome
+Name[de]=Dateien
+Name[sr@latin]=Datoteke
+Name[sr_YU]=Датотеке
+Comment = Access\sand organize\nfiles
+Terminal=false
+Name[fr]=Fichiers
+MimeType=inode/directory;
+NoDisplay=true
+X-Size=48
+
+# actions
+[Extra]
+Key=v
+`
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	f := New()
+	f.AddGroup("A").Set("k", "1")
+	f.AddGroup("B").Set("k", "2")
+	if f.AddGroup("A") != f.Group("A") {
+		t.Error("AddGroup should return existing groups")
+	}
+	if got := string(f.Bytes()); got != "[A]\nk=1\n\n[B]\nk=2\n" {
+		t.Errorf("got %q", got)
+	}
+	path := filepath.Join(t.TempDir(), "test.desktop")
+	if err := f.Save(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.Group("B").Value("k"); v != "2" {
+		t.Errorf("wrong value %q", v)
+	}
+	if _, err = Load(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"Key=value\n",
+		"[Group\n",
+		"[Group]\nno equals\n",
+		"[Group]\nKey[de=x\n",
+		"[Group]\n=x\n",
+	} {
+		_, err := Parse(strings.NewReader(in))
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("expected syntax error for %q, got %v", in, err)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, s := range []string{"", " a\\b\n\tc\r", "x;y"} {
+		if got := Unescape(Escape(s)); got != s {
+			t.Errorf("Unescape(Escape(%q)) = %q", s, got)
+		}
+	}
+	if got := Unescape(`a\qb\`); got != `a\qb\` {
+		t.Errorf("unknown escapes should be kept, got %q", got)
+	}
+	list := []string{"a;b", `c\d`, " e"}
+	if got := SplitList(JoinList(list)); !reflect.DeepEqual(got, list) {
+		t.Errorf("got %q, want %q", got, list)
+	}
+	if got := SplitList(`a\\;b`); !reflect.DeepEqual(got, []string{`a\`, "b"}) {
+		t.Errorf("got %q", got)
+	}
+}
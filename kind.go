@@ -0,0 +1,107 @@
+package xdg
+
+import "fmt"
+
+// Kind names one of the base directories.
+type Kind int
+
+const (
+	KindConfig Kind = iota + 1
+	KindData
+	KindCache
+	KindState
+	KindRuntime
+)
+
+// Kinds lists every Kind.
+var Kinds = []Kind{KindConfig, KindData, KindCache, KindState, KindRuntime}
+
+func (k Kind) String() string {
+	switch k {
+	case KindConfig:
+		return "config"
+	case KindData:
+		return "data"
+	case KindCache:
+		return "cache"
+	case KindState:
+		return "state"
+	case KindRuntime:
+		return "runtime"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// key returns the environment variable of the kind's home directory.
+func (k Kind) key() (string, bool) {
+	switch k {
+	case KindConfig:
+		return configHomeKey, true
+	case KindData:
+		return dataHomeKey, true
+	case KindCache:
+		return cacheHomeKey, true
+	case KindState:
+		return stateHomeKey, true
+	case KindRuntime:
+		return runtimeDirKey, true
+	}
+	return "", false
+}
+
+// Lookup returns the application's directory of the given kind. See
+// (*XDG).Lookup.
+func Lookup(kind Kind, name string) (string, error) { return newXdg(name).Lookup(kind) }
+
+// SearchDirs returns the application's search path for kind. See
+// (*XDG).SearchDirs.
+func SearchDirs(kind Kind, name string) []string { return newXdg(name).SearchDirs(kind) }
+
+// Lookup returns the directory of the given kind, the same as ConfigE,
+// DataE, CacheE, StateE or RuntimeE.
+func (xdg *XDG) Lookup(kind Kind) (string, error) {
+	key, ok := kind.key()
+	if !ok {
+		return "", fmt.Errorf("xdg: unknown directory kind %v", kind)
+	}
+	return xdg.getDirE(key)
+}
+
+// SearchDirs returns the directories searched for files of the given kind in
+// precedence order: the home directory followed by $XDG_CONFIG_DIRS for
+// KindConfig or $XDG_DATA_DIRS for KindData. The other kinds have no system
+// directories, so only the home directory is returned. Directories that
+// cannot be resolved are left out.
+func (xdg *XDG) SearchDirs(kind Kind) []string {
+	var dirs []string
+	if dir, err := xdg.Lookup(kind); err == nil {
+		dirs = append(dirs, dir)
+	}
+	switch kind {
+	case KindConfig:
+		dirs = append(dirs, xdg.ConfigDirs()...)
+	case KindData:
+		dirs = append(dirs, xdg.DataDirs()...)
+	}
+	return dirs
+}
+
+// MarshalText encodes the kind as its name.
+func (k Kind) MarshalText() ([]byte, error) {
+	if _, ok := k.key(); !ok {
+		return nil, fmt.Errorf("xdg: unknown directory kind %v", k)
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind name such as "config".
+func (k *Kind) UnmarshalText(text []byte) error {
+	for _, kind := range Kinds {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("xdg: unknown directory kind %q", text)
+}
@@ -0,0 +1,41 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "usr"))
+	t.Setenv(runtimeDirKey, filepath.Join(tmp, "run"))
+	for kind, want := range map[Kind]string{
+		KindConfig:  Config("app"),
+		KindData:    Data("app"),
+		KindCache:   Cache("app"),
+		KindState:   State("app"),
+		KindRuntime: filepath.Join(tmp, "run", "app"),
+	} {
+		dir, err := Lookup(kind, "app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, want, dir)
+	}
+	if _, err := Lookup(Kind(0), "app"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+	eq(t, "state", KindState.String())
+	eq(t, "Kind(42)", Kind(42).String())
+
+	arrEq(t, []string{Config("app"), filepath.Join(tmp, "etc", "app")}, SearchDirs(KindConfig, "app"))
+	arrEq(t, []string{Data("app"), filepath.Join(tmp, "usr", "app")}, SearchDirs(KindData, "app"))
+	arrEq(t, []string{Cache("app")}, SearchDirs(KindCache, "app"))
+	t.Setenv(runtimeDirKey, "")
+	if dirs := SearchDirs(KindRuntime, "app"); len(dirs) != 0 {
+		t.Errorf("expected no runtime dirs, got %v", dirs)
+	}
+}
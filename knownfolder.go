@@ -0,0 +1,66 @@
+package xdg
+
+import "path/filepath"
+
+// knownFolderID identifies a Windows Known Folder.
+type knownFolderID int
+
+const (
+	folderRoamingAppData knownFolderID = iota
+	folderLocalAppData
+	folderProgramData
+)
+
+// knownFolderEnv are the environment variables that mirror each Known
+// Folder, used when the shell API is unavailable.
+var knownFolderEnv = [...]string{
+	folderRoamingAppData: "APPDATA",
+	folderLocalAppData:   "LOCALAPPDATA",
+	folderProgramData:    "PROGRAMDATA",
+}
+
+// knownFolder asks the shell for a Known Folder, falling back to its
+// environment variable. A custom environment from WithEnviron is always
+// used as is since the shell only knows about the current process.
+func (xdg *XDG) knownFolder(id knownFolderID) string {
+	if xdg.environ == nil {
+		if p := shellKnownFolder(id); len(p) > 0 {
+			return p
+		}
+	}
+	p, _ := xdg.lookupEnv(knownFolderEnv[id])
+	return p
+}
+
+// windowsBase returns the Known Folder used in place of the XDG default for
+// key on Windows, following os.UserConfigDir and os.UserCacheDir:
+//
+//	XDG_CONFIG_HOME  %APPDATA%
+//	XDG_DATA_HOME    %LOCALAPPDATA%
+//	XDG_STATE_HOME   %LOCALAPPDATA%
+//	XDG_CACHE_HOME   %LOCALAPPDATA%\cache
+func (xdg *XDG) windowsBase(key string) string {
+	switch key {
+	case configHomeKey:
+		return xdg.knownFolder(folderRoamingAppData)
+	case dataHomeKey, stateHomeKey:
+		return xdg.knownFolder(folderLocalAppData)
+	case cacheHomeKey:
+		if p := xdg.knownFolder(folderLocalAppData); len(p) > 0 {
+			return filepath.Join(p, "cache")
+		}
+	}
+	return ""
+}
+
+// windowsDirs returns the system search path used on Windows for
+// XDG_CONFIG_DIRS and XDG_DATA_DIRS, which is %PROGRAMDATA%.
+func (xdg *XDG) windowsDirs(key string) []string {
+	switch key {
+	case configDirsKey, dataDirsKey:
+		if p := xdg.knownFolder(folderProgramData); len(p) > 0 {
+			return []string{p}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+//go:build !windows
+
+package xdg
+
+func shellKnownFolder(knownFolderID) string { return "" }
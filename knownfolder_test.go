@@ -0,0 +1,31 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWindowsDefaults(t *testing.T) {
+	unsetAll()
+	t.Setenv("APPDATA", `C:\Users\t\AppData\Roaming`)
+	t.Setenv("LOCALAPPDATA", `C:\Users\t\AppData\Local`)
+	t.Setenv("PROGRAMDATA", `C:\ProgramData`)
+	x := NewXDG(NewDirFinder("app"), WithHomeDir(`C:\Users\t`), WithGOOS("windows"))
+	eq(t, filepath.Join(`C:\Users\t\AppData\Roaming`, "app"), x.Config())
+	eq(t, filepath.Join(`C:\Users\t\AppData\Local`, "app"), x.Data())
+	eq(t, filepath.Join(`C:\Users\t\AppData\Local`, "app"), x.State())
+	eq(t, filepath.Join(`C:\Users\t\AppData\Local`, "cache", "app"), x.Cache())
+	eq(t, "", x.Runtime())
+	arrEq(t, []string{filepath.Join(`C:\ProgramData`, "app")}, x.ConfigDirs())
+	arrEq(t, []string{filepath.Join(`C:\ProgramData`, "app")}, x.DataDirs())
+
+	// XDG variables still win
+	t.Setenv(configHomeKey, `D:\config`)
+	eq(t, filepath.Join(`D:\config`, "app"), x.Config())
+
+	// missing known folders fall back to the home directory
+	t.Setenv("LOCALAPPDATA", "")
+	t.Setenv("PROGRAMDATA", "")
+	eq(t, filepath.Join(`C:\Users\t`, defaultCacheBase, "app"), x.Cache())
+	arrEq(t, nil, x.DataDirs())
+}
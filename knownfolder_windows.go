@@ -0,0 +1,50 @@
+package xdg
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	shell32                  = syscall.NewLazyDLL("shell32.dll")
+	ole32                    = syscall.NewLazyDLL("ole32.dll")
+	procSHGetKnownFolderPath = shell32.NewProc("SHGetKnownFolderPath")
+	procCoTaskMemFree        = ole32.NewProc("CoTaskMemFree")
+)
+
+var knownFolderGUID = [...]syscall.GUID{
+	// FOLDERID_RoamingAppData
+	folderRoamingAppData: guid(0x3EB685DB, 0x65F9, 0x4CF6, [8]byte{0xA0, 0x3A, 0xE3, 0xEF, 0x65, 0x72, 0x9F, 0x3D}),
+	// FOLDERID_LocalAppData
+	folderLocalAppData: guid(0xF1B32785, 0x6FBA, 0x4FCF, [8]byte{0x9D, 0x55, 0x7B, 0x8E, 0x7F, 0x15, 0x70, 0x91}),
+	// FOLDERID_ProgramData
+	folderProgramData: guid(0x62AB5D82, 0xFDC1, 0x4DC3, [8]byte{0xA9, 0xDD, 0x07, 0x0D, 0x1D, 0x49, 0x5D, 0x97}),
+}
+
+func guid(d1 uint32, d2, d3 uint16, d4 [8]byte) syscall.GUID {
+	return syscall.GUID{Data1: d1, Data2: d2, Data3: d3, Data4: d4}
+}
+
+func shellKnownFolder(id knownFolderID) string {
+	if procSHGetKnownFolderPath.Find() != nil || procCoTaskMemFree.Find() != nil {
+		return ""
+	}
+	var p *uint16
+	hr, _, _ := procSHGetKnownFolderPath.Call(
+		uintptr(unsafe.Pointer(&knownFolderGUID[id])),
+		0, 0,
+		uintptr(unsafe.Pointer(&p)),
+	)
+	if p == nil {
+		return ""
+	}
+	defer procCoTaskMemFree.Call(uintptr(unsafe.Pointer(p)))
+	if hr != 0 {
+		return ""
+	}
+	n := 0
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; n++ {
+		ptr = unsafe.Add(ptr, 2)
+	}
+	return syscall.UTF16ToString(unsafe.Slice(p, n))
+}
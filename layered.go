@@ -0,0 +1,143 @@
+package xdg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// LoadLayered reads file from every config directory, from the lowest
+// precedence entry of $XDG_CONFIG_DIRS up to $XDG_CONFIG_HOME/<name>, and
+// calls load with the contents of each copy. Decoding every layer into the
+// same value lets the user's file override system wide defaults. It returns
+// the paths that were loaded in the order they were applied. The error
+// wraps fs.ErrNotExist if there is no copy of file at all.
+func (xdg *XDG) LoadLayered(file string, load func(path string, data []byte) error) ([]string, error) {
+	dirs := xdg.configSearchPath()
+	name := filepath.ToSlash(file)
+	var paths []string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if len(dirs[i]) == 0 {
+			continue
+		}
+		data, err := fs.ReadFile(Dir(dirs[i]), name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return paths, err
+		}
+		p := filepath.Join(dirs[i], file)
+		if err = load(p, data); err != nil {
+			return paths, err
+		}
+		paths = append(paths, p)
+	}
+	if len(paths) == 0 {
+		return nil, &fs.PathError{Op: "load", Path: file, Err: fs.ErrNotExist}
+	}
+	return paths, nil
+}
+
+// Layered decodes every copy of file on the config search path and merges
+// them from lowest to highest precedence. merge is called with the result of
+// the lower layers and the next layer, and returns their combination. See
+// (*XDG).LoadLayered.
+func Layered[T any](xdg *XDG, file string, decode func([]byte) (T, error), merge func(base, over T) T) (T, []string, error) {
+	var (
+		result T
+		first  = true
+	)
+	paths, err := xdg.LoadLayered(file, func(_ string, data []byte) error {
+		v, err := decode(data)
+		if err != nil {
+			return err
+		}
+		if first {
+			result, first = v, false
+		} else {
+			result = merge(result, v)
+		}
+		return nil
+	})
+	return result, paths, err
+}
+
+// MergeMaps is a merge function for Layered that deeply merges decoded
+// documents. Nested maps are merged key by key and any other value in over
+// replaces the one in base. base is modified and returned.
+func MergeMaps(base, over map[string]any) map[string]any {
+	if base == nil {
+		base = make(map[string]any, len(over))
+	}
+	for k, v := range over {
+		if sub, ok := v.(map[string]any); ok {
+			if prev, ok := base[k].(map[string]any); ok {
+				base[k] = MergeMaps(prev, sub)
+				continue
+			}
+		}
+		base[k] = v
+	}
+	return base
+}
+
+// Provenance records where a setting in a merged config came from.
+type Provenance struct {
+	// Path is the file that supplied the value.
+	Path string
+	// Layer is the position of Path in the order the layers were applied,
+	// starting at 0 for the lowest precedence system copy.
+	Layer int
+}
+
+// LoadMergedWithProvenance decodes every copy of file on the config search
+// path into a map, merges them with MergeMaps, and reports which layer each
+// value of the result came from. The provenance map is keyed by the dotted
+// path of every leaf of the merged document, such as "ui.theme"; empty maps
+// count as leaves. decode is called like json.Unmarshal, which is used when
+// it is nil. See (*XDG).LoadLayered.
+func (xdg *XDG) LoadMergedWithProvenance(file string, decode func([]byte, any) error) (map[string]any, map[string]Provenance, error) {
+	if decode == nil {
+		decode = json.Unmarshal
+	}
+	var (
+		merged map[string]any
+		layers int
+		// set holds the last layer that set each leaf.
+		set = make(map[string]Provenance)
+	)
+	_, err := xdg.LoadLayered(file, func(path string, data []byte) error {
+		var doc map[string]any
+		if err := decode(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		layer := Provenance{Path: path, Layer: layers}
+		layers++
+		merged = MergeMaps(merged, doc)
+		walkLeaves("", doc, func(key string) { set[key] = layer })
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	prov := make(map[string]Provenance)
+	walkLeaves("", merged, func(key string) { prov[key] = set[key] })
+	return merged, prov, nil
+}
+
+// walkLeaves calls fn with the dotted path of every value in m that is not
+// a non-empty map.
+func walkLeaves(prefix string, m map[string]any, fn func(key string)) {
+	for k, v := range m {
+		if len(prefix) > 0 {
+			k = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]any); ok && len(sub) > 0 {
+			walkLeaves(k, sub, fn)
+		} else {
+			fn(k)
+		}
+	}
+}
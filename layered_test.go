@@ -0,0 +1,99 @@
+package xdg
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLayered(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(configHomeKey, filepath.Join(tmp, "home"))
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc")+listSeparator+filepath.Join(tmp, "vendor"))
+	writeTestFile(t, filepath.Join(tmp, "vendor/app/config.json"), `{"theme":"light","size":10,"ui":{"font":"mono","bar":true}}`)
+	writeTestFile(t, filepath.Join(tmp, "etc/app/config.json"), `{"size":12}`)
+	writeTestFile(t, filepath.Join(tmp, "home/app/config.json"), `{"theme":"dark","ui":{"font":"sans"}}`)
+
+	x := New("app")
+	var cfg struct {
+		Theme string
+		Size  int
+	}
+	paths, err := x.LoadLayered("config.json", func(_ string, data []byte) error {
+		return json.Unmarshal(data, &cfg)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{
+		filepath.Join(tmp, "vendor/app/config.json"),
+		filepath.Join(tmp, "etc/app/config.json"),
+		filepath.Join(tmp, "home/app/config.json"),
+	}, paths)
+	eq(t, "dark", cfg.Theme)
+	eq(t, 12, cfg.Size)
+
+	m, _, err := Layered(x, "config.json", func(data []byte) (map[string]any, error) {
+		var m map[string]any
+		return m, json.Unmarshal(data, &m)
+	}, MergeMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "dark", m["theme"].(string))
+	eq(t, 12.0, m["size"].(float64))
+	ui := m["ui"].(map[string]any)
+	eq(t, "sans", ui["font"].(string))
+	eq(t, true, ui["bar"].(bool))
+
+	_, err = x.LoadLayered("missing.json", func(string, []byte) error { return nil })
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestLoadMergedWithProvenance(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(configHomeKey, filepath.Join(tmp, "home"))
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc")+listSeparator+filepath.Join(tmp, "vendor"))
+	vendor := filepath.Join(tmp, "vendor/app/config.json")
+	etc := filepath.Join(tmp, "etc/app/config.json")
+	home := filepath.Join(tmp, "home/app/config.json")
+	writeTestFile(t, vendor, `{"theme":"light","size":10,"ui":{"font":"mono","bar":true},"keys":{"a":1}}`)
+	writeTestFile(t, etc, `{"size":12,"keys":"none"}`)
+	writeTestFile(t, home, `{"theme":"dark","ui":{"font":"sans"},"keys":{"b":2},"plugins":{}}`)
+
+	m, prov, err := New("app").LoadMergedWithProvenance("config.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "dark", m["theme"].(string))
+	want := map[string]Provenance{
+		"theme":   {home, 2},
+		"size":    {etc, 1},
+		"ui.font": {home, 2},
+		"ui.bar":  {vendor, 0},
+		"keys.b":  {home, 2},
+		"plugins": {home, 2},
+	}
+	if len(prov) != len(want) {
+		t.Errorf("got %v, want %v", prov, want)
+	}
+	for k, p := range want {
+		if prov[k] != p {
+			t.Errorf("%s: got %+v, want %+v", k, prov[k], p)
+		}
+	}
+
+	writeTestFile(t, home, `{`)
+	if _, _, err = New("app").LoadMergedWithProvenance("config.json", nil); err == nil {
+		t.Error("expected a decoding error")
+	}
+	if _, _, err = New("app").LoadMergedWithProvenance("missing.json", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
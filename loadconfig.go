@@ -0,0 +1,39 @@
+package xdg
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// LoadConfig finds file on the config search path of the application name
+// and decodes it into a T. See LoadConfigFrom.
+func LoadConfig[T any](name, file string, decode func([]byte, any) error) (T, string, error) {
+	return LoadConfigFrom[T](newXdg(name), file, decode)
+}
+
+// LoadConfigFrom decodes the copy of file that takes precedence on the
+// config search path of xdg, as found by SearchConfigFile, and returns it
+// with the path that was used. decode is called with the contents and a
+// pointer to the result, so json.Unmarshal and the Unmarshal functions of
+// most YAML and TOML libraries can be passed directly. A nil decode uses
+// json.Unmarshal. The error wraps fs.ErrNotExist if there is no copy of
+// file.
+func LoadConfigFrom[T any](xdg *XDG, file string, decode func([]byte, any) error) (T, string, error) {
+	var v T
+	if decode == nil {
+		decode = json.Unmarshal
+	}
+	path, err := xdg.SearchConfigFile(file)
+	if err != nil {
+		return v, "", err
+	}
+	data, err := Dir(filepath.Dir(path)).ReadFile(filepath.Base(path))
+	if err != nil {
+		return v, path, err
+	}
+	if err = decode(data, &v); err != nil {
+		return v, path, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, path, nil
+}
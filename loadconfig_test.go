@@ -0,0 +1,56 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	type config struct {
+		Theme string `json:"theme"`
+		Size  int    `json:"size"`
+	}
+	system := filepath.Join(tmp, "etc", "app", "app.json")
+	writeTestFile(t, system, `{"theme": "light", "size": 10}`)
+
+	c, path, err := LoadConfig[config]("app", "app.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, system, path)
+	eq(t, config{Theme: "light", Size: 10}, c)
+
+	user := filepath.Join(Config("app"), "app.json")
+	writeTestFile(t, user, `{"theme": "dark"}`)
+	c, path, err = LoadConfigFrom[config](New("app"), "app.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, user, path)
+	eq(t, config{Theme: "dark"}, c)
+
+	lines, _, err := LoadConfig[[]string]("app", "app.json", func(b []byte, v any) error {
+		*v.(*[]string) = strings.Split(string(b), " ")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(lines))
+
+	writeTestFile(t, user, `{"theme": `)
+	if _, path, err = LoadConfig[config]("app", "app.json", nil); err == nil || !strings.Contains(err.Error(), user) {
+		t.Errorf("expected a decode error naming %s, got %v", user, err)
+	}
+	eq(t, user, path)
+	if _, _, err = LoadConfig[config]("app", "missing.json", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package locale implements the locale matching rules used for localized
+// values in desktop entries and other freedesktop.org resources.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/desktop-entry-spec/latest/localized-keys.html
+package locale
+
+import (
+	"os"
+	"strings"
+)
+
+// Locale is a POSIX locale name of the form lang_COUNTRY.ENCODING@MODIFIER.
+type Locale struct {
+	Lang     string
+	Country  string
+	Encoding string
+	Modifier string
+}
+
+// Parse splits a locale name into its parts. Missing parts are left empty.
+func Parse(s string) Locale {
+	var l Locale
+	s, l.Modifier, _ = strings.Cut(s, "@")
+	s, l.Encoding, _ = strings.Cut(s, ".")
+	l.Lang, l.Country, _ = strings.Cut(s, "_")
+	return l
+}
+
+func (l Locale) String() string {
+	s := l.Lang
+	if len(l.Country) > 0 {
+		s += "_" + l.Country
+	}
+	if len(l.Encoding) > 0 {
+		s += "." + l.Encoding
+	}
+	if len(l.Modifier) > 0 {
+		s += "@" + l.Modifier
+	}
+	return s
+}
+
+// Candidates returns the names that match l in order of preference:
+// lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, then lang. The
+// encoding is never part of a candidate.
+func (l Locale) Candidates() []string {
+	if len(l.Lang) == 0 {
+		return nil
+	}
+	c := make([]string, 0, 4)
+	if len(l.Country) > 0 && len(l.Modifier) > 0 {
+		c = append(c, l.Lang+"_"+l.Country+"@"+l.Modifier)
+	}
+	if len(l.Country) > 0 {
+		c = append(c, l.Lang+"_"+l.Country)
+	}
+	if len(l.Modifier) > 0 {
+		c = append(c, l.Lang+"@"+l.Modifier)
+	}
+	return append(c, l.Lang)
+}
+
+// Messages returns the locale used for messages taken from LC_ALL,
+// LC_MESSAGES, or LANG. The C and POSIX locales are reported as the empty
+// string.
+func Messages() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(key)
+		if len(v) == 0 {
+			continue
+		}
+		if v == "C" || v == "POSIX" || strings.HasPrefix(v, "C.") {
+			return ""
+		}
+		return v
+	}
+	return ""
+}
+
+// Preferred returns the user's requested locales in order of preference.
+// The colon separated LANGUAGE variable is honored unless the messages
+// locale is C, as gettext does.
+func Preferred() []string {
+	msg := Messages()
+	if len(msg) == 0 {
+		return nil
+	}
+	var list []string
+	for _, l := range strings.Split(os.Getenv("LANGUAGE"), ":") {
+		if len(l) > 0 {
+			list = append(list, l)
+		}
+	}
+	return append(list, msg)
+}
+
+// Match returns the entry of available that best matches the requested
+// locales, or the empty string if nothing matches. Requested locales are
+// tried in order and each is expanded using Candidates.
+func Match(available []string, requested []string) string {
+	for _, req := range requested {
+		for _, c := range Parse(req).Candidates() {
+			for _, a := range available {
+				if a == c {
+					return a
+				}
+			}
+		}
+	}
+	return ""
+}
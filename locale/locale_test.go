@@ -0,0 +1,64 @@
+package locale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	l := Parse("sr_YU.UTF-8@Latn")
+	if l != (Locale{"sr", "YU", "UTF-8", "Latn"}) {
+		t.Errorf("wrong locale %#v", l)
+	}
+	if l.String() != "sr_YU.UTF-8@Latn" {
+		t.Errorf("wrong string %q", l.String())
+	}
+	want := []string{"sr_YU@Latn", "sr_YU", "sr@Latn", "sr"}
+	if !reflect.DeepEqual(l.Candidates(), want) {
+		t.Errorf("got %v, want %v", l.Candidates(), want)
+	}
+	if Parse("de").Candidates()[0] != "de" || Parse("").Candidates() != nil {
+		t.Error("wrong candidates")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	available := []string{"de", "sr@Latn", "sr_YU", "pt_BR"}
+	for _, tt := range []struct {
+		requested []string
+		want      string
+	}{
+		{[]string{"de_DE.UTF-8"}, "de"},
+		{[]string{"sr_YU@Latn"}, "sr_YU"},
+		{[]string{"sr_RS@Latn"}, "sr@Latn"},
+		{[]string{"pt_PT"}, ""},
+		{[]string{"fr", "pt_BR"}, "pt_BR"},
+		{nil, ""},
+	} {
+		if got := Match(available, tt.requested); got != tt.want {
+			t.Errorf("Match(%v) = %q, want %q", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "C.UTF-8")
+	t.Setenv("LANGUAGE", "fr:de")
+	if Preferred() != nil {
+		t.Error("C locale should have no preference")
+	}
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := Preferred(); !reflect.DeepEqual(got, []string{"fr", "de", "en_US.UTF-8"}) {
+		t.Errorf("wrong preference %v", got)
+	}
+	t.Setenv("LC_MESSAGES", "POSIX")
+	if Messages() != "" {
+		t.Error("POSIX locale should be empty")
+	}
+	t.Setenv("LC_ALL", "es_ES")
+	if Messages() != "es_ES" {
+		t.Error("LC_ALL should take precedence")
+	}
+}
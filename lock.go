@@ -0,0 +1,137 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Lock is an advisory lock on a file in the runtime directory. It is
+// released when the process exits.
+type Lock struct {
+	f    *os.File
+	path string
+}
+
+// Path returns the path of the lock file.
+func (l *Lock) Path() string { return l.path }
+
+// Unlock removes the lock file and releases the lock.
+func (l *Lock) Unlock() error {
+	var cerr error
+	if runtime.GOOS == "windows" {
+		// Open files cannot be removed on windows.
+		cerr = l.f.Close()
+	}
+	err := os.Remove(longPath(l.path))
+	audit(OpRemove, l.path, 0, 0, err)
+	if runtime.GOOS != "windows" {
+		cerr = l.f.Close()
+	}
+	return errors.Join(err, cerr)
+}
+
+// Lock takes an exclusive lock on $XDG_RUNTIME_DIR/<name>/<name>.lock. The
+// error wraps ErrLocked if another process holds the lock, which is how a
+// program can tell that another instance is already running.
+func (xdg *XDG) Lock() (*Lock, error) {
+	path, err := xdg.runtimeFile(xdg.appID() + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	return lockPath(path)
+}
+
+// PIDFile is like Lock for the file $XDG_RUNTIME_DIR/<name>/<name>.pid and
+// writes the process id into it. If another instance holds the lock the
+// error wraps ErrLocked and includes its process id.
+func (xdg *XDG) PIDFile() (*Lock, error) {
+	path, err := xdg.runtimeFile(xdg.appID() + ".pid")
+	if err != nil {
+		return nil, err
+	}
+	l, err := lockPath(path)
+	if errors.Is(err, ErrLocked) {
+		if pid, ok := readPID(path); ok {
+			return nil, fmt.Errorf("%w: pid %d", err, pid)
+		}
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	pid := strconv.Itoa(os.Getpid()) + "\n"
+	if err = l.f.Truncate(0); err == nil {
+		_, err = l.f.WriteAt([]byte(pid), 0)
+	}
+	if err == nil {
+		err = l.f.Sync()
+	}
+	audit(OpWrite, l.path, int64(len(pid)), 0600, err)
+	if err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return l, nil
+}
+
+// LockRuntime takes the lock of the application name. See (*XDG).Lock.
+func LockRuntime(name string) (*Lock, error) { return newXdg(name).Lock() }
+
+// PIDFile writes the pid file of the application name. See (*XDG).PIDFile.
+func PIDFile(name string) (*Lock, error) { return newXdg(name).PIDFile() }
+
+// runtimeFile returns the path of file in the application's runtime
+// directory, creating the directory with mode 0700.
+func (xdg *XDG) runtimeFile(file string) (string, error) {
+	dir, err := xdg.RuntimeSubdir()
+	if err != nil {
+		return "", err
+	}
+	return dir.Append(file).String(), nil
+}
+
+// LockFile takes an exclusive advisory lock on path, creating it with mode
+// 0600 if needed. It does not wait: the error wraps ErrLocked if another
+// process holds the lock. Unlock removes the file.
+func LockFile(path string) (*Lock, error) { return lockPath(path) }
+
+func lockPath(path string) (*Lock, error) {
+	for {
+		f, err := os.OpenFile(longPath(path), os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		if err = lockFile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s", err, path)
+		}
+		// The previous holder may have removed the file between the open
+		// and the lock, leaving us with a lock nobody else can see.
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		pi, err := os.Stat(longPath(path))
+		if err == nil && os.SameFile(fi, pi) {
+			return &Lock{f: f, path: path}, nil
+		}
+		f.Close()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+}
+
+func readPID(path string) (int, bool) {
+	b, err := os.ReadFile(longPath(path))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	return pid, err == nil && pid > 0
+}
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package xdg
+
+import "os"
+
+// lockFile is a no-op on platforms without flock, so locks are not
+// exclusive there.
+func lockFile(*os.File) error { return nil }
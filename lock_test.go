@@ -0,0 +1,85 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv(runtimeDirKey, base)
+	x := New("app")
+	l, err := x.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(base, "app", "app.lock"), l.Path())
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(l.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	if _, err = x.Lock(); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if err = l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if exists(l.Path()) {
+		t.Error("lock file should be removed")
+	}
+	l, err = LockRuntime("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Unlock()
+}
+
+func TestPIDFile(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv(runtimeDirKey, base)
+	l, err := PIDFile("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Unlock()
+	b, err := os.ReadFile(l.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, strconv.Itoa(os.Getpid()), strings.TrimSpace(string(b)))
+
+	_, err = PIDFile("app")
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "pid "+strconv.Itoa(os.Getpid())) {
+		t.Errorf("error should name the running pid: %v", err)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.lock")
+	l, err := LockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, path, l.Path())
+	if _, err = LockFile(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if err = l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if l, err = LockFile(path); err != nil {
+		t.Fatal(err)
+	}
+	l.Unlock()
+}
@@ -0,0 +1,38 @@
+package xdg
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32       = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx = kernel32.NewProc("LockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+func lockFile(f *os.File) error {
+	if err := procLockFileEx.Find(); err != nil {
+		return err
+	}
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(
+		f.Fd(),
+		lockfileExclusiveLock|lockfileFailImmediately,
+		0, 1, 0,
+		uintptr(unsafe.Pointer(&ol)),
+	)
+	if r != 0 {
+		return nil
+	}
+	if err == errorLockViolation {
+		return ErrLocked
+	}
+	return err
+}
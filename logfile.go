@@ -0,0 +1,132 @@
+package xdg
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// LogRotation configures size based rotation for LogFile. The zero value
+// disables rotation.
+type LogRotation struct {
+	// MaxBytes is the size at which the log is rotated.
+	MaxBytes int64
+	// Keep is the number of rotated files to retain, named <file>.1 (the most
+	// recent) through <file>.<Keep>. With Keep set to zero the log is
+	// truncated instead.
+	Keep int
+}
+
+// LogFile opens a log file in the state directory of the application name.
+// See (*XDG).LogFile.
+func LogFile(name, file string, rot LogRotation) (io.WriteCloser, error) {
+	return newXdg(name).LogFile(file, rot)
+}
+
+// LogFile opens file in $XDG_STATE_HOME/<name>, or $LOGS_DIRECTORY with
+// WithSystemdDirs, for appending, creating the directories as needed. Writes
+// are rotated according to rot. Missing directories are created with
+// DirMode(KindState). The returned writer is safe for concurrent use.
+func (xdg *XDG) LogFile(file string, rot LogRotation) (io.WriteCloser, error) {
+	dir, ok := xdg.systemdDir(systemdLogsKey)
+	if !ok {
+		var err error
+		if dir, err = xdg.StateE(); err != nil {
+			return nil, err
+		}
+	}
+	path, err := Dir(dir).join(file)
+	if err != nil {
+		return nil, err
+	}
+	if err = mkdirAll(filepath.Dir(path), xdg.DirMode(KindState)); err != nil {
+		return nil, err
+	}
+	l := &logFile{path: path, rot: rot}
+	if err = l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type logFile struct {
+	mu   sync.Mutex
+	path string
+	rot  LogRotation
+	f    *os.File
+	size int64
+}
+
+func (l *logFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return 0, fs.ErrClosed
+	}
+	if l.rot.MaxBytes > 0 && l.size > 0 && l.size+int64(len(p)) > l.rot.MaxBytes {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func (l *logFile) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return fs.ErrClosed
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+func (l *logFile) open() error {
+	f, err := os.OpenFile(longPath(l.path), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		audit(OpWrite, l.path, 0, 0644, err)
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts <file>.n to <file>.n+1, dropping the oldest, and starts a
+// new file.
+func (l *logFile) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+	name := func(n int) string {
+		if n == 0 {
+			return l.path
+		}
+		return l.path + "." + strconv.Itoa(n)
+	}
+	err := os.Remove(longPath(name(l.rot.Keep)))
+	audit(OpRemove, name(l.rot.Keep), 0, 0, err)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for n := l.rot.Keep - 1; n >= 0; n-- {
+		err = os.Rename(longPath(name(n)), longPath(name(n+1)))
+		audit(OpRename, name(n), 0, 0, err)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return l.open()
+}
@@ -0,0 +1,76 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestLogFile(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(stateHomeKey, tmp)
+	path := filepath.Join(tmp, "app", "logs", "app.log")
+
+	w, err := LogFile("app", "logs/app.log", LogRotation{MaxBytes: 10, Keep: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+		if _, err = w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("x")); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	eq(t, "six\n", readLog(t, path))
+	eq(t, "four\nfive\n", readLog(t, path+".1"))
+	eq(t, "three\n", readLog(t, path+".2"))
+	if exists(path + ".3") {
+		t.Error("only two rotated files should be kept")
+	}
+
+	w, err = LogFile("app", "logs/app.log", LogRotation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("seven\n"))
+	w.Close()
+	eq(t, "six\nseven\n", readLog(t, path))
+
+	if _, err = LogFile("app", "../app.log", LogRotation{}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
+
+func TestLogFile_Truncate(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(stateHomeKey, tmp)
+	w, err := LogFile("app", "app.log", LogRotation{MaxBytes: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Write([]byte("abc\n"))
+	w.Write([]byte("def\n"))
+	eq(t, "def\n", readLog(t, filepath.Join(tmp, "app", "app.log")))
+	if exists(filepath.Join(tmp, "app", "app.log.1")) {
+		t.Error("no rotated files should be kept")
+	}
+}
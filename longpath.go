@@ -0,0 +1,16 @@
+package xdg
+
+import "strings"
+
+// extendedPath returns the extended-length (\\?\ prefixed) form of an
+// absolute, cleaned Windows path.
+func extendedPath(p string) string {
+	switch {
+	case strings.HasPrefix(p, `\\?\`), strings.HasPrefix(p, `\\.\`):
+		return p
+	case strings.HasPrefix(p, `\\`):
+		return `\\?\UNC\` + p[2:]
+	default:
+		return `\\?\` + p
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !windows
+
+package xdg
+
+func longPath(p string) string { return p }
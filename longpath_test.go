@@ -0,0 +1,14 @@
+package xdg
+
+import "testing"
+
+func TestExtendedPath(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{`C:\Users\me\AppData\Local\app`, `\\?\C:\Users\me\AppData\Local\app`},
+		{`\\server\share\cache`, `\\?\UNC\server\share\cache`},
+		{`\\?\C:\already`, `\\?\C:\already`},
+		{`\\.\pipe\app`, `\\.\pipe\app`},
+	} {
+		eq(t, tt.want, extendedPath(tt.in))
+	}
+}
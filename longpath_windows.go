@@ -0,0 +1,24 @@
+package xdg
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxPath is the longest directory path the Win32 API accepts without the
+// extended-length prefix (MAX_PATH less room for an 8.3 file name).
+const maxPath = 248
+
+// longPath makes paths beyond MAX_PATH usable with the Win32 API. The os
+// package only does this for absolute paths, but deep cache trees are just
+// as often reached through relative ones.
+func longPath(p string) string {
+	if len(p) < maxPath || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	return extendedPath(abs)
+}
@@ -0,0 +1,64 @@
+package xdg
+
+import (
+	"sync"
+	"time"
+)
+
+// WithLookupCache remembers the results of SearchConfigFile and
+// SearchDataFile, including misses, for ttl so hot paths that keep probing
+// for optional files do not stat the whole search path every time. Use
+// InvalidateLookups after creating or removing files the cache may have
+// seen.
+func WithLookupCache(ttl time.Duration) Option {
+	return func(xdg *XDG) {
+		if ttl > 0 {
+			xdg.lookups = &lookupCache{ttl: ttl, m: make(map[string]lookupResult)}
+		}
+	}
+}
+
+// InvalidateLookups forgets every result remembered by WithLookupCache.
+func (xdg *XDG) InvalidateLookups() {
+	if xdg.lookups != nil {
+		xdg.lookups.clear()
+	}
+}
+
+type lookupCache struct {
+	ttl time.Duration
+	mu  sync.Mutex
+	m   map[string]lookupResult
+}
+
+type lookupResult struct {
+	path    string
+	err     error
+	expires time.Time
+}
+
+// search is the package level search, remembering the result under key.
+func (c *lookupCache) search(key string, dirs func() []string, relPath string) (string, error) {
+	if c == nil {
+		return search(dirs(), relPath)
+	}
+	key += "\x00" + relPath
+	now := time.Now()
+	c.mu.Lock()
+	r, ok := c.m[key]
+	c.mu.Unlock()
+	if ok && now.Before(r.expires) {
+		return r.path, r.err
+	}
+	path, err := search(dirs(), relPath)
+	c.mu.Lock()
+	c.m[key] = lookupResult{path: path, err: err, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return path, err
+}
+
+func (c *lookupCache) clear() {
+	c.mu.Lock()
+	clear(c.m)
+	c.mu.Unlock()
+}
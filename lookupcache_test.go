@@ -0,0 +1,57 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLookupCache(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "share"))
+	x := New("app", WithLookupCache(time.Hour))
+	theme := filepath.Join(x.Config(), "theme.toml")
+
+	if _, err := x.SearchConfigFile("theme.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+	writeTestFile(t, theme)
+	if _, err := x.SearchConfigFile("theme.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the miss to be remembered, got %v", err)
+	}
+	x.InvalidateLookups()
+	path, err := x.SearchConfigFile("theme.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, theme, path)
+
+	// Config and data lookups are remembered separately.
+	system := filepath.Join(tmp, "share", "app", "theme.toml")
+	writeTestFile(t, system)
+	if path, err = x.SearchDataFile("theme.toml"); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, system, path)
+	if err = os.Remove(system); err != nil {
+		t.Fatal(err)
+	}
+	if path, _ = x.SearchDataFile("theme.toml"); path != system {
+		t.Errorf("expected the hit to be remembered, got %q", path)
+	}
+
+	short := New("app", WithLookupCache(time.Millisecond))
+	if _, err = short.SearchDataFile("theme.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+	writeTestFile(t, system)
+	time.Sleep(5 * time.Millisecond)
+	if path, err = short.SearchDataFile("theme.toml"); err != nil || path != system {
+		t.Errorf("expected the entry to expire, got %q, %v", path, err)
+	}
+}
@@ -0,0 +1,85 @@
+package xdg
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ManDir returns the user's man page directory for section. See
+// (*XDG).ManDir.
+func ManDir(section int) (string, error) { return baseXdg().ManDir(section) }
+
+// ManDirs returns every man page directory for section. See (*XDG).ManDirs.
+func ManDirs(section int) ([]string, error) { return baseXdg().ManDirs(section) }
+
+// InstallManPage installs a man page. See (*XDG).InstallManPage.
+func InstallManPage(section int, name string, r io.Reader) (string, error) {
+	return baseXdg().InstallManPage(section, name, r)
+}
+
+// ManDir returns $XDG_DATA_HOME/man/man<section>, where man pages are
+// installed per user. man-db adds it to the search path when ~/.local/bin is
+// in $PATH.
+func (xdg *XDG) ManDir(section int) (string, error) {
+	sub, err := manSection(section)
+	if err != nil {
+		return "", err
+	}
+	base := xdg.baseDir(dataHomeKey)
+	if len(base) == 0 {
+		return "", ErrNoHome
+	}
+	return filepath.Join(base, sub), nil
+}
+
+// ManDirs returns the man/man<section> directories of $XDG_DATA_HOME
+// followed by those of $XDG_DATA_DIRS.
+func (xdg *XDG) ManDirs(section int) ([]string, error) {
+	sub, err := manSection(section)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	if base := xdg.baseDir(dataHomeKey); len(base) > 0 {
+		dirs = append(dirs, filepath.Join(base, sub))
+	}
+	return append(dirs, joinName(xdg.baseDirs(dataDirsKey), sub)...), nil
+}
+
+// InstallManPage writes the man page read from r into the user's man
+// directory for section and returns its path. The section is appended to
+// name unless it already ends in it, optionally followed by a compression
+// suffix like ".gz".
+func (xdg *XDG) InstallManPage(section int, name string, r io.Reader) (string, error) {
+	dir, err := xdg.ManDir(section)
+	if err != nil {
+		return "", err
+	}
+	if len(name) == 0 || !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+		return "", &fs.PathError{Op: "install", Path: name, Err: ErrInvalidPath}
+	}
+	file := name
+	ext := "." + strconv.Itoa(section)
+	if base := strings.TrimSuffix(name, filepath.Ext(name)); !strings.HasSuffix(name, ext) && !strings.HasSuffix(base, ext) {
+		file += ext
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if err = xdg.writeFileAtomicIn(KindData, dir, file, data, 0644); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, file), nil
+}
+
+func manSection(section int) (string, error) {
+	if section < 1 || section > 9 {
+		return "", fmt.Errorf("xdg: invalid man section %d", section)
+	}
+	return filepath.Join("man", "man"+strconv.Itoa(section)), nil
+}
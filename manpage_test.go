@@ -0,0 +1,57 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManDir(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	data := filepath.Join(tmp, "data")
+	t.Setenv(dataHomeKey, data)
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "usr"))
+	dir, err := ManDir(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(data, "man", "man1"), dir)
+	dirs, err := ManDirs(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{filepath.Join(data, "man", "man5"), filepath.Join(tmp, "usr", "man", "man5")}, dirs)
+	for _, s := range []int{0, 10, -1} {
+		if _, err = ManDir(s); err == nil {
+			t.Errorf("ManDir(%d): expected an error", s)
+		}
+	}
+}
+
+func TestInstallManPage(t *testing.T) {
+	unsetAll()
+	data := t.TempDir()
+	t.Setenv(dataHomeKey, data)
+	for name, file := range map[string]string{
+		"mytool":       "mytool.1",
+		"mytool-sub.1": "mytool-sub.1",
+		"other.1.gz":   "other.1.gz",
+	} {
+		path, err := InstallManPage(1, name, strings.NewReader(".TH MYTOOL 1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, filepath.Join(data, "man", "man1", file), path)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, ".TH MYTOOL 1", string(b))
+	}
+	if _, err := InstallManPage(1, "../x", strings.NewReader("")); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ConflictPolicy decides what Migrate does when a file already exists at the
+// destination.
+type ConflictPolicy int
+
+const (
+	// ConflictSkip leaves both files in place.
+	ConflictSkip ConflictPolicy = iota
+	// ConflictOverwrite replaces the destination with the legacy file.
+	ConflictOverwrite
+	// ConflictFail stops the migration with an error.
+	ConflictFail
+)
+
+// MigrateAction describes what happened to a single legacy file.
+type MigrateAction int
+
+const (
+	MigrateMoved MigrateAction = iota
+	MigrateCopied
+	MigrateSkipped
+)
+
+func (a MigrateAction) String() string {
+	switch a {
+	case MigrateMoved:
+		return "moved"
+	case MigrateCopied:
+		return "copied"
+	case MigrateSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("MigrateAction(%d)", int(a))
+	}
+}
+
+// Migration is one entry of the report returned by Migrate.
+type Migration struct {
+	From   string
+	To     string
+	Action MigrateAction
+}
+
+// MigrateOptions configures Migrate.
+type MigrateOptions struct {
+	// Legacy is the directory to migrate. It defaults to ~/.<name>.
+	Legacy string
+	// Target returns the directory a legacy file is moved to, given its
+	// slash separated path relative to Legacy. By default everything goes
+	// to the config directory.
+	Target func(rel string) Dir
+	// Copy leaves the legacy directory in place.
+	Copy bool
+	// DryRun reports what would happen without touching any file.
+	DryRun bool
+	// Conflict is applied when a destination file already exists.
+	Conflict ConflictPolicy
+}
+
+// Migrate moves the contents of a legacy dot directory into the XDG
+// directories. It returns a report of every regular file found. A missing
+// legacy directory is not an error. The legacy directory itself is removed
+// once every file has been moved out of it.
+func (xdg *XDG) Migrate(opts MigrateOptions) ([]Migration, error) {
+	legacy := opts.Legacy
+	if len(legacy) == 0 {
+		home, err := xdg.Home()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNoHome, err)
+		}
+		legacy = filepath.Join(home, "."+xdg.finder.Name())
+	}
+	target := opts.Target
+	if target == nil {
+		config := xdg.ConfigDir()
+		target = func(string) Dir { return config }
+	}
+
+	var (
+		report []Migration
+		dirs   []string
+		keep   bool
+		src    = Dir(legacy).fs()
+	)
+	err := fs.WalkDir(src, ".", func(rel string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if rel == "." && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		from := filepath.Join(legacy, filepath.FromSlash(rel))
+		if d.IsDir() {
+			dirs = append(dirs, from)
+			return nil
+		}
+		dst := target(rel)
+		if len(dst) == 0 {
+			return fmt.Errorf("xdg: no migration target for %s", rel)
+		}
+		m := Migration{From: from, To: filepath.Join(string(dst), filepath.FromSlash(rel)), Action: MigrateMoved}
+		if opts.Copy {
+			m.Action = MigrateCopied
+		}
+		if !d.Type().IsRegular() && (d.Type()&fs.ModeSymlink == 0 || !isOSFileSystem()) {
+			m.Action = MigrateSkipped
+		} else if exists(m.To) {
+			switch opts.Conflict {
+			case ConflictFail:
+				return fmt.Errorf("xdg: cannot migrate %s: %w", m.To, fs.ErrExist)
+			case ConflictSkip:
+				m.Action = MigrateSkipped
+			}
+		}
+		report = append(report, m)
+		if m.Action == MigrateSkipped {
+			keep = true
+			return nil
+		}
+		if opts.DryRun {
+			return nil
+		}
+		return migrateFile(src, rel, d, m, xdg.dirModeFor(string(dst)))
+	})
+	if err != nil || opts.DryRun || opts.Copy || keep {
+		return report, err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err = remove(dirs[i]); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Migrate moves the legacy dot directory of the application name into its
+// XDG directories. See (*XDG).Migrate.
+func Migrate(name string, opts MigrateOptions) ([]Migration, error) {
+	return newXdg(name).Migrate(opts)
+}
+
+// migrateFile copies a regular file or symbolic link keeping its mode and
+// modification time, and removes the original if it is being moved.
+func migrateFile(src fs.FS, rel string, d fs.DirEntry, m Migration, dirPerm fs.FileMode) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	if err = mkdirAll(filepath.Dir(m.To), dirPerm); err != nil {
+		return err
+	}
+	if d.Type()&fs.ModeSymlink != 0 {
+		link, err := os.Readlink(longPath(m.From))
+		if err != nil {
+			return err
+		}
+		err = restoreLink(m.To, link)
+	} else {
+		err = copyRegular(src, rel, m.To, info)
+	}
+	if err != nil {
+		return err
+	}
+	if m.Action == MigrateMoved {
+		return remove(m.From)
+	}
+	return nil
+}
+
+func copyRegular(src fs.FS, rel, to string, info fs.FileInfo) error {
+	data, err := fs.ReadFile(src, rel)
+	if err != nil {
+		return err
+	}
+	if err = writeFile(to, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if isOSFileSystem() {
+		if err = chmod(to, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return setModTime(to, info.ModTime())
+}
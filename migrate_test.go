@@ -0,0 +1,158 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func legacyTree(t *testing.T) (home string) {
+	t.Helper()
+	home = t.TempDir()
+	writeTestFile(t, filepath.Join(home, ".app", "config.toml"))
+	writeTestFile(t, filepath.Join(home, ".app", "db", "items.db"))
+	return home
+}
+
+func TestMigrate(t *testing.T) {
+	unsetAll()
+	home := legacyTree(t)
+	x := New("app", WithHomeDir(home))
+	report, err := x.Migrate(MigrateOptions{
+		Target: func(rel string) Dir {
+			if strings.HasPrefix(rel, "db/") {
+				return x.DataDir()
+			}
+			return x.ConfigDir()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(report))
+	eq(t, filepath.Join(home, ".config/app/config.toml"), report[0].To)
+	eq(t, filepath.Join(home, ".local/share/app/db/items.db"), report[1].To)
+	for _, m := range report {
+		eq(t, MigrateMoved, m.Action)
+		if !exists(m.To) {
+			t.Errorf("%s was not created", m.To)
+		}
+	}
+	if exists(filepath.Join(home, ".app")) {
+		t.Error("legacy directory should be removed")
+	}
+
+	report, err = x.Migrate(MigrateOptions{})
+	if err != nil || len(report) != 0 {
+		t.Errorf("expected nothing to migrate, got %v, %v", report, err)
+	}
+}
+
+func TestMigrate_DryRunAndCopy(t *testing.T) {
+	unsetAll()
+	home := legacyTree(t)
+	x := New("app", WithHomeDir(home))
+	report, err := x.Migrate(MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(report))
+	if x.ConfigDir().Exists() {
+		t.Error("dry run should not create anything")
+	}
+
+	report, err = x.Migrate(MigrateOptions{Copy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, MigrateCopied, report[0].Action)
+	eq(t, "copied", report[0].Action.String())
+	if !exists(report[0].From) || !exists(report[0].To) {
+		t.Error("copy should keep both files")
+	}
+}
+
+func TestMigrate_Conflict(t *testing.T) {
+	unsetAll()
+	home := legacyTree(t)
+	x := New("app", WithHomeDir(home))
+	dst := filepath.Join(home, ".config/app/config.toml")
+	writeTestFile(t, dst, "new")
+
+	_, err := x.Migrate(MigrateOptions{Conflict: ConflictFail, DryRun: true})
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("expected ErrExist, got %v", err)
+	}
+
+	report, err := x.Migrate(MigrateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, MigrateSkipped, report[0].Action)
+	eq(t, MigrateMoved, report[1].Action)
+	if !exists(filepath.Join(home, ".app", "config.toml")) {
+		t.Error("skipped file should stay in the legacy directory")
+	}
+
+	if _, err = x.Migrate(MigrateOptions{Conflict: ConflictOverwrite}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) == "new" {
+		t.Error("file should be overwritten")
+	}
+	if exists(filepath.Join(home, ".app")) {
+		t.Error("legacy directory should be removed")
+	}
+}
+
+func TestMigrate_Preserve(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits and symbolic links differ on windows")
+	}
+	unsetAll()
+	home := legacyTree(t)
+	legacy := filepath.Join(home, ".app")
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	conf := filepath.Join(legacy, "config.toml")
+	if err := os.Chmod(conf, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(conf, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("config.toml", filepath.Join(legacy, "current")); err != nil {
+		t.Fatal(err)
+	}
+	x := New("app", WithHomeDir(home))
+	report, err := x.Migrate(MigrateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 3, len(report))
+	for _, m := range report {
+		eq(t, MigrateMoved, m.Action)
+	}
+	info, err := os.Stat(filepath.Join(x.Config(), "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, fs.FileMode(0600), info.Mode().Perm())
+	eq(t, true, info.ModTime().Equal(mtime))
+	link, err := os.Readlink(filepath.Join(x.Config(), "current"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "config.toml", link)
+	if exists(legacy) {
+		t.Error("legacy directory should be removed")
+	}
+}
@@ -0,0 +1,221 @@
+package mime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+var errMagic = errors.New("mime: invalid magic file")
+
+const magicHeader = "MIME-Magic\x00\n"
+
+type magicSection struct {
+	priority int
+	typ      string
+	rules    []*magicRule
+}
+
+type magicRule struct {
+	indent   int
+	offset   int
+	value    []byte
+	mask     []byte
+	wordSize int
+	rng      int
+	children []*magicRule
+}
+
+// parseMagic reads the binary magic file format:
+//
+//	[priority:type]
+//	[indent]>offset=<2 byte length>value[&mask][~word-size][+range]
+func parseMagic(data []byte) ([]magicSection, error) {
+	if !bytes.HasPrefix(data, []byte(magicHeader)) {
+		return nil, errMagic
+	}
+	data = data[len(magicHeader):]
+	var (
+		sections []magicSection
+		cur      *magicSection
+		stack    []*magicRule
+	)
+	for len(data) > 0 {
+		if data[0] == '[' {
+			end := bytes.IndexByte(data, '\n')
+			if end < 0 || data[end-1] != ']' {
+				return nil, errMagic
+			}
+			prio, typ, ok := bytes.Cut(data[1:end-1], []byte(":"))
+			if !ok {
+				return nil, errMagic
+			}
+			p, err := strconv.Atoi(string(prio))
+			if err != nil {
+				return nil, errMagic
+			}
+			sections = append(sections, magicSection{priority: p, typ: string(typ)})
+			cur, stack = &sections[len(sections)-1], stack[:0]
+			data = data[end+1:]
+			continue
+		}
+		if cur == nil {
+			return nil, errMagic
+		}
+		r := &magicRule{wordSize: 1, rng: 1}
+		var ok bool
+		if r.indent, data, ok = number(data); !ok {
+			r.indent = 0
+		}
+		if len(data) == 0 || data[0] != '>' {
+			return nil, errMagic
+		}
+		if r.offset, data, ok = number(data[1:]); !ok || len(data) < 3 || data[0] != '=' {
+			return nil, errMagic
+		}
+		n := int(binary.BigEndian.Uint16(data[1:3]))
+		data = data[3:]
+		if len(data) < n {
+			return nil, errMagic
+		}
+		r.value, data = data[:n], data[n:]
+		if len(data) > 0 && data[0] == '&' {
+			if len(data) < n+1 {
+				return nil, errMagic
+			}
+			r.mask, data = data[1:n+1], data[n+1:]
+		}
+		if len(data) > 0 && data[0] == '~' {
+			if r.wordSize, data, ok = number(data[1:]); !ok {
+				return nil, errMagic
+			}
+		}
+		if len(data) > 0 && data[0] == '+' {
+			if r.rng, data, ok = number(data[1:]); !ok {
+				return nil, errMagic
+			}
+		}
+		// Unknown trailing fields are ignored up to the end of the line.
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return nil, errMagic
+		}
+		data = data[end+1:]
+		r.swap()
+
+		for len(stack) > r.indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			cur.rules = append(cur.rules, r)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, r)
+		}
+		stack = append(stack, r)
+	}
+	return sections, nil
+}
+
+func number(b []byte) (int, []byte, bool) {
+	i := 0
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, b, false
+	}
+	n, err := strconv.Atoi(string(b[:i]))
+	return n, b[i:], err == nil
+}
+
+var littleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// swap reverses each word of the value and mask on little endian hosts, as
+// the spec requires for word sizes above one.
+func (r *magicRule) swap() {
+	if !littleEndian || r.wordSize <= 1 || len(r.value)%r.wordSize != 0 {
+		return
+	}
+	rev := func(b []byte) []byte {
+		out := make([]byte, len(b))
+		for i := 0; i < len(b); i += r.wordSize {
+			for j := 0; j < r.wordSize; j++ {
+				out[i+j] = b[i+r.wordSize-1-j]
+			}
+		}
+		return out
+	}
+	r.value = rev(r.value)
+	if r.mask != nil {
+		r.mask = rev(r.mask)
+	}
+}
+
+func (s *magicSection) match(data []byte) bool {
+	for _, r := range s.rules {
+		if r.match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *magicSection) extent() int {
+	n := 0
+	var walk func(rules []*magicRule)
+	walk = func(rules []*magicRule) {
+		for _, r := range rules {
+			if e := r.offset + r.rng + len(r.value); e > n {
+				n = e
+			}
+			walk(r.children)
+		}
+	}
+	walk(s.rules)
+	return n
+}
+
+// match reports whether the rule matches at any offset in its range and,
+// if it has nested rules, whether one of them matches too.
+func (r *magicRule) match(data []byte) bool {
+	if !r.matchValue(data) {
+		return false
+	}
+	if len(r.children) == 0 {
+		return true
+	}
+	for _, c := range r.children {
+		if c.match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *magicRule) matchValue(data []byte) bool {
+	for off := r.offset; off < r.offset+r.rng; off++ {
+		if off+len(r.value) > len(data) {
+			return false
+		}
+		window := data[off : off+len(r.value)]
+		if r.mask == nil {
+			if bytes.Equal(window, r.value) {
+				return true
+			}
+			continue
+		}
+		ok := true
+		for i := range window {
+			if window[i]&r.mask[i] != r.value[i]&r.mask[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,349 @@
+// Package mime detects MIME types using the shared-mime-info database
+// installed in the XDG data directories, so detection follows the types
+// known to the rest of the desktop.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/shared-mime-info-spec/latest/
+package mime
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// Types returned when the database has no better answer.
+const (
+	OctetStream = "application/octet-stream"
+	PlainText   = "text/plain"
+	Directory   = "inode/directory"
+	ZeroSize    = "application/x-zerosize"
+)
+
+// Database is a loaded shared-mime-info database.
+type Database struct {
+	globs      []glob
+	aliases    map[string]string
+	subclasses map[string][]string
+	magic      []magicSection
+}
+
+type glob struct {
+	weight    int
+	typ       string
+	pattern   string
+	sensitive bool
+}
+
+// Dirs returns the mime directories of the XDG data search path, from
+// highest to lowest precedence.
+func Dirs() []string {
+	var dirs []string
+	for d := range xdg.SearchPathData("mime") {
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+var (
+	defaultOnce sync.Once
+	defaultDB   *Database
+	defaultErr  error
+)
+
+// Default returns the database loaded from Dirs. It is loaded on first use.
+func Default() (*Database, error) {
+	defaultOnce.Do(func() { defaultDB, defaultErr = Load(Dirs()...) })
+	return defaultDB, defaultErr
+}
+
+// TypeOf detects the type of the file at path with the default database.
+// See (*Database).TypeOf.
+func TypeOf(path string) (string, error) {
+	db, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return db.TypeOf(path)
+}
+
+// TypeOfReader detects the type of the data in r with the default database.
+// See (*Database).TypeOfReader.
+func TypeOfReader(r io.Reader) (string, error) {
+	db, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return db.TypeOfReader(r)
+}
+
+// Load reads the database files (globs2, aliases, subclasses, and magic)
+// from dirs, given from highest to lowest precedence. Missing files are
+// skipped. It is an error if no directory has a database.
+func Load(dirs ...string) (*Database, error) {
+	db := &Database{aliases: make(map[string]string), subclasses: make(map[string][]string)}
+	found := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		d := xdg.Dir(dirs[i])
+		if data, err := d.ReadFile("globs2"); err == nil {
+			db.parseGlobs(data)
+			found = true
+		}
+		if data, err := d.ReadFile("aliases"); err == nil {
+			eachPair(data, func(a, b string) { db.aliases[a] = b })
+		}
+		if data, err := d.ReadFile("subclasses"); err == nil {
+			eachPair(data, func(a, b string) { db.subclasses[a] = append(db.subclasses[a], b) })
+		}
+		if data, err := d.ReadFile("magic"); err == nil {
+			sections, err := parseMagic(data)
+			if err != nil {
+				return nil, err
+			}
+			db.magic = append(db.magic, sections...)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "load", Path: "mime/globs2", Err: fs.ErrNotExist}
+	}
+	sort.SliceStable(db.magic, func(i, j int) bool { return db.magic[i].priority > db.magic[j].priority })
+	return db, nil
+}
+
+func (db *Database) parseGlobs(data []byte) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		fields := strings.SplitN(line, ":", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		weight, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		g := glob{weight: weight, typ: fields[1], pattern: fields[2]}
+		if g.pattern == "__NOGLOBS__" {
+			// Globs from lower precedence directories are discarded.
+			kept := db.globs[:0]
+			for _, old := range db.globs {
+				if old.typ != g.typ {
+					kept = append(kept, old)
+				}
+			}
+			db.globs = kept
+			continue
+		}
+		if len(fields) == 4 {
+			for _, flag := range strings.Split(fields[3], ",") {
+				g.sensitive = g.sensitive || flag == "cs"
+			}
+		}
+		db.globs = append(db.globs, g)
+	}
+}
+
+func eachPair(data []byte, fn func(a, b string)) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if f := strings.Fields(sc.Text()); len(f) == 2 && f[0][0] != '#' {
+			fn(f[0], f[1])
+		}
+	}
+}
+
+// Canonical resolves an alias to its canonical type.
+func (db *Database) Canonical(typ string) string {
+	if c, ok := db.aliases[typ]; ok {
+		return c
+	}
+	return typ
+}
+
+// Parents returns the types typ is a direct subclass of.
+func (db *Database) Parents(typ string) []string {
+	return db.subclasses[db.Canonical(typ)]
+}
+
+// IsA reports whether typ is parent or a subclass of it. Every text/* type
+// is a subclass of text/plain and every type other than inode/* is a
+// subclass of application/octet-stream.
+func (db *Database) IsA(typ, parent string) bool {
+	typ, parent = db.Canonical(typ), db.Canonical(parent)
+	seen := make(map[string]bool)
+	var walk func(t string) bool
+	walk = func(t string) bool {
+		if t == parent {
+			return true
+		}
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for _, p := range db.subclasses[t] {
+			if walk(db.Canonical(p)) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case walk(typ):
+		return true
+	case parent == PlainText:
+		return strings.HasPrefix(typ, "text/")
+	case parent == OctetStream:
+		return !strings.HasPrefix(typ, "inode/")
+	}
+	return false
+}
+
+// TypeByName returns the type matching the file name with the glob
+// patterns, or an empty string. Literal names win over patterns, then the
+// highest weight, then the longest pattern. More than one candidate of
+// equal standing is returned in the second result.
+func (db *Database) TypeByName(name string) (string, []string) {
+	name = path.Base(filepath.ToSlash(name))
+	lower := strings.ToLower(name)
+	var (
+		best  *glob
+		ties  []string
+		score = func(g *glob) (bool, int, int) {
+			return !strings.ContainsAny(g.pattern, "*?["), g.weight, len(g.pattern)
+		}
+	)
+	for i := range db.globs {
+		g := &db.globs[i]
+		subject := name
+		if !g.sensitive {
+			subject = lower
+		}
+		pattern := g.pattern
+		if !g.sensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		if ok, _ := path.Match(pattern, subject); !ok {
+			continue
+		}
+		if best == nil {
+			best, ties = g, []string{g.typ}
+			continue
+		}
+		bl, bw, bn := score(best)
+		gl, gw, gn := score(g)
+		switch {
+		case gl && !bl, gl == bl && gw > bw, gl == bl && gw == bw && gn > bn:
+			best, ties = g, []string{g.typ}
+		case gl == bl && gw == bw && gn == bn && g.typ != best.typ:
+			ties = append(ties, g.typ)
+		}
+	}
+	if best == nil {
+		return "", nil
+	}
+	return db.Canonical(best.typ), ties
+}
+
+// TypeOf returns the type of the file at path. The name is checked against
+// the glob patterns first and the contents are sniffed with the magic rules
+// when the name does not match or matches several types.
+func (db *Database) TypeOf(name string) (string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return Directory, nil
+	}
+	typ, ties := db.TypeByName(name)
+	if len(typ) > 0 && len(ties) < 2 {
+		return typ, nil
+	}
+	if info.Size() == 0 {
+		if len(typ) > 0 {
+			return typ, nil
+		}
+		return ZeroSize, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sniffed, err := db.TypeOfReader(f)
+	if err != nil {
+		return "", err
+	}
+	// The contents decide between the candidate names.
+	for _, t := range ties {
+		if db.Canonical(t) == sniffed {
+			return sniffed, nil
+		}
+	}
+	for _, t := range ties {
+		if db.IsA(sniffed, t) {
+			return db.Canonical(t), nil
+		}
+	}
+	if len(typ) > 0 {
+		return typ, nil
+	}
+	return sniffed, nil
+}
+
+// TypeOfReader sniffs the type of the data in r with the magic rules. Data
+// no rule matches is text/plain if it looks like UTF-8 text and
+// application/octet-stream otherwise.
+func (db *Database) TypeOfReader(r io.Reader) (string, error) {
+	n := 256
+	for _, s := range db.magic {
+		if e := s.extent(); e > n {
+			n = e
+		}
+	}
+	buf := make([]byte, n)
+	m, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	buf = buf[:m]
+	for _, s := range db.magic {
+		if s.match(buf) {
+			return db.Canonical(s.typ), nil
+		}
+	}
+	if looksLikeText(buf) {
+		return PlainText, nil
+	}
+	return OctetStream, nil
+}
+
+func looksLikeText(b []byte) bool {
+	if len(b) > 128 {
+		b = b[:128]
+	}
+	if bytes.IndexByte(b, 0) >= 0 {
+		return false
+	}
+	// The sample may end in the middle of a rune.
+	for i := 0; i < 3 && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	return utf8.Valid(b)
+}
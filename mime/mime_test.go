@@ -0,0 +1,203 @@
+package mime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testGlobs = `# comment
+50:image/png:*.png
+50:image/jpeg:*.jpg
+50:image/jpeg:*.jpeg
+50:text/x-c:*.h
+50:text/x-c++:*.h
+60:text/x-makefile:makefile
+10:text/x-makefile:*.mk
+50:application/x-old:*.old
+50:text/x-Readme:README:cs
+40:text/plain:*.txt
+`
+
+// rule encodes one rule in the binary magic format.
+func rule(indent, offset int, value, mask string, rng int) []byte {
+	var b bytes.Buffer
+	if indent > 0 {
+		b.WriteString(strconv.Itoa(indent))
+	}
+	b.WriteString(">" + strconv.Itoa(offset) + "=")
+	binary.Write(&b, binary.BigEndian, uint16(len(value)))
+	b.WriteString(value)
+	if len(mask) > 0 {
+		b.WriteString("&" + mask)
+	}
+	if rng > 1 {
+		b.WriteString("+" + strconv.Itoa(rng))
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func testMagic() []byte {
+	var b bytes.Buffer
+	b.WriteString(magicHeader)
+	b.WriteString("[50:image/png]\n")
+	b.Write(rule(0, 0, "\x89PNG", "", 0))
+	b.WriteString("[80:text/x-c++]\n")
+	b.Write(rule(0, 0, "#include", "", 0))
+	b.Write(rule(1, 0, "class", "", 64))
+	b.WriteString("[40:text/x-c]\n")
+	b.Write(rule(0, 0, "#include", "", 0))
+	b.WriteString("[50:application/x-masked]\n")
+	b.Write(rule(0, 2, "AB", "\xff\xdf", 0))
+	return b.Bytes()
+}
+
+func writeDB(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testDB(t *testing.T) *Database {
+	t.Helper()
+	user, system := t.TempDir(), t.TempDir()
+	writeDB(t, system, map[string][]byte{
+		"globs2":     []byte(testGlobs),
+		"magic":      testMagic(),
+		"aliases":    []byte("image/jpg image/jpeg\napplication/x-old application/x-new\n"),
+		"subclasses": []byte("text/x-c++ text/x-c\ntext/x-c text/plain\n"),
+	})
+	writeDB(t, user, map[string][]byte{
+		"globs2": []byte("60:text/x-markdown:*.md\n0:text/x-makefile:__NOGLOBS__\n40:text/x-makefile:GNUmakefile\n"),
+	})
+	db, err := Load(user, system)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestTypeByName(t *testing.T) {
+	db := testDB(t)
+	for _, tt := range []struct{ name, want string }{
+		{"photo.PNG", "image/png"},
+		{"/tmp/a.jpeg", "image/jpeg"},
+		{"notes.md", "text/x-markdown"},
+		{"GNUmakefile", "text/x-makefile"},
+		{"makefile", ""},
+		{"x.mk", ""},
+		{"a.old", "application/x-new"},
+		{"README", "text/x-Readme"},
+		{"readme", ""},
+		{"unknown", ""},
+	} {
+		if got, _ := db.TypeByName(tt.name); got != tt.want {
+			t.Errorf("TypeByName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, ties := db.TypeByName("x.h"); len(ties) != 2 {
+		t.Errorf("expected two candidates, got %q", ties)
+	}
+}
+
+func TestTypeOfReader(t *testing.T) {
+	db := testDB(t)
+	for _, tt := range []struct{ data, want string }{
+		{"\x89PNG\r\n", "image/png"},
+		{"#include <x>\nclass A {};", "text/x-c++"},
+		{"#include <x>\nint main() {}", "text/x-c"},
+		{"..Ab", "application/x-masked"},
+		{"..AB", "application/x-masked"},
+		{"..ab", PlainText},
+		{"plain words", PlainText},
+		{"\x00\x01\x02", OctetStream},
+		{"", PlainText},
+	} {
+		got, err := db.TypeOfReader(strings.NewReader(tt.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TypeOfReader(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	db := testDB(t)
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for _, tt := range []struct{ path, want string }{
+		{write("a.png", "not really"), "image/png"},
+		{write("a.h", "#include <x>\nclass A;"), "text/x-c++"},
+		{write("b.h", "#include <x>\nint a;"), "text/x-c"},
+		{write("c.h", "plain"), "text/x-c"},
+		{write("blob", "\x89PNG...."), "image/png"},
+		{write("empty", ""), ZeroSize},
+		{dir, Directory},
+	} {
+		got, err := db.TypeOf(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TypeOf(%q) = %q, want %q", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+	if _, err := db.TypeOf(filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestIsA(t *testing.T) {
+	db := testDB(t)
+	for _, tt := range []struct {
+		typ, parent string
+		want        bool
+	}{
+		{"text/x-c++", "text/x-c", true},
+		{"text/x-c++", "text/plain", true},
+		{"text/x-markdown", "text/plain", true},
+		{"image/png", OctetStream, true},
+		{"image/jpg", "image/jpeg", true},
+		{Directory, OctetStream, false},
+		{"text/x-c", "text/x-c++", false},
+	} {
+		if got := db.IsA(tt.typ, tt.parent); got != tt.want {
+			t.Errorf("IsA(%q, %q) = %v", tt.typ, tt.parent, got)
+		}
+	}
+	if p := db.Parents("text/x-c++"); len(p) != 1 || p[0] != "text/x-c" {
+		t.Errorf("wrong parents %q", p)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load(t.TempDir()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+	dir := t.TempDir()
+	writeDB(t, dir, map[string][]byte{"globs2": nil, "magic": []byte("bad")})
+	if _, err := Load(dir); err == nil {
+		t.Error("expected magic error")
+	}
+}
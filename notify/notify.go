@@ -0,0 +1,133 @@
+// Package notify sends desktop notifications using the freedesktop.org
+// Desktop Notifications Specification, or the notification portal when
+// running inside a sandbox.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/notification-spec/latest/
+//	https://flatpak.github.io/xdg-desktop-portal/docs/doc-org.freedesktop.portal.Notification.html
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/harrybrwn/xdg/internal/gdbus"
+)
+
+// Urgency is the notification urgency level. The zero value is Normal.
+type Urgency byte
+
+const (
+	Normal Urgency = iota
+	Low
+	Critical
+)
+
+// Notification is a single desktop notification.
+type Notification struct {
+	Title string
+	Body  string
+	// Icon is either an icon theme name or an absolute path to an image.
+	Icon    string
+	Urgency Urgency
+	// Timeout is how long the notification is shown. Zero lets the
+	// notification server decide.
+	Timeout time.Duration
+}
+
+var (
+	call      = gdbus.Call
+	sandboxed = gdbus.Sandboxed
+	now       = time.Now
+)
+
+// Send shows a notification on behalf of app. When no notification service
+// can be reached Send does nothing and returns nil.
+func Send(app string, n Notification) error {
+	return SendContext(context.Background(), app, n)
+}
+
+// SendContext is Send with a context.
+func SendContext(ctx context.Context, app string, n Notification) error {
+	var err error
+	if sandboxed() {
+		err = sendPortal(ctx, app, n)
+	} else {
+		err = sendNotifications(ctx, app, n)
+	}
+	if errors.Is(err, gdbus.ErrUnavailable) || gdbus.IsServiceUnknown(err) {
+		return nil
+	}
+	return err
+}
+
+func sendNotifications(ctx context.Context, app string, n Notification) error {
+	timeout := int32(-1)
+	if n.Timeout > 0 {
+		timeout = int32(n.Timeout / time.Millisecond)
+	}
+	_, err := call(
+		ctx,
+		"org.freedesktop.Notifications",
+		"/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications.Notify",
+		gdbus.String(app),
+		"uint32 0",
+		gdbus.String(n.Icon),
+		gdbus.String(n.Title),
+		gdbus.String(n.Body),
+		gdbus.Strings(nil),
+		gdbus.Dict("urgency", gdbus.Variant(fmt.Sprintf("byte %d", n.Urgency.level()))),
+		fmt.Sprintf("int32 %d", timeout),
+	)
+	return err
+}
+
+func sendPortal(ctx context.Context, app string, n Notification) error {
+	pairs := []string{
+		"title", gdbus.Variant(gdbus.String(n.Title)),
+		"body", gdbus.Variant(gdbus.String(n.Body)),
+		"priority", gdbus.Variant(gdbus.String(n.Urgency.priority())),
+	}
+	if len(n.Icon) > 0 && !filepath.IsAbs(n.Icon) {
+		pairs = append(pairs, "icon", gdbus.Variant(
+			fmt.Sprintf("(%s, %s)", gdbus.String("themed"), gdbus.Variant(gdbus.Strings([]string{n.Icon}))),
+		))
+	}
+	_, err := call(
+		ctx,
+		"org.freedesktop.portal.Desktop",
+		"/org/freedesktop/portal/desktop",
+		"org.freedesktop.portal.Notification.AddNotification",
+		gdbus.String(fmt.Sprintf("%s-%d", app, now().UnixNano())),
+		gdbus.Dict(pairs...),
+	)
+	return err
+}
+
+// level returns the urgency byte defined by the notification spec.
+func (u Urgency) level() byte {
+	switch u {
+	case Low:
+		return 0
+	case Critical:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func (u Urgency) priority() string {
+	switch u {
+	case Low:
+		return "low"
+	case Critical:
+		return "urgent"
+	default:
+		return "normal"
+	}
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harrybrwn/xdg/internal/gdbus"
+)
+
+type fakeCall struct {
+	method string
+	args   []string
+	err    error
+}
+
+func (f *fakeCall) call(_ context.Context, _, _, method string, args ...string) (string, error) {
+	f.method = method
+	f.args = args
+	return "", f.err
+}
+
+func setup(t *testing.T, sandbox bool, err error) *fakeCall {
+	t.Helper()
+	f := &fakeCall{err: err}
+	oldCall, oldSandboxed, oldNow := call, sandboxed, now
+	call = f.call
+	sandboxed = func() bool { return sandbox }
+	now = func() time.Time { return time.Unix(0, 42) }
+	t.Cleanup(func() { call, sandboxed, now = oldCall, oldSandboxed, oldNow })
+	return f
+}
+
+func TestSend(t *testing.T) {
+	f := setup(t, false, nil)
+	err := Send("myapp", Notification{
+		Title:   "Done",
+		Body:    "it's finished",
+		Icon:    "dialog-information",
+		Urgency: Critical,
+		Timeout: 3 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.method != "org.freedesktop.Notifications.Notify" {
+		t.Errorf("wrong method %q", f.method)
+	}
+	want := []string{
+		"'myapp'", "uint32 0", "'dialog-information'", "'Done'",
+		`'it\'s finished'`, "@as []", "{'urgency': <byte 2>}", "int32 3000",
+	}
+	if strings.Join(f.args, "|") != strings.Join(want, "|") {
+		t.Errorf("wrong args:\n got %q\nwant %q", f.args, want)
+	}
+}
+
+func TestSend_DefaultUrgency(t *testing.T) {
+	f := setup(t, false, nil)
+	if err := Send("myapp", Notification{Title: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.args[6] != "{'urgency': <byte 1>}" {
+		t.Errorf("zero urgency should be normal, got %s", f.args[6])
+	}
+}
+
+func TestSend_Portal(t *testing.T) {
+	f := setup(t, true, nil)
+	if err := Send("myapp", Notification{Title: "hi", Icon: "mail"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.method != "org.freedesktop.portal.Notification.AddNotification" {
+		t.Errorf("wrong method %q", f.method)
+	}
+	if f.args[0] != "'myapp-42'" {
+		t.Errorf("wrong id %q", f.args[0])
+	}
+	want := "{'title': <'hi'>, 'body': <''>, 'priority': <'normal'>, 'icon': <('themed', <['mail']>)>}"
+	if f.args[1] != want {
+		t.Errorf("wrong notification:\n got %s\nwant %s", f.args[1], want)
+	}
+}
+
+func TestSend_Unavailable(t *testing.T) {
+	setup(t, false, gdbus.ErrUnavailable)
+	if err := Send("myapp", Notification{}); err != nil {
+		t.Errorf("expected no-op, got %v", err)
+	}
+	setup(t, false, &gdbus.Error{Output: "GDBus.Error:org.freedesktop.DBus.Error.ServiceUnknown: nope"})
+	if err := Send("myapp", Notification{}); err != nil {
+		t.Errorf("expected no-op, got %v", err)
+	}
+	e := errors.New("boom")
+	setup(t, false, &gdbus.Error{Output: "boom", Err: e})
+	if err := Send("myapp", Notification{}); !errors.Is(err, e) {
+		t.Errorf("expected error, got %v", err)
+	}
+}
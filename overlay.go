@@ -0,0 +1,104 @@
+package xdg
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path/filepath"
+)
+
+// OverlayFS is a writable view of a search path. Reads fall through from
+// the home directory to the system directories like ConfigFS, while every
+// write lands in the home directory, which is how the spec expects
+// applications to treat the user's copy of a file as an override.
+type OverlayFS interface {
+	fs.FS
+	// Create returns a writer for the user's copy of name, shadowing any
+	// system wide copy. The file is written atomically with mode 0644 when
+	// the writer is closed, creating missing directories.
+	Create(name string) (io.WriteCloser, error)
+	// Remove deletes the user's copy of name, so that a copy further down
+	// the search path, if there is one, shows through again.
+	Remove(name string) error
+}
+
+// ConfigOverlay returns a writable view of the application's config
+// directories. See (*XDG).ConfigOverlay.
+func ConfigOverlay(name string) OverlayFS { return newXdg(name).ConfigOverlay() }
+
+// DataOverlay returns a writable view of the application's data
+// directories. See (*XDG).DataOverlay.
+func DataOverlay(name string) OverlayFS { return newXdg(name).DataOverlay() }
+
+// ConfigOverlay returns an OverlayFS that reads from $XDG_CONFIG_HOME/<name>
+// followed by $XDG_CONFIG_DIRS and writes to $XDG_CONFIG_HOME/<name>.
+func (xdg *XDG) ConfigOverlay() OverlayFS {
+	home, err := xdg.ConfigE()
+	return &overlayFS{unionFS: newUnionFS(xdg.configSearchPath()), home: home, homeErr: err, dirMode: xdg.DirMode(KindConfig)}
+}
+
+// DataOverlay returns an OverlayFS that reads from $XDG_DATA_HOME/<name>
+// followed by $XDG_DATA_DIRS and writes to $XDG_DATA_HOME/<name>.
+func (xdg *XDG) DataOverlay() OverlayFS {
+	home, err := xdg.DataE()
+	return &overlayFS{unionFS: newUnionFS(xdg.dataSearchPath()), home: home, homeErr: err, dirMode: xdg.DirMode(KindData)}
+}
+
+type overlayFS struct {
+	*unionFS
+	home    string
+	homeErr error
+	// dirMode is what missing directories in home are created with.
+	dirMode fs.FileMode
+}
+
+func (o *overlayFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if o.homeErr != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: o.homeErr}
+	}
+	return filepath.Join(o.home, filepath.FromSlash(name)), nil
+}
+
+func (o *overlayFS) Create(name string) (io.WriteCloser, error) {
+	path, err := o.path("create", name)
+	if err != nil {
+		return nil, err
+	}
+	return &overlayFile{path: path, dirMode: o.dirMode}, nil
+}
+
+func (o *overlayFS) Remove(name string) error {
+	path, err := o.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return remove(path)
+}
+
+// overlayFile buffers the contents of a file until it is closed.
+type overlayFile struct {
+	bytes.Buffer
+	path    string
+	dirMode fs.FileMode
+	closed  bool
+}
+
+func (f *overlayFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	return f.Buffer.Write(p)
+}
+
+func (f *overlayFile) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.closed = true
+	err := writeFileAtomic(f.path, f.Bytes(), 0644, f.dirMode)
+	audit(OpWrite, f.path, int64(f.Len()), 0644, err)
+	return err
+}
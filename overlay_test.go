@@ -0,0 +1,81 @@
+package xdg
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigOverlay(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	system := filepath.Join(tmp, "etc", "app", "themes", "dark.toml")
+	writeTestFile(t, system, "system")
+	o := ConfigOverlay("app")
+
+	b, err := fs.ReadFile(o, "themes/dark.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "system", string(b))
+
+	w, err := o.Create("themes/dark.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.WriteString(w, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = fs.ReadFile(o, "themes/dark.toml"); string(b) != "system" {
+		t.Error("writes should not be visible before Close")
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("x")); err == nil {
+		t.Error("expected an error writing after Close")
+	}
+	if b, _ = fs.ReadFile(o, "themes/dark.toml"); string(b) != "user" {
+		t.Errorf("expected the user's copy, got %q", b)
+	}
+	user := filepath.Join(Config("app"), "themes", "dark.toml")
+	if b, _ = os.ReadFile(user); string(b) != "user" {
+		t.Errorf("write did not land in the config home: %q", b)
+	}
+	if b, _ = os.ReadFile(system); string(b) != "system" {
+		t.Errorf("system copy was modified: %q", b)
+	}
+
+	if err = o.Remove("themes/dark.toml"); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = fs.ReadFile(o, "themes/dark.toml"); string(b) != "system" {
+		t.Errorf("expected the system copy to show through, got %q", b)
+	}
+	if err = o.Remove("themes/dark.toml"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	if _, err = o.Create("../escape"); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+
+	t.Setenv("HOME", "")
+	t.Setenv(dataDirsKey, filepath.Join(tmp, "share"))
+	writeTestFile(t, filepath.Join(tmp, "share", "app", "a"), "a")
+	d := New("app", WithEnviron(func(key string) (string, bool) {
+		if key == dataDirsKey {
+			return filepath.Join(tmp, "share"), true
+		}
+		return "", false
+	})).DataOverlay()
+	if b, err = fs.ReadFile(d, "a"); err != nil || string(b) != "a" {
+		t.Errorf("expected to read the system copy, got %q, %v", b, err)
+	}
+	if _, err = d.Create("a"); err == nil {
+		t.Error("expected an error without a home directory")
+	}
+}
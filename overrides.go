@@ -0,0 +1,225 @@
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// overridesFile is the user override file, relative to the config home.
+var overridesFile = filepath.Join("xdg-go", "overrides.toml")
+
+// WithoutOverrides disables the user override file. See (*XDG).Overrides.
+func WithoutOverrides() Option {
+	return func(xdg *XDG) { xdg.noOverrides = true }
+}
+
+// Overrides returns the directory remaps that apply to this application from
+// $XDG_CONFIG_HOME/xdg-go/overrides.toml. The file has one table per
+// application with any of the keys config, data, cache, state, or runtime:
+//
+//	[myapp]
+//	cache = "/mnt/scratch/myapp"
+//
+// Remapped directories are used in place of the resolved directory. A
+// leading ~ and $VAR references are expanded as in the XDG variables, and a
+// value that is still not absolute makes the lookup fail with
+// ErrInvalidPath. A missing or malformed file has no effect. The file is
+// read through the installed FileSystem and checked for changes at most
+// once a second.
+func (xdg *XDG) Overrides() map[string]string {
+	if xdg.noOverrides || len(xdg.root) > 0 {
+		return nil
+	}
+	base := xdg.baseDir(configHomeKey)
+	if len(base) == 0 {
+		return nil
+	}
+	return overrides.load(filepath.Join(base, overridesFile))[xdg.finder.Name()]
+}
+
+// override returns the remapped directory for key, reporting whether there
+// is one. The error is set when the remap is not an absolute path.
+func (xdg *XDG) override(key string) (string, bool, error) {
+	var name string
+	switch key {
+	case configHomeKey:
+		name = "config"
+	case dataHomeKey:
+		name = "data"
+	case cacheHomeKey:
+		name = "cache"
+	case stateHomeKey:
+		name = "state"
+	case runtimeDirKey:
+		name = "runtime"
+	default:
+		return "", false, nil
+	}
+	p, ok := xdg.Overrides()[name]
+	if !ok || len(p) == 0 {
+		return "", false, nil
+	}
+	p = xdg.expand(p)
+	if !filepath.IsAbs(p) {
+		return "", true, fmt.Errorf("%w: %s override %q is not absolute", ErrInvalidPath, name, p)
+	}
+	return p, true, nil
+}
+
+var overrides overrideCache
+
+// overrideRecheck is how long the override file is trusted before it is
+// checked for changes again, so directory lookups do not stat it each time.
+var overrideRecheck = time.Second
+
+// overrideCache holds the most recently parsed override file and reparses
+// it only when its size or modification time changes.
+type overrideCache struct {
+	mu      sync.Mutex
+	path    string
+	checked time.Time
+	modTime time.Time
+	size    int64
+	tables  map[string]map[string]string
+}
+
+func (c *overrideCache) load(path string) map[string]map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.path == path && now.Sub(c.checked) < overrideRecheck {
+		return c.tables
+	}
+	info, err := fileSystem().Stat(path)
+	if err != nil {
+		c.path, c.checked, c.modTime, c.size, c.tables = path, now, time.Time{}, -1, nil
+		return nil
+	}
+	if c.path == path && c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+		c.checked = now
+		return c.tables
+	}
+	b, err := Dir(filepath.Dir(path)).ReadFile(filepath.Base(path))
+	if err != nil {
+		return nil
+	}
+	tables, err := parseOverrides(b)
+	if err != nil {
+		tables = nil
+	}
+	c.path, c.checked, c.modTime, c.size, c.tables = path, now, info.ModTime(), info.Size(), tables
+	return tables
+}
+
+// parseOverrides parses the small subset of TOML used by the override file:
+// tables containing string keys.
+func parseOverrides(b []byte) (map[string]map[string]string, error) {
+	var (
+		tables = make(map[string]map[string]string)
+		cur    map[string]string
+		sc     = bufio.NewScanner(bytes.NewReader(b))
+		n      int
+	)
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 || len(stripComment(line[end+1:])) > 0 {
+				return nil, &overrideError{n, "invalid table header"}
+			}
+			name, err := tomlKey(line[1:end])
+			if err != nil {
+				return nil, &overrideError{n, err.Error()}
+			}
+			if cur = tables[name]; cur == nil {
+				cur = make(map[string]string)
+				tables[name] = cur
+			}
+			continue
+		}
+		if cur == nil {
+			return nil, &overrideError{n, "key outside of a table"}
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, &overrideError{n, "expected key = value"}
+		}
+		key, err := tomlKey(k)
+		if err != nil {
+			return nil, &overrideError{n, err.Error()}
+		}
+		val, err := tomlString(v)
+		if err != nil {
+			return nil, &overrideError{n, err.Error()}
+		}
+		cur[key] = val
+	}
+	return tables, sc.Err()
+}
+
+type overrideError struct {
+	line int
+	msg  string
+}
+
+func (e *overrideError) Error() string {
+	return overridesFile + ":" + strconv.Itoa(e.line) + ": " + e.msg
+}
+
+func tomlKey(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+		return tomlString(s)
+	}
+	if len(s) == 0 || strings.ContainsAny(s, " \t\"'#") {
+		return "", strconv.ErrSyntax
+	}
+	return s, nil
+}
+
+func tomlString(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return "", strconv.ErrSyntax
+	}
+	var end int
+	switch s[0] {
+	case '\'':
+		end = strings.IndexByte(s[1:], '\'') + 1
+		if end == 0 || len(stripComment(s[end+1:])) > 0 {
+			return "", strconv.ErrSyntax
+		}
+		return s[1:end], nil
+	case '"':
+		for end = 1; end < len(s); end++ {
+			if s[end] == '\\' {
+				end++
+			} else if s[end] == '"' {
+				break
+			}
+		}
+		if end >= len(s) || len(stripComment(s[end+1:])) > 0 {
+			return "", strconv.ErrSyntax
+		}
+		return strconv.Unquote(s[:end+1])
+	}
+	return "", strconv.ErrSyntax
+}
+
+func stripComment(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 0 && s[0] == '#' {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestOverrides(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(configHomeKey, dir)
+	t.Setenv(cacheHomeKey, "/home/t/.cache")
+	file := filepath.Join(dir, overridesFile)
+	writeTestFile(t, file, `
+# move the cache to a scratch disk
+[myapp]
+cache = "/mnt/scratch/myapp" # trailing comment
+state = '/mnt/state/C:\x'
+
+["other.app"]
+data = "/srv/other"
+`)
+	eq(t, "/mnt/scratch/myapp", Cache("myapp"))
+	eq(t, `/mnt/state/C:\x`, State("myapp"))
+	eq(t, filepath.Join(dir, "myapp"), Config("myapp"))
+	eq(t, "/srv/other", Data("other.app"))
+	eq(t, "/home/t/.cache/other.app", Cache("other.app"))
+	eq(t, "/home/t/.cache/myapp", NewXDG(NewDirFinder("myapp"), WithoutOverrides()).Cache())
+
+	// changes are picked up once the file is checked again
+	later := time.Now().Add(time.Minute)
+	writeTestFile(t, file, "[myapp]\ncache = \"/tmp/c\"\n")
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "/mnt/scratch/myapp", Cache("myapp"))
+	defer func(d time.Duration) { overrideRecheck = d }(overrideRecheck)
+	overrideRecheck = 0
+	eq(t, "/tmp/c", Cache("myapp"))
+
+	// malformed files are ignored
+	writeTestFile(t, file, "cache = \"/tmp/c\"\n")
+	if err := os.Chtimes(file, later.Add(time.Minute), later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "/home/t/.cache/myapp", Cache("myapp"))
+}
+
+func TestParseOverrides(t *testing.T) {
+	for _, in := range []string{
+		"key = \"v\"\n",
+		"[t\n",
+		"[t] x\n",
+		"[t]\nkey\n",
+		"[t]\nkey = v\n",
+		"[t]\nkey = \"v\" x\n",
+		"[t]\nkey = 'v\n",
+		"[t]\nbad key = 'v'\n",
+		"[t]\n = 'v'\n",
+	} {
+		if _, err := parseOverrides([]byte(in)); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+	tables, err := parseOverrides([]byte("[a]\n'b' = \"x\\ty\"\n[a]\nc = ''\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "x\ty", tables["a"]["b"])
+	eq(t, "", tables["a"]["c"])
+}
+
+func TestOverrides_Paths(t *testing.T) {
+	defer func(d time.Duration) { overrideRecheck = d }(overrideRecheck)
+	overrideRecheck = 0
+	unsetAll()
+	dir := t.TempDir()
+	t.Setenv("HOME", "/home/t")
+	t.Setenv(configHomeKey, dir)
+	t.Setenv("SCRATCH", "/mnt/scratch")
+	writeTestFile(t, filepath.Join(dir, overridesFile), `
+[myapp]
+cache = "$SCRATCH/myapp"
+data = "~/big/myapp"
+state = "relative/state"
+`)
+	x := New("myapp")
+	eq(t, "/mnt/scratch/myapp", x.Cache())
+	eq(t, "/home/t/big/myapp", x.Data())
+	if _, err := x.StateE(); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for a relative override, got %v", err)
+	}
+}
+
+func TestOverrides_FileSystem(t *testing.T) {
+	defer func(d time.Duration) { overrideRecheck = d }(overrideRecheck)
+	overrideRecheck = 0
+	unsetAll()
+	SetFileSystem(memFS{m: fstest.MapFS{
+		"conf/xdg-go/overrides.toml": {Data: []byte("[myapp]\ncache = \"/mnt/scratch\"\n")},
+	}})
+	defer SetFileSystem(nil)
+	t.Setenv(configHomeKey, "/conf")
+	eq(t, "/mnt/scratch", New("myapp").Cache())
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package xdg
+
+import "io/fs"
+
+func checkOwner(string, fs.FileInfo) error { return nil }
+
+func fileOwner(fs.FileInfo) (uid, gid int, ok bool) { return 0, 0, false }
+
+func setOwner(string, int, int) error { return nil }
@@ -0,0 +1,39 @@
+//go:build unix
+
+package xdg
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+func checkOwner(path string, info fs.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Getuid(); int(st.Uid) != uid {
+		return fmt.Errorf("%w: %s is owned by uid %d, not %d", ErrInsecurePermissions, path, st.Uid, uid)
+	}
+	return nil
+}
+
+// fileOwner returns the uid and gid of the file described by info.
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
+
+// setOwner changes the owner of path, without following a symbolic link.
+// Only root may give files away, so it does nothing for anyone else.
+func setOwner(path string, uid, gid int) error {
+	if os.Geteuid() != 0 || !isOSFileSystem() {
+		return nil
+	}
+	return os.Lchown(longPath(path), uid, gid)
+}
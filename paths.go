@@ -0,0 +1,32 @@
+package xdg
+
+// Paths is every directory of an application, tagged for encoding with
+// encoding/json and YAML libraries.
+type Paths struct {
+	ConfigHome string `json:"configHome" yaml:"configHome"`
+	DataHome   string `json:"dataHome" yaml:"dataHome"`
+	CacheHome  string `json:"cacheHome" yaml:"cacheHome"`
+	StateHome  string `json:"stateHome" yaml:"stateHome"`
+	// RuntimeDir is empty when there is no runtime directory.
+	RuntimeDir string   `json:"runtimeDir,omitempty" yaml:"runtimeDir,omitempty"`
+	ConfigDirs []string `json:"configDirs" yaml:"configDirs"`
+	DataDirs   []string `json:"dataDirs" yaml:"dataDirs"`
+}
+
+// All returns every directory of the application name. See (*XDG).Paths.
+func All(name string) Paths { return newXdg(name).Paths() }
+
+// Paths returns every directory of the application in one value, for
+// diagnostics and debug output. The search paths are never nil so they
+// encode as empty lists.
+func (xdg *XDG) Paths() Paths {
+	return Paths{
+		ConfigHome: xdg.Config(),
+		DataHome:   xdg.Data(),
+		CacheHome:  xdg.Cache(),
+		StateHome:  xdg.State(),
+		RuntimeDir: xdg.Runtime(),
+		ConfigDirs: append([]string{}, xdg.ConfigDirs()...),
+		DataDirs:   append([]string{}, xdg.DataDirs()...),
+	}
+}
@@ -0,0 +1,41 @@
+package xdg
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv(configHomeKey, filepath.Join(tmp, "config"))
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	t.Setenv(dataDirsKey, "")
+	p := All("app")
+	eq(t, filepath.Join(tmp, "config", "app"), p.ConfigHome)
+	eq(t, Data("app"), p.DataHome)
+	eq(t, Cache("app"), p.CacheHome)
+	eq(t, State("app"), p.StateHome)
+	eq(t, "", p.RuntimeDir)
+	arrEq(t, []string{filepath.Join(tmp, "etc", "app")}, p.ConfigDirs)
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err = json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["runtimeDir"]; ok {
+		t.Error("an empty runtime dir should be omitted")
+	}
+	eq(t, any(p.ConfigHome), m["configHome"])
+	if dirs, ok := m["dataDirs"].([]any); !ok {
+		t.Errorf("dataDirs should be a list, got %v", m["dataDirs"])
+	} else if len(dirs) != len(p.DataDirs) {
+		t.Errorf("wrong dataDirs %v", dirs)
+	}
+}
@@ -0,0 +1,81 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PortableMarker is the marker file name conventionally used with
+// WithPortableMarker.
+const PortableMarker = "portable"
+
+var executable = os.Executable
+
+// WithPortable keeps the application's files in a single directory, as a
+// portable install on a USB stick would:
+//
+//	<root>/config
+//	<root>/data
+//	<root>/cache
+//	<root>/state
+//
+// An empty root means the directory containing the executable. The runtime
+// and system directories are resolved as usual. The application name is not
+// appended since root belongs to the application.
+func WithPortable(root string) Option {
+	return func(xdg *XDG) {
+		xdg.portable = true
+		xdg.portableRoot = root
+	}
+}
+
+// WithPortableMarker enables portable mode, rooted at the directory of the
+// executable, only if a file named marker exists next to the executable.
+// See WithPortable.
+func WithPortableMarker(marker string) Option {
+	return func(xdg *XDG) { xdg.portableMarker = marker }
+}
+
+// portableDir returns the portable directory for key if portable mode is
+// on.
+func (xdg *XDG) portableDir(key string) (string, bool) {
+	var sub string
+	switch key {
+	case configHomeKey:
+		sub = "config"
+	case dataHomeKey:
+		sub = "data"
+	case cacheHomeKey:
+		sub = "cache"
+	case stateHomeKey:
+		sub = "state"
+	default:
+		return "", false
+	}
+	root, ok := xdg.portableBase()
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(root, sub), true
+}
+
+func (xdg *XDG) portableBase() (string, bool) {
+	if !xdg.portable && len(xdg.portableMarker) == 0 {
+		return "", false
+	}
+	if xdg.portable && len(xdg.portableRoot) > 0 {
+		return xdg.portableRoot, true
+	}
+	exe, err := executable()
+	if err != nil {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	dir := filepath.Dir(exe)
+	if !xdg.portable && !exists(filepath.Join(dir, xdg.portableMarker)) {
+		return "", false
+	}
+	return dir, true
+}
@@ -0,0 +1,32 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithPortable(t *testing.T) {
+	unsetAll()
+	t.Setenv(runtimeDirKey, "/run/user/1000")
+	root := t.TempDir()
+	x := New("app", WithHomeDir("/home/t"), WithPortable(root))
+	eq(t, filepath.Join(root, "config"), x.Config())
+	eq(t, filepath.Join(root, "data"), x.Data())
+	eq(t, filepath.Join(root, "cache"), x.Cache())
+	eq(t, filepath.Join(root, "state"), x.State())
+	eq(t, "/run/user/1000/app", x.Runtime())
+
+	exeDir := t.TempDir()
+	executable = func() (string, error) { return filepath.Join(exeDir, "app.exe"), nil }
+	t.Cleanup(func() { executable = os.Executable })
+
+	x = New("app", WithHomeDir("/home/t"), WithPortable(""))
+	eq(t, filepath.Join(exeDir, "config"), x.Config())
+
+	x = New("app", WithHomeDir("/home/t"), WithPortableMarker(PortableMarker))
+	eq(t, "/home/t/.config/app", x.Config())
+	writeTestFile(t, filepath.Join(exeDir, PortableMarker))
+	eq(t, filepath.Join(exeDir, "config"), x.Config())
+	eq(t, filepath.Join(exeDir, "state"), x.State())
+}
@@ -0,0 +1,32 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// setModTime sets the access and modification times of path to mtime. The
+// FileSystem interface cannot set times, so it only has an effect on the
+// host file system.
+func setModTime(path string, mtime time.Time) error {
+	if !isOSFileSystem() || mtime.IsZero() {
+		return nil
+	}
+	return os.Chtimes(longPath(path), mtime, mtime)
+}
+
+// chmod sets the permission bits of path on the host file system.
+func chmod(path string, perm fs.FileMode) error {
+	err := os.Chmod(longPath(path), perm)
+	audit(OpChmod, path, 0, perm, err)
+	return err
+}
+
+// lexists reports whether path exists on the host file system without
+// following a final symbolic link.
+func lexists(path string) bool {
+	_, err := os.Lstat(longPath(path))
+	return err == nil || !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,60 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+const profilesDir = "profiles"
+
+// WithProfile nests the config, data, cache and state directories under
+// <dir>/profiles/<name> so several profiles of the application can be kept
+// side by side. The runtime and system directories are shared by all
+// profiles.
+func WithProfile(name string) Option {
+	return func(xdg *XDG) { xdg.profile = name }
+}
+
+// Profile returns the profile set with WithProfile.
+func (xdg *XDG) Profile() string { return xdg.profile }
+
+// Profiles returns the sorted names of the profiles that have a config,
+// data, cache or state directory.
+func (xdg *XDG) Profiles() ([]string, error) {
+	seen := make(map[string]bool)
+	for _, key := range []string{configHomeKey, dataHomeKey, cacheHomeKey, stateHomeKey} {
+		dir, err := xdg.appDirE(key)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := Dir(dir).ReadDir(profilesDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				seen[e.Name()] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Profiles returns the profiles of the application name. See
+// (*XDG).Profiles.
+func Profiles(name string) ([]string, error) { return newXdg(name).Profiles() }
+
+// validProfile reports whether name can be used as a single profile
+// directory.
+func validProfile(name string) bool {
+	return len(name) > 0 && filepath.IsLocal(name) && filepath.Base(name) == name
+}
@@ -0,0 +1,54 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithProfile(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv(runtimeDirKey, "/run/user/1000")
+	x := New("app", WithHomeDir(home), WithProfile("work"))
+	eq(t, "work", x.Profile())
+	eq(t, filepath.Join(home, ".config/app/profiles/work"), x.Config())
+	eq(t, filepath.Join(home, ".local/share/app/profiles/work"), x.Data())
+	eq(t, filepath.Join(home, ".cache/app/profiles/work"), x.Cache())
+	eq(t, filepath.Join(home, ".local/state/app/profiles/work"), x.State())
+	eq(t, "/run/user/1000/app", x.Runtime())
+
+	_, err := New("app", WithHomeDir(home), WithProfile("../x")).ConfigE()
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	x := New("app", WithHomeDir(home))
+	names, err := x.Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 0, len(names))
+
+	for _, p := range []string{
+		".config/app/profiles/work",
+		".config/app/profiles/home",
+		".cache/app/profiles/work",
+		".local/state/app/profiles/old",
+	} {
+		if err = os.MkdirAll(filepath.Join(home, p), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(home, ".config/app/profiles/notes.txt"))
+	names, err = New("app", WithHomeDir(home), WithProfile("work")).Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{"home", "old", "work"}, names)
+}
@@ -0,0 +1,53 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+)
+
+// Prune removes the empty directories below d, deepest first, so that a
+// directory holding nothing but empty directories is removed as well. d
+// itself is kept. It returns the paths of the removed directories. A missing
+// directory is not an error.
+func (d Dir) Prune() ([]string, error) { return d.prune(false) }
+
+// PruneAll is like Prune but also removes d if it ends up empty.
+func (d Dir) PruneAll() ([]string, error) { return d.prune(true) }
+
+func (d Dir) prune(self bool) ([]string, error) {
+	var dirs []string
+	err := d.Walk(func(name string, e fs.DirEntry, err error) error {
+		if err != nil {
+			if name == "." && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if e.IsDir() && (self || name != ".") {
+			dirs = append(dirs, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	// The walk is in lexical order, so every directory comes after its
+	// parent.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := d.ReadDir(dirs[i])
+		if err != nil {
+			return removed, err
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		full := filepath.Join(string(d), filepath.FromSlash(dirs[i]))
+		if err = remove(full); err != nil {
+			return removed, err
+		}
+		removed = append(removed, full)
+	}
+	return removed, nil
+}
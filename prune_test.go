@@ -0,0 +1,47 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	tmp := t.TempDir()
+	d := Dir(filepath.Join(tmp, "cache"))
+	for _, p := range []string{"a/b/c", "a/d", "keep/empty"} {
+		if err := os.MkdirAll(filepath.Join(string(d), p), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(string(d), "keep", "file"))
+
+	removed, err := d.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrEq(t, []string{
+		filepath.Join(string(d), "keep", "empty"),
+		filepath.Join(string(d), "a", "d"),
+		filepath.Join(string(d), "a", "b", "c"),
+		filepath.Join(string(d), "a", "b"),
+		filepath.Join(string(d), "a"),
+	}, removed)
+	if !d.Exists() || !exists(filepath.Join(string(d), "keep", "file")) {
+		t.Error("prune removed too much")
+	}
+
+	if err = os.Remove(filepath.Join(string(d), "keep", "file")); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err = d.PruneAll(); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(removed))
+	if d.Exists() {
+		t.Error("expected the directory to be removed")
+	}
+	if removed, err = d.Prune(); err != nil || len(removed) != 0 {
+		t.Errorf("expected nothing to do for a missing directory, got %v, %v", removed, err)
+	}
+}
@@ -0,0 +1,505 @@
+// Package recent reads and writes the list of recently used files shared by
+// desktop applications in $XDG_DATA_HOME/recently-used.xbel.
+//
+// See docs:
+//
+//	https://www.freedesktop.org/wiki/Specifications/desktop-bookmark-spec/
+package recent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/mime"
+)
+
+// FileName is the name of the recent files list in $XDG_DATA_HOME.
+const FileName = "recently-used.xbel"
+
+const (
+	nsBookmark = "http://www.freedesktop.org/standards/desktop-bookmarks"
+	nsMime     = "http://www.freedesktop.org/standards/shared-mime-info"
+	// metadataOwner owns the metadata block defined by the spec.
+	metadataOwner = "http://freedesktop.org"
+	// timeFormat is the format GLib writes timestamps in.
+	timeFormat = "2006-01-02T15:04:05.000000Z"
+)
+
+// lockTimeout is how long Add waits for other writers.
+var lockTimeout = 5 * time.Second
+
+// Bookmark is an entry in the recent files list.
+type Bookmark struct {
+	// Href is the URI of the file.
+	Href        string
+	Title       string
+	Description string
+	Added       time.Time
+	Modified    time.Time
+	Visited     time.Time
+	MimeType    string
+	// Groups are the names of the groups the file belongs to, such as the
+	// name of the application that opened it.
+	Groups []string
+	// Applications are the applications that registered the file.
+	Applications []Application
+	// Private bookmarks should only be shown by the applications that
+	// registered them.
+	Private bool
+}
+
+// Application is an application that registered a bookmark.
+type Application struct {
+	Name string
+	// Exec is the command line used to open the file, with %u or %f
+	// standing for its URI or path.
+	Exec     string
+	Modified time.Time
+	// Count is how many times the application registered the file.
+	Count int
+}
+
+// Path returns the local path of a file URI.
+func (b Bookmark) Path() (string, bool) {
+	u, err := url.Parse(b.Href)
+	if err != nil || u.Scheme != "file" || (len(u.Host) > 0 && u.Host != "localhost") {
+		return "", false
+	}
+	p := u.Path
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		// file:///C:/dir on windows.
+		p = p[1:]
+	}
+	return filepath.FromSlash(p), true
+}
+
+// Application returns the entry for the application name.
+func (b Bookmark) Application(name string) (Application, bool) {
+	i := slices.IndexFunc(b.Applications, func(a Application) bool { return a.Name == name })
+	if i < 0 {
+		return Application{}, false
+	}
+	return b.Applications[i], true
+}
+
+// File returns the path of the recent files list,
+// $XDG_DATA_HOME/recently-used.xbel.
+func File() (string, error) { return xdg.DataE(FileName) }
+
+// List reads the recent files list and returns its bookmarks, most recently
+// modified first. A missing list is empty.
+func List() ([]Bookmark, error) {
+	path, err := File()
+	if err != nil {
+		return nil, err
+	}
+	bms, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(bms, func(i, j int) bool { return bms[i].Modified.After(bms[j].Modified) })
+	return bms, nil
+}
+
+// Load reads the bookmarks of an XBEL file.
+func Load(path string) ([]Bookmark, error) {
+	b, err := xdg.Dir(filepath.Dir(path)).ReadFile(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	bms, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return bms, nil
+}
+
+// Parse reads the bookmarks of an XBEL document in file order.
+func Parse(r io.Reader) ([]Bookmark, error) {
+	var doc xbel
+	if err := xml.NewTokenDecoder(prefixReader{xml.NewDecoder(r)}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	bms := make([]Bookmark, 0, len(doc.Bookmarks))
+	for _, x := range doc.Bookmarks {
+		b := Bookmark{
+			Href:        x.Href,
+			Title:       strings.TrimSpace(x.Title),
+			Description: strings.TrimSpace(x.Desc),
+			Added:       parseTime(x.Added),
+			Modified:    parseTime(x.Modified),
+			Visited:     parseTime(x.Visited),
+		}
+		for _, m := range x.Info.Metadata {
+			if m.Owner != metadataOwner {
+				continue
+			}
+			if m.MimeType != nil {
+				b.MimeType = m.MimeType.Type
+			}
+			b.Groups = append(b.Groups, m.Groups...)
+			b.Private = b.Private || m.Private != nil
+			for _, a := range m.Applications {
+				app := Application{Name: a.Name, Exec: a.Exec, Modified: parseTime(a.Modified)}
+				if app.Modified.IsZero() {
+					// Older files store seconds since the epoch.
+					if sec, err := strconv.ParseInt(a.Timestamp, 10, 64); err == nil {
+						app.Modified = time.Unix(sec, 0)
+					}
+				}
+				app.Count, _ = strconv.Atoi(a.Count)
+				b.Applications = append(b.Applications, app)
+			}
+		}
+		bms = append(bms, b)
+	}
+	return bms, nil
+}
+
+// Write writes bms as an XBEL document in the layout GLib uses.
+func Write(w io.Writer, bms []Bookmark) error {
+	doc := xbel{Version: "1.0", NSBookmark: nsBookmark, NSMime: nsMime}
+	for _, b := range bms {
+		x := xmlBookmark{
+			Href:     b.Href,
+			Added:    formatTime(b.Added),
+			Modified: formatTime(b.Modified),
+			Visited:  formatTime(b.Visited),
+			Title:    b.Title,
+			Desc:     b.Description,
+		}
+		m := xmlMetadata{Owner: metadataOwner, Groups: b.Groups}
+		if len(b.MimeType) > 0 {
+			m.MimeType = &xmlMime{Type: b.MimeType}
+		}
+		if b.Private {
+			m.Private = &struct{}{}
+		}
+		for _, a := range b.Applications {
+			m.Applications = append(m.Applications, xmlApp{
+				Name:     a.Name,
+				Exec:     a.Exec,
+				Modified: formatTime(a.Modified),
+				Count:    strconv.Itoa(a.Count),
+			})
+		}
+		x.Info.Metadata = []xmlMetadata{m}
+		doc.Bookmarks = append(doc.Bookmarks, x)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Entry describes a file being added to the recent files list.
+type Entry struct {
+	// URI is the file's URI. A local path is converted to a file URI.
+	URI string
+	// MimeType is detected from the file when empty.
+	MimeType string
+	// AppName defaults to the program name.
+	AppName string
+	// AppExec defaults to "'<AppName> %u'".
+	AppExec string
+	Groups  []string
+	Private bool
+}
+
+// Add records that e.URI was used by an application, adding it to the
+// recent files list or updating its existing bookmark. The list is replaced
+// atomically while holding a lock on recently-used.xbel.lock so concurrent
+// writers do not lose each other's entries.
+func Add(e Entry) error {
+	e, err := e.complete()
+	if err != nil {
+		return err
+	}
+	return update(func(bms []Bookmark) ([]Bookmark, bool) {
+		return add(bms, e, time.Now()), true
+	})
+}
+
+// Prune trims the recent files list, removing the bookmarks that were
+// last modified more than maxAge ago and then the least recently modified
+// ones until at most maxEntries remain. A zero argument disables that
+// limit. It returns the number of bookmarks removed. The list is rewritten
+// the same way as by Add.
+func Prune(maxEntries int, maxAge time.Duration) (int, error) {
+	var removed int
+	err := update(func(bms []Bookmark) ([]Bookmark, bool) {
+		var cut time.Time
+		if maxAge > 0 {
+			cut = time.Now().Add(-maxAge)
+		}
+		keep := make(map[int]bool, len(bms))
+		byAge := make([]int, 0, len(bms))
+		for i, b := range bms {
+			if cut.IsZero() || !b.Modified.Before(cut) {
+				byAge = append(byAge, i)
+			}
+		}
+		sort.SliceStable(byAge, func(i, j int) bool { return bms[byAge[i]].Modified.After(bms[byAge[j]].Modified) })
+		if maxEntries > 0 && len(byAge) > maxEntries {
+			byAge = byAge[:maxEntries]
+		}
+		for _, i := range byAge {
+			keep[i] = true
+		}
+		out := bms[:0]
+		for i, b := range bms {
+			if keep[i] {
+				out = append(out, b)
+			}
+		}
+		removed = len(bms) - len(out)
+		return out, removed > 0
+	})
+	return removed, err
+}
+
+// RemoveByApp removes every trace of the application app from the recent
+// files list: app is removed from the applications of each bookmark, and
+// bookmarks no other application has used are removed entirely. It returns
+// the number of bookmarks removed. The list is rewritten the same way as by
+// Add.
+func RemoveByApp(app string) (int, error) {
+	var removed int
+	err := update(func(bms []Bookmark) ([]Bookmark, bool) {
+		changed := false
+		out := bms[:0]
+		for _, b := range bms {
+			apps := slices.DeleteFunc(b.Applications, func(a Application) bool { return a.Name == app })
+			if len(apps) == len(b.Applications) {
+				out = append(out, b)
+				continue
+			}
+			changed = true
+			if len(apps) == 0 {
+				removed++
+				continue
+			}
+			b.Applications = apps
+			out = append(out, b)
+		}
+		return out, changed
+	})
+	return removed, err
+}
+
+// update rewrites the recent files list with the result of fn while holding
+// a lock on recently-used.xbel.lock. The list is replaced atomically and
+// only if fn reports a change.
+func update(fn func([]Bookmark) ([]Bookmark, bool)) error {
+	path, err := File()
+	if err != nil {
+		return err
+	}
+	dir := xdg.Dir(filepath.Dir(path))
+	if err = dir.Create(); err != nil {
+		return err
+	}
+	l, err := lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	bms, err := Load(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	bms, changed := fn(bms)
+	if !changed {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err = Write(&buf, bms); err != nil {
+		return err
+	}
+	return dir.WriteFileAtomic(FileName, buf.Bytes(), 0600)
+}
+
+func (e Entry) complete() (Entry, error) {
+	if len(e.URI) == 0 {
+		return e, errors.New("recent: empty URI")
+	}
+	var local string
+	if u, err := url.Parse(e.URI); err != nil || len(u.Scheme) < 2 {
+		// Not a URI, or a windows drive letter.
+		local, err = filepath.Abs(e.URI)
+		if err != nil {
+			return e, err
+		}
+		e.URI = fileURI(local)
+	} else {
+		local, _ = Bookmark{Href: e.URI}.Path()
+	}
+	if len(e.MimeType) == 0 {
+		e.MimeType = mime.OctetStream
+		if len(local) > 0 {
+			if typ, err := mime.TypeOf(local); err == nil {
+				e.MimeType = typ
+			}
+		}
+	}
+	if len(e.AppName) == 0 {
+		e.AppName = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	}
+	if len(e.AppExec) == 0 {
+		e.AppExec = "'" + e.AppName + " %u'"
+	}
+	return e, nil
+}
+
+func fileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+// add merges e into bms.
+func add(bms []Bookmark, e Entry, now time.Time) []Bookmark {
+	i := slices.IndexFunc(bms, func(b Bookmark) bool { return b.Href == e.URI })
+	if i < 0 {
+		bms = append(bms, Bookmark{Href: e.URI, Added: now})
+		i = len(bms) - 1
+	}
+	b := &bms[i]
+	b.Modified, b.Visited = now, now
+	b.MimeType = e.MimeType
+	b.Private = b.Private || e.Private
+	for _, g := range e.Groups {
+		if !slices.Contains(b.Groups, g) {
+			b.Groups = append(b.Groups, g)
+		}
+	}
+	j := slices.IndexFunc(b.Applications, func(a Application) bool { return a.Name == e.AppName })
+	if j < 0 {
+		b.Applications = append(b.Applications, Application{Name: e.AppName})
+		j = len(b.Applications) - 1
+	}
+	app := &b.Applications[j]
+	app.Exec, app.Modified = e.AppExec, now
+	app.Count++
+	return bms
+}
+
+// lock waits up to lockTimeout for the lock on path.
+func lock(path string) (*xdg.Lock, error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		l, err := xdg.LockFile(path)
+		if !errors.Is(err, xdg.ErrLocked) || time.Now().After(deadline) {
+			return l, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeFormat)
+}
+
+type xbel struct {
+	XMLName    xml.Name      `xml:"xbel"`
+	Version    string        `xml:"version,attr"`
+	NSBookmark string        `xml:"xmlns:bookmark,attr"`
+	NSMime     string        `xml:"xmlns:mime,attr"`
+	Bookmarks  []xmlBookmark `xml:"bookmark"`
+}
+
+type xmlBookmark struct {
+	Href     string `xml:"href,attr"`
+	Added    string `xml:"added,attr,omitempty"`
+	Modified string `xml:"modified,attr,omitempty"`
+	Visited  string `xml:"visited,attr,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Desc     string `xml:"desc,omitempty"`
+	Info     struct {
+		Metadata []xmlMetadata `xml:"metadata"`
+	} `xml:"info"`
+}
+
+type xmlMetadata struct {
+	Owner        string    `xml:"owner,attr"`
+	MimeType     *xmlMime  `xml:"mime:mime-type"`
+	Groups       []string  `xml:"bookmark:groups>bookmark:group"`
+	Applications []xmlApp  `xml:"bookmark:applications>bookmark:application"`
+	Private      *struct{} `xml:"bookmark:private"`
+}
+
+type xmlMime struct {
+	Type string `xml:"type,attr"`
+}
+
+type xmlApp struct {
+	Name      string `xml:"name,attr"`
+	Exec      string `xml:"exec,attr"`
+	Modified  string `xml:"modified,attr,omitempty"`
+	Timestamp string `xml:"timestamp,attr,omitempty"`
+	Count     string `xml:"count,attr"`
+}
+
+// prefixReader returns raw tokens with namespace prefixes folded into the
+// local name, so the same "bookmark:group" style struct tags work for
+// decoding and for writing the prefixes other implementations expect.
+type prefixReader struct{ d *xml.Decoder }
+
+func (r prefixReader) Token() (xml.Token, error) {
+	tok, err := r.d.RawToken()
+	switch t := tok.(type) {
+	case xml.StartElement:
+		t.Name = foldPrefix(t.Name)
+		attrs := make([]xml.Attr, len(t.Attr))
+		for i, a := range t.Attr {
+			attrs[i] = xml.Attr{Name: foldPrefix(a.Name), Value: a.Value}
+		}
+		t.Attr = attrs
+		return t, err
+	case xml.EndElement:
+		t.Name = foldPrefix(t.Name)
+		return t, err
+	}
+	return tok, err
+}
+
+func foldPrefix(n xml.Name) xml.Name {
+	if len(n.Space) == 0 {
+		return n
+	}
+	return xml.Name{Local: n.Space + ":" + n.Local}
+}
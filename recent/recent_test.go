@@ -0,0 +1,263 @@
+package recent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+// glibFile is a list as written by GLib.
+const glibFile = `<?xml version="1.0" encoding="UTF-8"?>
+<xbel version="1.0"
+      xmlns:bookmark="http://www.freedesktop.org/standards/desktop-bookmarks"
+      xmlns:mime="http://www.freedesktop.org/standards/shared-mime-info"
+>
+  <bookmark href="file:///home/user/notes.txt" added="2024-03-01T10:00:00.123456Z" modified="2024-03-02T11:00:00.000000Z" visited="2024-03-02T11:00:00.000000Z">
+    <title>Notes</title>
+    <info>
+      <metadata owner="http://freedesktop.org">
+        <mime:mime-type type="text/plain"/>
+        <bookmark:groups>
+          <bookmark:group>gedit</bookmark:group>
+        </bookmark:groups>
+        <bookmark:applications>
+          <bookmark:application name="gedit" exec="&apos;gedit %u&apos;" modified="2024-03-02T11:00:00.000000Z" count="3"/>
+          <bookmark:application name="vim" exec="&apos;vim %f&apos;" timestamp="1709200000" count="1"/>
+        </bookmark:applications>
+        <bookmark:private/>
+      </metadata>
+      <metadata owner="http://example.com">
+        <mime:mime-type type="image/png"/>
+      </metadata>
+    </info>
+  </bookmark>
+  <bookmark href="https://example.com/" added="2024-03-03T10:00:00Z" modified="2024-03-03T10:00:00Z" visited="2024-03-03T10:00:00Z">
+    <info>
+      <metadata owner="http://freedesktop.org">
+        <mime:mime-type type="text/html"/>
+      </metadata>
+    </info>
+  </bookmark>
+</xbel>`
+
+func TestParse(t *testing.T) {
+	bms, err := Parse(strings.NewReader(glibFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bms) != 2 {
+		t.Fatalf("expected 2 bookmarks, got %d", len(bms))
+	}
+	b := bms[0]
+	if b.Title != "Notes" || b.MimeType != "text/plain" || !b.Private {
+		t.Errorf("wrong bookmark %+v", b)
+	}
+	if len(b.Groups) != 1 || b.Groups[0] != "gedit" {
+		t.Errorf("wrong groups %v", b.Groups)
+	}
+	if want := time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.UTC); !b.Added.Equal(want) {
+		t.Errorf("wrong added time %v", b.Added)
+	}
+	gedit, ok := b.Application("gedit")
+	if !ok || gedit.Exec != "'gedit %u'" || gedit.Count != 3 {
+		t.Errorf("wrong application %+v", gedit)
+	}
+	vim, _ := b.Application("vim")
+	if !vim.Modified.Equal(time.Unix(1709200000, 0)) {
+		t.Errorf("wrong timestamp %v", vim.Modified)
+	}
+	if p, ok := b.Path(); !ok || p != filepath.FromSlash("/home/user/notes.txt") {
+		t.Errorf("wrong path %q", p)
+	}
+	if _, ok = bms[1].Path(); ok {
+		t.Error("https URI should not have a path")
+	}
+
+	var buf bytes.Buffer
+	if err = Write(&buf, bms); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`xmlns:bookmark="http://www.freedesktop.org/standards/desktop-bookmarks"`,
+		`<mime:mime-type type="text/plain">`,
+		`<bookmark:group>gedit</bookmark:group>`,
+		`<bookmark:application name="gedit" exec="&#39;gedit %u&#39;" modified="2024-03-02T11:00:00.000000Z" count="3">`,
+		`<bookmark:private>`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("output is missing %s:\n%s", s, buf.String())
+		}
+	}
+	again, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 2 || again[0].MimeType != "text/plain" || len(again[0].Applications) != 2 || !again[0].Added.Equal(b.Added) {
+		t.Errorf("round trip lost data: %+v", again)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	xdgtest.Sandbox(t)
+	file := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(file, []byte("%PDF-1.4"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if bms, err := List(); err != nil || len(bms) != 0 {
+		t.Fatalf("expected an empty list, got %v, %v", bms, err)
+	}
+	err := Add(Entry{URI: file, MimeType: "application/pdf", AppName: "viewer", Groups: []string{"docs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Add(Entry{URI: "https://example.com/", MimeType: "text/html", AppName: "browser"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Add(Entry{URI: file, MimeType: "application/pdf", AppName: "viewer"}); err != nil {
+		t.Fatal(err)
+	}
+	path, err := File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(os.Getenv("XDG_DATA_HOME"), FileName); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+	bms, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bms) != 2 {
+		t.Fatalf("expected 2 bookmarks, got %+v", bms)
+	}
+	b := bms[0]
+	if p, _ := b.Path(); p != file {
+		t.Errorf("expected %s first, got %s", file, b.Href)
+	}
+	app, ok := b.Application("viewer")
+	if !ok || app.Count != 2 || app.Exec != "'viewer %u'" {
+		t.Errorf("wrong application %+v", app)
+	}
+	if len(b.Groups) != 1 || b.MimeType != "application/pdf" || b.Added.After(b.Modified) {
+		t.Errorf("wrong bookmark %+v", b)
+	}
+	if _, err = os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file should be removed, got %v", err)
+	}
+}
+
+func TestAddConcurrent(t *testing.T) {
+	xdgtest.Sandbox(t)
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Add(Entry{URI: "https://example.com/" + name, MimeType: "text/html"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	bms, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bms) != 8 {
+		t.Errorf("expected 8 bookmarks, got %d", len(bms))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	xdgtest.Sandbox(t)
+	if n, err := Prune(1, 0); err != nil || n != 0 {
+		t.Fatalf("expected nothing to prune, got %d, %v", n, err)
+	}
+	path, err := File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("pruning an empty list should not create it, got %v", err)
+	}
+	now := time.Now()
+	bms := []Bookmark{
+		{Href: "file:///old", Modified: now.Add(-48 * time.Hour)},
+		{Href: "file:///a", Modified: now.Add(-3 * time.Hour)},
+		{Href: "file:///b", Modified: now.Add(-1 * time.Hour)},
+		{Href: "file:///c", Modified: now.Add(-2 * time.Hour)},
+	}
+	var buf bytes.Buffer
+	if err = Write(&buf, bms); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Prune(0, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 expired bookmark, got %d", n)
+	}
+	if n, err = Prune(2, 0); err != nil || n != 1 {
+		t.Fatalf("expected 1 bookmark over the limit, got %d, %v", n, err)
+	}
+	left, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 2 || left[0].Href != "file:///b" || left[1].Href != "file:///c" {
+		t.Errorf("wrong bookmarks left: %+v", left)
+	}
+}
+
+func TestRemoveByApp(t *testing.T) {
+	xdgtest.Sandbox(t)
+	for _, e := range []Entry{
+		{URI: "https://example.com/a", MimeType: "text/html", AppName: "browser"},
+		{URI: "https://example.com/b", MimeType: "text/html", AppName: "browser"},
+		{URI: "https://example.com/b", MimeType: "text/html", AppName: "editor"},
+		{URI: "https://example.com/c", MimeType: "text/html", AppName: "editor"},
+	} {
+		if err := Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := RemoveByApp("browser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 bookmark removed, got %d", n)
+	}
+	bms, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bms) != 2 {
+		t.Fatalf("expected 2 bookmarks, got %+v", bms)
+	}
+	for _, b := range bms {
+		if _, ok := b.Application("browser"); ok {
+			t.Errorf("%s still lists browser", b.Href)
+		}
+		if _, ok := b.Application("editor"); !ok {
+			t.Errorf("%s lost editor", b.Href)
+		}
+	}
+	if n, err = RemoveByApp("browser"); err != nil || n != 0 {
+		t.Errorf("expected nothing left to remove, got %d, %v", n, err)
+	}
+}
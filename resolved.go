@@ -0,0 +1,154 @@
+package xdg
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Source says where the directory holding a Resolved file was configured.
+type Source int
+
+const (
+	// SourceDefault is a directory from the platform defaults.
+	SourceDefault Source = iota
+	// SourceEnv is a directory taken from an environment variable, such as
+	// $XDG_CONFIG_HOME or a systemd $STATE_DIRECTORY.
+	SourceEnv
+	// SourceOption is a directory chosen by an Option, such as WithRoot,
+	// WithPortable or WithSystemMode.
+	SourceOption
+	// SourceOverride is a directory remapped by the user override file. See
+	// (*XDG).Overrides.
+	SourceOverride
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceEnv:
+		return "env"
+	case SourceOption:
+		return "option"
+	case SourceOverride:
+		return "override"
+	}
+	return "unknown"
+}
+
+// Resolved is a file found on a search path along with where it came from,
+// for logging and diagnostics.
+type Resolved struct {
+	Path string
+	Kind Kind
+	// Layer is the position of the directory in the search path. Layer 0 is
+	// the home directory of Kind and layer n is the nth system directory.
+	Layer int
+	// Source is where the directory of Layer was configured.
+	Source Source
+}
+
+// Resolve returns the copy of file that takes precedence on the
+// application's search path for kind. See (*XDG).Resolve.
+func Resolve(kind Kind, name, file string) (Resolved, error) {
+	return newXdg(name).Resolve(kind, file)
+}
+
+// ResolveConfigFile is like SearchConfigFile but describes the file that
+// was found. See (*XDG).Resolve.
+func (xdg *XDG) ResolveConfigFile(file string) (Resolved, error) {
+	return xdg.Resolve(KindConfig, file)
+}
+
+// ResolveDataFile is like SearchDataFile but describes the file that was
+// found. See (*XDG).Resolve.
+func (xdg *XDG) ResolveDataFile(file string) (Resolved, error) {
+	return xdg.Resolve(KindData, file)
+}
+
+// Resolve returns the copy of file that takes precedence on the search path
+// of kind, as SearchDirs orders it. The error wraps fs.ErrNotExist if no
+// copy exists.
+func (xdg *XDG) Resolve(kind Kind, file string) (Resolved, error) {
+	key, ok := kind.key()
+	if !ok {
+		return Resolved{}, fmt.Errorf("xdg: unknown directory kind %v", kind)
+	}
+	home, _ := xdg.getDirE(key)
+	dirs := []string{home}
+	switch kind {
+	case KindConfig:
+		dirs = append(dirs, xdg.ConfigDirs()...)
+	case KindData:
+		dirs = append(dirs, xdg.DataDirs()...)
+	}
+	res := find(dirs, file, true)
+	if len(res) == 0 {
+		return Resolved{}, &fs.PathError{Op: "resolve", Path: file, Err: fs.ErrNotExist}
+	}
+	r := Resolved{Path: res[0].Path, Kind: kind, Layer: res[0].Layer}
+	if r.Layer == 0 {
+		r.Source = xdg.homeSource(key)
+	} else {
+		r.Source = xdg.dirsSource(kind)
+	}
+	return r, nil
+}
+
+// OpenFirst opens the copy of file that takes precedence on the search path
+// of kind. See (*XDG).Resolve.
+func (xdg *XDG) OpenFirst(kind Kind, file string) (fs.File, Resolved, error) {
+	r, err := xdg.Resolve(kind, file)
+	if err != nil {
+		return nil, r, err
+	}
+	f, err := Dir(filepath.Dir(r.Path)).Open(filepath.Base(r.Path))
+	return f, r, err
+}
+
+// homeSource follows the precedence of appDirE to find where the home
+// directory for key comes from.
+func (xdg *XDG) homeSource(key string) Source {
+	if len(xdg.root) > 0 {
+		return SourceOption
+	}
+	if _, ok := xdg.portableDir(key); ok {
+		return SourceOption
+	}
+	if _, ok, _ := xdg.override(key); ok {
+		return SourceOverride
+	}
+	if _, ok := xdg.systemdDir(key); ok {
+		return SourceEnv
+	}
+	if _, ok := xdg.systemBase(key); ok {
+		return SourceOption
+	}
+	if key == runtimeDirKey {
+		if dir, ok := xdg.envDir(key); ok && (!xdg.runtimeFallback || ValidateRuntimeDir(dir) == nil) {
+			return SourceEnv
+		}
+		return SourceDefault
+	}
+	if _, ok := xdg.envDir(key); ok {
+		return SourceEnv
+	}
+	return SourceDefault
+}
+
+// dirsSource reports where the system directories of kind come from, as
+// dirsFor resolves them.
+func (xdg *XDG) dirsSource(kind Kind) Source {
+	if len(xdg.root) > 0 {
+		return SourceOption
+	}
+	key := configDirsKey
+	if kind == KindData {
+		key = dataDirsKey
+	}
+	if _, ok := xdg.envDirs(key); ok {
+		return SourceEnv
+	}
+	return SourceDefault
+}
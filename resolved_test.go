@@ -0,0 +1,66 @@
+package xdg
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tmp, "home"))
+	t.Setenv(configHomeKey, filepath.Join(tmp, "conf"))
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	writeTestFile(t, filepath.Join(tmp, "conf", "app", "a.toml"))
+	writeTestFile(t, filepath.Join(tmp, "etc", "app", "b.toml"))
+	x := New("app")
+
+	r, err := x.ResolveConfigFile("a.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, Resolved{Path: filepath.Join(tmp, "conf", "app", "a.toml"), Kind: KindConfig, Layer: 0, Source: SourceEnv}, r)
+	r, err = Resolve(KindConfig, "app", "b.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, Resolved{Path: filepath.Join(tmp, "etc", "app", "b.toml"), Kind: KindConfig, Layer: 1, Source: SourceEnv}, r)
+
+	if _, err = x.ResolveConfigFile("c.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+	if _, err = x.Resolve(Kind(0), "a.toml"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+
+	state := filepath.Join(x.State(), "s")
+	writeTestFile(t, state)
+	r, err = x.Resolve(KindState, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, Resolved{Path: state, Kind: KindState, Source: SourceDefault}, r)
+
+	rooted := New("app", WithRoot(filepath.Join(tmp, "root")))
+	writeTestFile(t, filepath.Join(rooted.Data(), "d"))
+	r, err = rooted.ResolveDataFile("d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, SourceOption, r.Source)
+	eq(t, "option", r.Source.String())
+
+	f, r, err := x.OpenFirst(KindConfig, "b.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, r.Path, string(b))
+}
@@ -0,0 +1,136 @@
+package xdg
+
+import (
+	"io/fs"
+	"iter"
+	"path/filepath"
+)
+
+// Resource is a copy of a file found on the data search path.
+type Resource struct {
+	Path string
+	// Layer is the position of the directory in the search path. Layer 0 is
+	// $XDG_DATA_HOME and layer n is the nth entry of $XDG_DATA_DIRS.
+	Layer int
+}
+
+// FindResource returns the copy of relPath that takes precedence for the
+// application. See (*XDG).FindResource.
+func FindResource(name, relPath string) (path string, layer int, err error) {
+	return newXdg(name).FindResource(relPath)
+}
+
+// FindResource returns the copy of relPath that takes precedence on the
+// data search path and the layer it was found in. The error wraps
+// fs.ErrNotExist if no copy exists.
+func (xdg *XDG) FindResource(relPath string) (path string, layer int, err error) {
+	res := find(xdg.dataSearchPath(), relPath, true)
+	if len(res) == 0 {
+		return "", -1, &fs.PathError{Op: "find", Path: relPath, Err: fs.ErrNotExist}
+	}
+	return res[0].Path, res[0].Layer, nil
+}
+
+// FindResources returns every copy of relPath on the data search path in
+// precedence order. The first entry is the one that wins, the rest are
+// shadowed by it.
+func (xdg *XDG) FindResources(relPath string) []Resource {
+	return find(xdg.dataSearchPath(), relPath, false)
+}
+
+// SearchConfigFile returns the path of the first file named file found in
+// the application's config directories. See (*XDG).SearchConfigFile.
+func SearchConfigFile(name, file string) (string, error) {
+	return newXdg(name).SearchConfigFile(file)
+}
+
+// SearchDataFile returns the path of the first file named file found in the
+// application's data directories. See (*XDG).SearchDataFile.
+func SearchDataFile(name, file string) (string, error) {
+	return newXdg(name).SearchDataFile(file)
+}
+
+// SearchConfigFile looks for file in $XDG_CONFIG_HOME/<name> followed by
+// each of $XDG_CONFIG_DIRS in order and returns the first path that exists.
+// The error wraps fs.ErrNotExist if the file is not found.
+func (xdg *XDG) SearchConfigFile(file string) (string, error) {
+	return xdg.lookups.search("config", xdg.configSearchPath, file)
+}
+
+// SearchDataFile looks for file in $XDG_DATA_HOME/<name> followed by each of
+// $XDG_DATA_DIRS in order and returns the first path that exists. The error
+// wraps fs.ErrNotExist if the file is not found.
+func (xdg *XDG) SearchDataFile(file string) (string, error) {
+	return xdg.lookups.search("data", xdg.dataSearchPath, file)
+}
+
+// SearchPathConfig yields the application's config directories in
+// precedence order. See (*XDG).SearchPathConfig.
+func SearchPathConfig(name string) iter.Seq[string] { return newXdg(name).SearchPathConfig() }
+
+// SearchPathData yields the application's data directories in precedence
+// order. See (*XDG).SearchPathData.
+func SearchPathData(name string) iter.Seq[string] { return newXdg(name).SearchPathData() }
+
+// SearchPathConfig yields $XDG_CONFIG_HOME/<name> followed by each of
+// $XDG_CONFIG_DIRS. The system directories are only resolved if iteration
+// continues past the first directory.
+func (xdg *XDG) SearchPathConfig() iter.Seq[string] {
+	return xdg.searchPath(xdg.Config, xdg.ConfigDirs)
+}
+
+// SearchPathData yields $XDG_DATA_HOME/<name> followed by each of
+// $XDG_DATA_DIRS. The system directories are only resolved if iteration
+// continues past the first directory.
+func (xdg *XDG) SearchPathData() iter.Seq[string] {
+	return xdg.searchPath(xdg.Data, xdg.DataDirs)
+}
+
+func (xdg *XDG) searchPath(home func() string, dirs func() []string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if h := home(); len(h) > 0 && !yield(h) {
+			return
+		}
+		for _, d := range dirs() {
+			if len(d) > 0 && !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+func (xdg *XDG) configSearchPath() []string {
+	return append([]string{xdg.Config()}, xdg.ConfigDirs()...)
+}
+
+func (xdg *XDG) dataSearchPath() []string {
+	return append([]string{xdg.Data()}, xdg.DataDirs()...)
+}
+
+func search(dirs []string, relPath string) (string, error) {
+	res := find(dirs, relPath, true)
+	if len(res) == 0 {
+		return "", &fs.PathError{Op: "search", Path: relPath, Err: fs.ErrNotExist}
+	}
+	return res[0].Path, nil
+}
+
+// find looks for relPath in each of dirs, where the index of a directory is
+// its layer. Empty directories are skipped but still occupy a layer.
+func find(dirs []string, relPath string, first bool) []Resource {
+	var res []Resource
+	for layer, dir := range dirs {
+		if len(dir) == 0 {
+			continue
+		}
+		p := filepath.Join(dir, relPath)
+		if !exists(p) {
+			continue
+		}
+		res = append(res, Resource{Path: p, Layer: layer})
+		if first {
+			break
+		}
+	}
+	return res
+}
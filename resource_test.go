@@ -0,0 +1,98 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindResource(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	sys1 := filepath.Join(root, "sys1")
+	sys2 := filepath.Join(root, "sys2")
+	t.Setenv(dataHomeKey, home)
+	t.Setenv(dataDirsKey, sys1+listSeparator+sys2)
+	for _, dir := range []string{sys1, sys2} {
+		writeTestFile(t, filepath.Join(dir, "myapp", "schema.json"))
+	}
+	x := newXdg("myapp")
+	p, layer, err := FindResource("myapp", "schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(sys1, "myapp", "schema.json"), p)
+	eq(t, 1, layer)
+
+	writeTestFile(t, filepath.Join(home, "myapp", "schema.json"))
+	_, layer, _ = x.FindResource("schema.json")
+	eq(t, 0, layer)
+	res := x.FindResources("schema.json")
+	eq(t, 3, len(res))
+	eq(t, 2, res[2].Layer)
+	eq(t, filepath.Join(sys2, "myapp", "schema.json"), res[2].Path)
+
+	_, layer, err = x.FindResource("missing.json")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	eq(t, -1, layer)
+}
+
+func TestSearchFile(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(configHomeKey, filepath.Join(root, "config"))
+	t.Setenv(configDirsKey, filepath.Join(root, "etc1")+listSeparator+filepath.Join(root, "etc2"))
+	t.Setenv(dataHomeKey, filepath.Join(root, "data"))
+	t.Setenv(dataDirsKey, filepath.Join(root, "share"))
+	writeTestFile(t, filepath.Join(root, "etc2", "app", "config.toml"))
+	writeTestFile(t, filepath.Join(root, "share", "app", "themes", "dark.css"))
+
+	p, err := SearchConfigFile("app", "config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(root, "etc2", "app", "config.toml"), p)
+	writeTestFile(t, filepath.Join(root, "etc1", "app", "config.toml"))
+	p, _ = SearchConfigFile("app", "config.toml")
+	eq(t, filepath.Join(root, "etc1", "app", "config.toml"), p)
+	writeTestFile(t, filepath.Join(root, "config", "app", "config.toml"))
+	p, _ = SearchConfigFile("app", "config.toml")
+	eq(t, filepath.Join(root, "config", "app", "config.toml"), p)
+
+	p, err = SearchDataFile("app", "themes/dark.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(root, "share", "app", "themes", "dark.css"), p)
+	_, err = SearchDataFile("app", "themes/light.css")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	unsetAll()
+	t.Setenv(configHomeKey, "/home/t/.config")
+	t.Setenv(configDirsKey, "/etc/xdg:/opt/etc")
+	t.Setenv(dataHomeKey, "/home/t/.local/share")
+	t.Setenv(dataDirsKey, "/usr/share")
+
+	var dirs []string
+	for d := range SearchPathConfig("app") {
+		dirs = append(dirs, d)
+	}
+	arrEq(t, []string{"/home/t/.config/app", "/etc/xdg/app", "/opt/etc/app"}, dirs)
+
+	dirs = dirs[:0]
+	for d := range SearchPathConfig("app") {
+		dirs = append(dirs, d)
+		break
+	}
+	arrEq(t, []string{"/home/t/.config/app"}, dirs)
+
+	dirs = slices.Collect(SearchPathData("app"))
+	arrEq(t, []string{"/home/t/.local/share/app", "/usr/share/app"}, dirs)
+}
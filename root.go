@@ -0,0 +1,50 @@
+package xdg
+
+import (
+	"path/filepath"
+)
+
+// rootRuntimeBase is where the runtime directory goes under a root. The spec
+// has no default for it so one is made up that sits next to the others.
+const rootRuntimeBase = ".local/run"
+
+// WithRoot places every directory under root, as if root were the home
+// directory and the system directories were installed under it:
+//
+//	<root>/.config/<name>
+//	<root>/.local/share/<name>
+//	<root>/.local/state/<name>
+//	<root>/.cache/<name>
+//	<root>/.local/run/<name>
+//	<root>/etc/xdg/<name>
+//	<root>/usr/local/share/<name>, <root>/usr/share/<name>
+//
+// The XDG environment variables and the user overrides file are ignored, so
+// resolution depends on nothing outside of root. This is useful for
+// per-project tool state and for test sandboxes.
+func WithRoot(root string) Option {
+	return func(xdg *XDG) { xdg.root = root }
+}
+
+func (xdg *XDG) rootedDir(key string) string {
+	base := rootedBase(xdg.root, key)
+	if len(base) == 0 {
+		return filepath.Join(xdg.root, "."+xdg.finder.Name())
+	}
+	return filepath.Join(base, xdg.finder.Name())
+}
+
+func (xdg *XDG) rootedDirs(key, name string) []string {
+	paths := defaultDirs(key)
+	for i := range paths {
+		paths[i] = filepath.Join(xdg.root, paths[i], name)
+	}
+	return paths
+}
+
+func rootedBase(root, key string) string {
+	if key == runtimeDirKey {
+		return filepath.Join(root, rootRuntimeBase)
+	}
+	return defaultBase(root, key)
+}
@@ -0,0 +1,32 @@
+package xdg
+
+import "testing"
+
+func TestWithRoot(t *testing.T) {
+	t.Setenv(configHomeKey, "/ignored/config")
+	t.Setenv(runtimeDirKey, "/ignored/run")
+	t.Setenv(dataDirsKey, "/ignored/data")
+	x := NewXDG(NewDirFinder("tool"), WithRoot("/src/project/.tool"))
+	eq(t, "/src/project/.tool/.config/tool", x.Config())
+	eq(t, "/src/project/.tool/.local/share/tool", x.Data())
+	eq(t, "/src/project/.tool/.local/state/tool", x.State())
+	eq(t, "/src/project/.tool/.cache/tool", x.Cache())
+	eq(t, "/src/project/.tool/.local/run/tool", x.Runtime())
+	eq(t, "/src/project/.tool/.tool", x.getDir("unknown"))
+	arrEq(t, []string{"/src/project/.tool/etc/xdg/tool"}, x.ConfigDirs())
+	arrEq(t, []string{
+		"/src/project/.tool/usr/local/share/tool",
+		"/src/project/.tool/usr/share/tool",
+	}, x.DataDirs())
+	arrEq(t, nil, x.getDirs("unknown"))
+	eq(t, "/src/project/.tool/.config", x.baseDir(configHomeKey))
+	if x.Overrides() != nil {
+		t.Error("rooted instances should ignore overrides")
+	}
+}
+
+func TestDefaultDirs(t *testing.T) {
+	arrEq(t, []string{"/usr/local/share/", "/usr/share/"}, defaultDirs(dataDirsKey))
+	arrEq(t, []string{"/etc/xdg"}, defaultDirs(configDirsKey))
+	arrEq(t, nil, defaultDirs("unknown"))
+}
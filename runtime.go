@@ -0,0 +1,172 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RuntimeSubdir creates a nested directory under $XDG_RUNTIME_DIR/app. See
+// (*XDG).RuntimeSubdir.
+func RuntimeSubdir(app string, parts ...string) (Dir, error) {
+	return newXdg(app).RuntimeSubdir(parts...)
+}
+
+// RuntimeSubdir creates the directory $XDG_RUNTIME_DIR/<app>/<parts...>.
+// Every level below $XDG_RUNTIME_DIR is created with mode 0700. Levels that
+// already exist must be directories owned by the current user and are
+// tightened to 0700 if their mode is looser.
+func (xdg *XDG) RuntimeSubdir(parts ...string) (Dir, error) {
+	base := xdg.baseDir(runtimeDirKey)
+	levels := append(nameParts(xdg.finder.Name()), parts...)
+	if p, ok := xdg.systemdDir(runtimeDirKey); ok {
+		// systemd already created the service's directory.
+		base, levels = p, parts
+	}
+	if len(base) == 0 {
+		return "", ErrRuntimeDirUnset
+	}
+	dir := base
+	for _, p := range levels {
+		if len(p) == 0 || p == "." || p == ".." || strings.ContainsAny(p, `/\`) {
+			return "", fmt.Errorf("%w: runtime subdirectory %q", ErrInvalidPath, p)
+		}
+		dir = filepath.Join(dir, p)
+		if err := ensurePrivateDir(dir); err != nil {
+			return "", err
+		}
+	}
+	return Dir(dir), nil
+}
+
+func ensurePrivateDir(dir string) error {
+	err := os.Mkdir(longPath(dir), 0700)
+	audit(OpMkdir, dir, 0, 0700|fs.ModeDir, err)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	info, err := os.Lstat(longPath(dir))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s", ErrNotDirectory, dir)
+	}
+	if err = checkOwner(dir, info); err != nil {
+		return err
+	}
+	if info.Mode().Perm() != 0700 {
+		err = os.Chmod(longPath(dir), 0700)
+		audit(OpChmod, dir, 0, 0700|fs.ModeDir, err)
+	}
+	return err
+}
+
+var (
+	cleanupMu   sync.Mutex
+	cleanupDirs []Dir
+)
+
+// CleanupAtExit registers d to be removed, with everything in it, when
+// Cleanup is called. Go has no exit hooks, so programs should defer Cleanup
+// in main.
+func CleanupAtExit(d Dir) {
+	cleanupMu.Lock()
+	cleanupDirs = append(cleanupDirs, d)
+	cleanupMu.Unlock()
+}
+
+// Cleanup removes every directory registered with CleanupAtExit, most
+// recently registered first.
+func Cleanup() error {
+	cleanupMu.Lock()
+	dirs := cleanupDirs
+	cleanupDirs = nil
+	cleanupMu.Unlock()
+	var errs []error
+	for i := len(dirs) - 1; i >= 0; i-- {
+		err := os.RemoveAll(longPath(string(dirs[i])))
+		audit(OpRemove, string(dirs[i]), 0, 0, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithRuntimeFallback makes the runtime directory fall back to
+// FallbackRuntimeDir when $XDG_RUNTIME_DIR is unset or fails
+// ValidateRuntimeDir, as the spec recommends. The fallback directory is
+// created on demand. If warn is not nil it is called with the reason every
+// time the fallback is used.
+func WithRuntimeFallback(warn func(error)) Option {
+	return func(xdg *XDG) {
+		xdg.runtimeFallback = true
+		xdg.runtimeWarn = warn
+	}
+}
+
+// FallbackRuntimeDir returns a per-user replacement for $XDG_RUNTIME_DIR
+// inside os.TempDir.
+func FallbackRuntimeDir() string {
+	id := strconv.Itoa(os.Getuid())
+	if id == "-1" {
+		id = os.Getenv("USERNAME")
+	}
+	return filepath.Join(os.TempDir(), "xdg-runtime-"+id)
+}
+
+// ValidateRuntimeDir checks that dir meets the requirements the spec places
+// on $XDG_RUNTIME_DIR: it must be a directory owned by the current user with
+// access restricted to that user (mode 0700).
+func ValidateRuntimeDir(dir string) error {
+	info, err := os.Lstat(longPath(dir))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: runtime dir %s", ErrNotDirectory, dir)
+	}
+	if err = checkOwner(dir, info); err != nil {
+		return err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		return fmt.Errorf("%w: runtime dir %s has mode %#o, expected 0700", ErrInsecurePermissions, dir, info.Mode().Perm())
+	}
+	return nil
+}
+
+// runtimeBase returns the base runtime directory, applying the fallback if
+// it was enabled.
+func (xdg *XDG) runtimeBase() (string, error) {
+	dir, ok := xdg.envDir(runtimeDirKey)
+	if !xdg.runtimeFallback {
+		if !ok {
+			return "", ErrRuntimeDirUnset
+		}
+		return dir, nil
+	}
+	reason := ErrRuntimeDirUnset
+	if ok {
+		if reason = ValidateRuntimeDir(dir); reason == nil {
+			return dir, nil
+		}
+	}
+	fallback := FallbackRuntimeDir()
+	if err := ensurePrivateDir(fallback); err != nil {
+		return "", fmt.Errorf("%w: fallback %s: %w", reason, fallback, err)
+	}
+	if xdg.runtimeWarn != nil {
+		xdg.runtimeWarn(fmt.Errorf("%w, using %s", reason, fallback))
+	}
+	return fallback, nil
+}
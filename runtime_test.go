@@ -0,0 +1,112 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuntimeSubdir(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv(runtimeDirKey, base)
+	d, err := RuntimeSubdir("myapp", "sockets", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(base, "myapp", "sockets", "v1"), string(d))
+	for _, p := range []string{"myapp", "myapp/sockets", "myapp/sockets/v1"} {
+		info, err := os.Stat(filepath.Join(base, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, os.FileMode(0700), info.Mode().Perm())
+	}
+
+	// existing directories are tightened
+	if err = os.Chmod(filepath.Join(base, "myapp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RuntimeSubdir("myapp", "sockets"); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(filepath.Join(base, "myapp"))
+	eq(t, os.FileMode(0700), info.Mode().Perm())
+
+	for _, bad := range []string{"..", "a/b", ""} {
+		if _, err = RuntimeSubdir("myapp", bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if err = os.WriteFile(filepath.Join(base, "myapp", "file"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RuntimeSubdir("myapp", "file"); err == nil {
+		t.Error("expected error for a file in the way")
+	}
+
+	CleanupAtExit(d)
+	if err = Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Exists() {
+		t.Error("directory should have been cleaned up")
+	}
+
+	os.Unsetenv(runtimeDirKey)
+	if _, err = RuntimeSubdir("myapp"); err == nil {
+		t.Error("expected error without XDG_RUNTIME_DIR")
+	}
+}
+
+func TestRuntimeFallback(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	os.Unsetenv(runtimeDirKey)
+	var warnings []error
+	x := New("myapp", WithRuntimeFallback(func(err error) { warnings = append(warnings, err) }))
+	dir, err := x.RuntimeE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(FallbackRuntimeDir(), "myapp"), dir)
+	eq(t, tmp, filepath.Dir(FallbackRuntimeDir()))
+	eq(t, 1, len(warnings))
+	if !errors.Is(warnings[0], ErrRuntimeDirUnset) {
+		t.Errorf("wrong warning %v", warnings[0])
+	}
+	if err = ValidateRuntimeDir(FallbackRuntimeDir()); err != nil {
+		t.Error(err)
+	}
+
+	// a valid runtime dir is used as is
+	valid := filepath.Join(tmp, "valid")
+	if err = os.Mkdir(valid, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(runtimeDirKey, valid)
+	eq(t, filepath.Join(valid, "myapp"), x.Runtime())
+	eq(t, 1, len(warnings))
+
+	// an insecure one is replaced
+	if err = os.Chmod(valid, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ValidateRuntimeDir(valid); !errors.Is(err, ErrInsecurePermissions) {
+		t.Errorf("expected insecure permissions, got %v", err)
+	}
+	eq(t, filepath.Join(FallbackRuntimeDir(), "myapp"), x.Runtime())
+	eq(t, 2, len(warnings))
+
+	// without the option nothing is validated
+	eq(t, filepath.Join(valid, "myapp"), Runtime("myapp"))
+
+	if err = ValidateRuntimeDir(filepath.Join(tmp, "missing")); err == nil {
+		t.Error("expected error for missing dir")
+	}
+	file := filepath.Join(tmp, "file")
+	_ = os.WriteFile(file, nil, 0600)
+	if err = ValidateRuntimeDir(file); err == nil {
+		t.Error("expected error for a file")
+	}
+}
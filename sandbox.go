@@ -0,0 +1,83 @@
+package xdg
+
+import (
+	"path/filepath"
+
+	"github.com/harrybrwn/xdg/internal/sandbox"
+)
+
+// Sandbox kinds reported by SandboxInfo.
+const (
+	SandboxFlatpak = sandbox.Flatpak
+	SandboxSnap    = sandbox.Snap
+)
+
+// Sandbox describes the application sandbox the process runs in.
+type Sandbox struct {
+	// Kind is SandboxFlatpak, SandboxSnap, or empty when not sandboxed.
+	Kind string
+	// ID is the flatpak application ID or the snap name.
+	ID string
+	// UserData is the per-user directory the sandbox keeps the application's
+	// files in: ~/.var/app/<id> for flatpak and $SNAP_USER_DATA for snap.
+	UserData string
+	// UserCommon is $SNAP_USER_COMMON, the snap user directory shared by
+	// every revision. It is empty for flatpak.
+	UserCommon string
+}
+
+// SandboxInfo reports whether the process runs inside a Flatpak or Snap
+// sandbox. See (*XDG).SandboxInfo.
+func SandboxInfo() Sandbox { return newXdg("").SandboxInfo() }
+
+// SandboxInfo reports whether the process runs inside a Flatpak sandbox,
+// detected from $FLATPAK_ID or /.flatpak-info, or a Snap, detected from
+// $SNAP_NAME. /.flatpak-info is read once per process. Both normally export
+// XDG variables pointing inside the sandbox, but when they are missing the
+// default directories are placed under UserData instead of the home
+// directory.
+func (xdg *XDG) SandboxInfo() Sandbox {
+	if xdg.goos != "linux" {
+		return Sandbox{}
+	}
+	// The info file describes the current process only.
+	kind, id := sandbox.Detect(xdg.lookupEnv, xdg.environ == nil)
+	switch kind {
+	case sandbox.Flatpak:
+		sb := Sandbox{Kind: SandboxFlatpak, ID: id}
+		if home, err := xdg.Home(); err == nil && len(id) > 0 {
+			sb.UserData = filepath.Join(home, ".var", "app", id)
+		}
+		return sb
+	case sandbox.Snap:
+		// These are paths set by the sandbox itself, so they are read as is
+		// rather than expanded like the XDG variables.
+		sb := Sandbox{Kind: SandboxSnap, ID: id}
+		sb.UserData, _ = xdg.lookupEnv("SNAP_USER_DATA")
+		sb.UserCommon, _ = xdg.lookupEnv("SNAP_USER_COMMON")
+		return sb
+	}
+	return Sandbox{}
+}
+
+// base returns the default base directory for key inside a sandbox,
+// mirroring the variables flatpak and snap set up.
+func (sb Sandbox) base(key string) string {
+	if len(sb.UserData) == 0 {
+		return ""
+	}
+	if sb.Kind == SandboxSnap {
+		return defaultBase(sb.UserData, key)
+	}
+	switch key {
+	case configHomeKey:
+		return filepath.Join(sb.UserData, "config")
+	case dataHomeKey:
+		return filepath.Join(sb.UserData, "data")
+	case cacheHomeKey:
+		return filepath.Join(sb.UserData, "cache")
+	case stateHomeKey:
+		return filepath.Join(sb.UserData, ".local", "state")
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package xdg
+
+import "testing"
+
+func TestSandboxInfo(t *testing.T) {
+	env := map[string]string{"HOME": "/home/t"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	x := New("app", WithEnviron(lookup), WithGOOS("linux"))
+	eq(t, Sandbox{}, x.SandboxInfo())
+	eq(t, "/home/t/.config/app", x.Config())
+
+	env["FLATPAK_ID"] = "org.example.App"
+	eq(t, Sandbox{Kind: SandboxFlatpak, ID: "org.example.App", UserData: "/home/t/.var/app/org.example.App"}, x.SandboxInfo())
+	eq(t, "/home/t/.var/app/org.example.App/config/app", x.Config())
+	eq(t, "/home/t/.var/app/org.example.App/data/app", x.Data())
+	eq(t, "/home/t/.var/app/org.example.App/cache/app", x.Cache())
+	eq(t, "/home/t/.var/app/org.example.App/.local/state/app", x.State())
+	env[configHomeKey] = "/custom"
+	eq(t, "/custom/app", x.Config())
+	delete(env, configHomeKey)
+
+	delete(env, "FLATPAK_ID")
+	env["SNAP_NAME"] = "tool"
+	env["SNAP_USER_DATA"] = "/home/t/snap/tool/42"
+	env["SNAP_USER_COMMON"] = "/home/t/snap/tool/common"
+	eq(t, Sandbox{Kind: SandboxSnap, ID: "tool", UserData: "/home/t/snap/tool/42", UserCommon: "/home/t/snap/tool/common"}, x.SandboxInfo())
+	eq(t, "/home/t/snap/tool/42/.config/app", x.Config())
+	eq(t, "/home/t/snap/tool/42/.local/share/app", x.Data())
+
+	// Sandbox variables are not expanded like the XDG ones.
+	env["SNAP_USER_COMMON"] = "/home/t/snap/$rev"
+	eq(t, "/home/t/snap/$rev", x.SandboxInfo().UserCommon)
+	env["SNAP_NAME"] = "~tool"
+	eq(t, "~tool", x.SandboxInfo().ID)
+
+	x = New("app", WithEnviron(lookup), WithGOOS("darwin"))
+	eq(t, Sandbox{}, x.SandboxInfo())
+}
@@ -0,0 +1,128 @@
+package xdg
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// defaultSearchWorkers bounds the concurrent lookups of the Context search
+// functions unless WithSearchWorkers is used.
+const defaultSearchWorkers = 8
+
+// WithSearchWorkers sets how many directories SearchDataFileContext and
+// FindResourcesContext probe at once.
+func WithSearchWorkers(n int) Option {
+	return func(xdg *XDG) { xdg.searchWorkers = n }
+}
+
+// SearchDataFileContext is SearchDataFile for the application name with
+// the directories probed concurrently. See (*XDG).SearchDataFileContext.
+func SearchDataFileContext(ctx context.Context, name, file string) (string, error) {
+	return newXdg(name).SearchDataFileContext(ctx, file)
+}
+
+// SearchDataFileContext is like SearchDataFile but probes the directories
+// of the data search path concurrently, for systems where $XDG_DATA_DIRS
+// holds slow network mounts. The result is the same as SearchDataFile's:
+// the first directory in precedence order that has the file wins, no matter
+// which lookup finishes first. It returns ctx.Err() if ctx is done first,
+// without waiting for the lookups still in flight.
+func (xdg *XDG) SearchDataFileContext(ctx context.Context, file string) (string, error) {
+	res, err := xdg.findParallel(ctx, xdg.dataSearchPath(), file, true)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", &fs.PathError{Op: "search", Path: file, Err: fs.ErrNotExist}
+	}
+	return res[0].Path, nil
+}
+
+// FindResourcesContext is like FindResources but probes the directories of
+// the data search path concurrently. The copies are returned in precedence
+// order.
+func (xdg *XDG) FindResourcesContext(ctx context.Context, relPath string) ([]Resource, error) {
+	return xdg.findParallel(ctx, xdg.dataSearchPath(), relPath, false)
+}
+
+// findParallel is find with a bounded pool of workers. Results are
+// collected in layer order, so with first set it returns as soon as every
+// layer before the first hit has been ruled out.
+func (xdg *XDG) findParallel(ctx context.Context, dirs []string, relPath string, first bool) ([]Resource, error) {
+	workers := xdg.searchWorkers
+	if workers <= 0 {
+		workers = defaultSearchWorkers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		layer int
+		found bool
+	}
+	const (
+		unknown = iota
+		found
+		missing
+	)
+	var (
+		state = make([]int, len(dirs))
+		todo  []int
+		jobs  = make(chan int)
+		// Buffered so workers never block once we stop listening.
+		results = make(chan result, len(dirs))
+	)
+	for i, d := range dirs {
+		if len(d) == 0 {
+			state[i] = missing
+		} else {
+			todo = append(todo, i)
+		}
+	}
+	pending := len(todo)
+	for w := 0; w < min(workers, pending); w++ {
+		go func() {
+			for i := range jobs {
+				results <- result{i, exists(filepath.Join(dirs[i], relPath))}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, i := range todo {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		res  []Resource
+		next int
+	)
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case r := <-results:
+			pending--
+			state[r.layer] = missing
+			if r.found {
+				state[r.layer] = found
+			}
+		}
+		for ; next < len(dirs) && state[next] != unknown; next++ {
+			if state[next] != found {
+				continue
+			}
+			res = append(res, Resource{Path: filepath.Join(dirs[next], relPath), Layer: next})
+			if first {
+				return res, nil
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowFS delays Stat calls for paths below slow until release is closed.
+type slowFS struct {
+	FileSystem
+	slow    string
+	release chan struct{}
+}
+
+func (f slowFS) Stat(name string) (fs.FileInfo, error) {
+	if strings.HasPrefix(name, f.slow) {
+		<-f.release
+	}
+	return f.FileSystem.Stat(name)
+}
+
+func TestSearchDataFileContext(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	dirs := []string{filepath.Join(tmp, "slow"), filepath.Join(tmp, "a"), filepath.Join(tmp, "b")}
+	t.Setenv(dataDirsKey, strings.Join(dirs, string(os.PathListSeparator)))
+	for _, d := range dirs {
+		writeTestFile(t, filepath.Join(d, "app", "icons", "x.png"))
+	}
+	if err := os.Remove(filepath.Join(dirs[0], "app", "icons", "x.png")); err != nil {
+		t.Fatal(err)
+	}
+	fsys := slowFS{FileSystem: OSFileSystem(), slow: dirs[0], release: make(chan struct{})}
+	SetFileSystem(fsys)
+	defer SetFileSystem(nil)
+	x := New("app", WithSearchWorkers(2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := x.SearchDataFileContext(ctx, "icons/x.png"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the search to wait for the slow layer, got %v", err)
+	}
+
+	done := make(chan struct{})
+	var (
+		path string
+		err  error
+	)
+	go func() {
+		defer close(done)
+		path, err = x.SearchDataFileContext(context.Background(), "icons/x.png")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(fsys.release)
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(dirs[1], "app", "icons", "x.png"), path)
+
+	res, err := x.FindResourcesContext(context.Background(), "icons/x.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := x.FindResources("icons/x.png")
+	eq(t, len(want), len(res))
+	for i := range want {
+		eq(t, want[i], res[i])
+	}
+	if _, err = SearchDataFileContext(context.Background(), "app", "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
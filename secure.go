@@ -0,0 +1,84 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// EnsureSecure checks that d and everything in it is owned by the current
+// user and not writable by the group or others. Write bits it finds are
+// cleared; files owned by someone else cannot be fixed and are reported with
+// ErrInsecurePermissions. Symbolic links are not followed. On Windows, where
+// the mode bits do not describe access, only existence is checked.
+func (d Dir) EnsureSecure() error { return d.ensureMode(0022, true) }
+
+// EnsurePrivate is like EnsureSecure but also clears the bits that let the
+// group or others read d and its contents. Use it for directories holding
+// tokens, keys or other secrets.
+func (d Dir) EnsurePrivate() error { return d.ensureMode(0077, true) }
+
+// CheckSecure is like EnsureSecure but only reports the entries that are
+// writable by the group or others with ErrInsecurePermissions, leaving
+// their modes unchanged, so an application can refuse to run instead.
+func (d Dir) CheckSecure() error { return d.ensureMode(0022, false) }
+
+// CheckPrivate is like EnsurePrivate but only reports the entries that are
+// readable or writable by the group or others, leaving their modes
+// unchanged.
+func (d Dir) CheckPrivate() error { return d.ensureMode(0077, false) }
+
+// ensureMode clears the mask bits from every entry below d, or only reports
+// them when fix is false, and reports the entries owned by another user.
+func (d Dir) ensureMode(mask fs.FileMode, fix bool) error {
+	root := string(d)
+	info, err := os.Lstat(longPath(root))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s", ErrNotDirectory, root)
+	}
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	var errs []error
+	err = filepath.WalkDir(root, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if e.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if err = checkOwner(path, info); err != nil {
+			errs = append(errs, err)
+			if e.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if perm := info.Mode().Perm(); perm&mask != 0 && !fix {
+			errs = append(errs, fmt.Errorf("%w: %s has mode %#o", ErrInsecurePermissions, path, perm))
+		} else if perm&mask != 0 {
+			err = os.Chmod(longPath(path), perm&^mask)
+			audit(OpChmod, path, 0, (perm&^mask)|info.Mode().Type(), err)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s has mode %#o: %w", ErrInsecurePermissions, path, perm, err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
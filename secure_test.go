@@ -0,0 +1,73 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEnsureSecure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions on windows")
+	}
+	tmp := t.TempDir()
+	d := Dir(filepath.Join(tmp, "app"))
+	sub := filepath.Join(string(d), "sub")
+	token := filepath.Join(sub, "token")
+	writeTestFile(t, token)
+	for path, perm := range map[string]os.FileMode{string(d): 0777, sub: 0775, token: 0666} {
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/", filepath.Join(string(d), "link")); err != nil {
+		t.Fatal(err)
+	}
+	mode := func(path string) os.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	err := d.CheckSecure()
+	if !errors.Is(err, ErrInsecurePermissions) || !strings.Contains(err.Error(), token) {
+		t.Errorf("expected the loose token to be reported, got %v", err)
+	}
+	eq(t, os.FileMode(0777), mode(string(d)))
+	eq(t, os.FileMode(0666), mode(token))
+
+	if err := d.EnsureSecure(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.CheckSecure(); err != nil {
+		t.Errorf("expected a secure directory, got %v", err)
+	}
+	if err := d.CheckPrivate(); !errors.Is(err, ErrInsecurePermissions) {
+		t.Errorf("expected readable entries to be reported, got %v", err)
+	}
+	eq(t, os.FileMode(0755), mode(string(d)))
+	eq(t, os.FileMode(0755), mode(sub))
+	eq(t, os.FileMode(0644), mode(token))
+	if err := d.EnsurePrivate(); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, os.FileMode(0700), mode(string(d)))
+	eq(t, os.FileMode(0700), mode(sub))
+	eq(t, os.FileMode(0600), mode(token))
+	if err := d.CheckPrivate(); err != nil {
+		t.Errorf("expected a private directory, got %v", err)
+	}
+
+	if err := Dir(token).EnsureSecure(); err == nil {
+		t.Error("expected an error for a file")
+	}
+	if err := Dir(filepath.Join(tmp, "missing")).EnsureSecure(); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
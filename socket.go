@@ -0,0 +1,66 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// ListenRuntimeSocket listens on a unix socket in the runtime directory of
+// the application name. See (*XDG).ListenSocket.
+func ListenRuntimeSocket(name, socket string) (net.Listener, error) {
+	return newXdg(name).ListenSocket(socket)
+}
+
+// ListenSocket listens on the unix socket $XDG_RUNTIME_DIR/<name>/<socket>,
+// creating the directory with mode 0700. A stale socket left behind by a
+// process that has exited is removed first, but a socket that still accepts
+// connections is an error. The socket file is removed when the listener is
+// closed.
+func (xdg *XDG) ListenSocket(socket string) (net.Listener, error) {
+	if !filepath.IsLocal(socket) || filepath.Base(socket) != socket {
+		return nil, fmt.Errorf("%w: socket %q", ErrInvalidPath, socket)
+	}
+	path, err := xdg.runtimeFile(socket)
+	if err != nil {
+		return nil, err
+	}
+	if err = removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	audit(OpWrite, path, 0, fs.ModeSocket|0600, err)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, 0600); err != nil && runtime.GOOS != "windows" {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("xdg: %s exists and is not a socket", path)
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%w: socket %s is in use", ErrLocked, path)
+	}
+	err = os.Remove(path)
+	audit(OpRemove, path, 0, 0, err)
+	return err
+}
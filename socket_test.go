@@ -0,0 +1,53 @@
+package xdg
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenSocket(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv(runtimeDirKey, base)
+	path := filepath.Join(base, "app", "ctl.sock")
+
+	l, err := ListenRuntimeSocket("app", "ctl.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, path, l.Addr().String())
+	if _, err = ListenRuntimeSocket("app", "ctl.sock"); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked for a live socket, got %v", err)
+	}
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if exists(path) {
+		t.Error("socket should be removed on close")
+	}
+
+	// Leave a stale socket behind.
+	ul, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ul.SetUnlinkOnClose(false)
+	ul.Close()
+	if !exists(path) {
+		t.Fatal("expected stale socket")
+	}
+	l, err = ListenRuntimeSocket("app", "ctl.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	writeTestFile(t, filepath.Join(base, "app", "file"))
+	if _, err = ListenRuntimeSocket("app", "file"); err == nil {
+		t.Error("expected error for a regular file")
+	}
+	if _, err = ListenRuntimeSocket("app", "../x.sock"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
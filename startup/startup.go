@@ -0,0 +1,82 @@
+// Package startup consumes and forwards the tokens used by the Startup
+// Notification Protocol on X11 and the xdg-activation protocol on Wayland,
+// so windows opened by launched applications receive focus.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/startup-notification-spec/latest/
+//	https://wayland.app/protocols/xdg-activation-v1
+package startup
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// StartupIDKey is the X11 startup notification variable.
+	StartupIDKey = "DESKTOP_STARTUP_ID"
+	// ActivationTokenKey is the Wayland activation token variable.
+	ActivationTokenKey = "XDG_ACTIVATION_TOKEN"
+)
+
+// Token holds the startup identifiers handed to a launched application.
+type Token struct {
+	StartupID       string
+	ActivationToken string
+}
+
+// FromString returns a token that forwards the same value under both
+// variables, which is what launchers do when they receive a single token
+// from the compositor.
+func FromString(token string) Token {
+	return Token{StartupID: token, ActivationToken: token}
+}
+
+// Consume reads the tokens from the environment and unsets them so they are
+// not inherited by child processes, as both protocols require.
+func Consume() Token {
+	t := Token{
+		StartupID:       os.Getenv(StartupIDKey),
+		ActivationToken: os.Getenv(ActivationTokenKey),
+	}
+	os.Unsetenv(StartupIDKey)
+	os.Unsetenv(ActivationTokenKey)
+	return t
+}
+
+// IsZero reports whether the token is empty.
+func (t Token) IsZero() bool {
+	return len(t.StartupID) == 0 && len(t.ActivationToken) == 0
+}
+
+// Env returns the token as KEY=value pairs. Empty values are omitted.
+func (t Token) Env() []string {
+	var env []string
+	if len(t.StartupID) > 0 {
+		env = append(env, StartupIDKey+"="+t.StartupID)
+	}
+	if len(t.ActivationToken) > 0 {
+		env = append(env, ActivationTokenKey+"="+t.ActivationToken)
+	}
+	return env
+}
+
+// Apply sets the token in the environment of cmd. Any inherited token
+// variables are removed first so stale values are never forwarded. If
+// cmd.Env is nil the current process environment is used as the base.
+func (t Token) Apply(cmd *exec.Cmd) {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	out := make([]string, 0, len(env)+2)
+	for _, kv := range env {
+		if strings.HasPrefix(kv, StartupIDKey+"=") || strings.HasPrefix(kv, ActivationTokenKey+"=") {
+			continue
+		}
+		out = append(out, kv)
+	}
+	cmd.Env = append(out, t.Env()...)
+}
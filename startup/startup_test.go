@@ -0,0 +1,45 @@
+package startup
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestConsume(t *testing.T) {
+	t.Setenv(StartupIDKey, "x11-id")
+	t.Setenv(ActivationTokenKey, "wl-token")
+	tok := Consume()
+	if tok != (Token{"x11-id", "wl-token"}) {
+		t.Errorf("wrong token %#v", tok)
+	}
+	if _, ok := os.LookupEnv(StartupIDKey); ok {
+		t.Error("startup id should be unset")
+	}
+	if _, ok := os.LookupEnv(ActivationTokenKey); ok {
+		t.Error("activation token should be unset")
+	}
+	if !Consume().IsZero() {
+		t.Error("second consume should be empty")
+	}
+}
+
+func TestApply(t *testing.T) {
+	cmd := exec.Command("true")
+	cmd.Env = []string{"A=1", StartupIDKey + "=stale", ActivationTokenKey + "=stale"}
+	FromString("tok").Apply(cmd)
+	want := []string{"A=1", StartupIDKey + "=tok", ActivationTokenKey + "=tok"}
+	if !reflect.DeepEqual(cmd.Env, want) {
+		t.Errorf("got %v, want %v", cmd.Env, want)
+	}
+	Token{}.Apply(cmd)
+	if !reflect.DeepEqual(cmd.Env, []string{"A=1"}) {
+		t.Errorf("empty token should remove variables, got %v", cmd.Env)
+	}
+	cmd = exec.Command("true")
+	Token{ActivationToken: "wl"}.Apply(cmd)
+	if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != ActivationTokenKey+"=wl" {
+		t.Errorf("expected inherited environment plus token, got %v", cmd.Env)
+	}
+}
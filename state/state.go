@@ -0,0 +1,152 @@
+// Package state is a small persistent key-value store kept in the
+// application's $XDG_STATE_HOME directory, for things like the last window
+// size or the time of the last update check.
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// FileName is the name of the file the store is saved to.
+const FileName = "state.json"
+
+// ErrNotFound is returned by GetJSON when a key is not in the store.
+var ErrNotFound = errors.New("state: key not found")
+
+// Store holds string and JSON values. It is safe for concurrent use.
+type Store struct {
+	dir xdg.Dir
+	// dirMode is what dir is created with when the store is first saved.
+	dirMode fs.FileMode
+
+	mu     sync.Mutex
+	values map[string]json.RawMessage
+}
+
+// Open loads the store of the application name from $XDG_STATE_HOME/<name>.
+func Open(name string, opts ...xdg.Option) (*Store, error) {
+	x := xdg.New(name, opts...)
+	dir, err := x.StateE()
+	if err != nil {
+		return nil, err
+	}
+	s, err := OpenDir(xdg.Dir(dir))
+	if s != nil {
+		s.dirMode = x.DirMode(xdg.KindState)
+	}
+	return s, err
+}
+
+// OpenDir loads the store saved in dir. A missing file is an empty store.
+// If dir does not exist it is created with mode 0700 when the store is
+// first saved.
+func OpenDir(dir xdg.Dir) (*Store, error) {
+	s := &Store{dir: dir, dirMode: 0700, values: make(map[string]json.RawMessage)}
+	data, err := dir.ReadFile(FileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &s.values); err != nil {
+		return nil, fmt.Errorf("state: %s: %w", dir.Append(FileName), err)
+	}
+	if s.values == nil {
+		// The file held a JSON null.
+		s.values = make(map[string]json.RawMessage)
+	}
+	return s, nil
+}
+
+// Path returns the path of the file the store is saved to.
+func (s *Store) Path() string { return s.dir.Append(FileName).String() }
+
+// Keys returns the sorted keys in the store.
+func (s *Store) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the string stored under key. Values saved with SetJSON that
+// are not strings are returned as JSON text.
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.values[key]
+	if !ok {
+		return "", false
+	}
+	var v string
+	if err := json.Unmarshal(raw, &v); err == nil {
+		return v, true
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return string(raw), true
+	}
+	return b.String(), true
+}
+
+// GetJSON decodes the value stored under key into v. The error is
+// ErrNotFound if there is no such key.
+func (s *Store) GetJSON(key string, v any) error {
+	s.mu.Lock()
+	raw, ok := s.values[key]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Set stores a string under key and saves the store.
+func (s *Store) Set(key, value string) error { return s.SetJSON(key, value) }
+
+// SetJSON stores the JSON encoding of v under key and saves the store.
+func (s *Store) SetJSON(key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = raw
+	return s.save()
+}
+
+// Delete removes key and saves the store. It is not an error if the key
+// does not exist.
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; !ok {
+		return nil
+	}
+	delete(s.values, key)
+	return s.save()
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.values, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = s.dir.CreateMode(s.dirMode); err != nil {
+		return err
+	}
+	return s.dir.WriteFileAtomic(FileName, append(data, '\n'), 0600)
+}
@@ -0,0 +1,115 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestStore(t *testing.T) {
+	x := xdgtest.SandboxApp(t, "app")
+	s, err := Open("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Path() != filepath.Join(x.State(), FileName) {
+		t.Errorf("wrong path %q", s.Path())
+	}
+	if _, ok := s.Get("missing"); ok {
+		t.Error("expected missing key")
+	}
+	type size struct{ W, H int }
+	if err = s.Set("last-check", "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.SetJSON("window", size{800, 600}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(s.Path()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := s.Get("last-check"); !ok || v != "2024-01-01" {
+		t.Errorf("got %q, %v", v, ok)
+	}
+	var w size
+	if err = s.GetJSON("window", &w); err != nil || w != (size{800, 600}) {
+		t.Errorf("got %v, %v", w, err)
+	}
+	if v, _ := s.Get("window"); v != `{"W":800,"H":600}` {
+		t.Errorf("got %q", v)
+	}
+	if got := s.Keys(); len(got) != 2 || got[0] != "last-check" || got[1] != "window" {
+		t.Errorf("wrong keys %v", got)
+	}
+
+	if err = s.Delete("window"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Delete("window"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.GetJSON("window", &w); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpenDir_Corrupt(t *testing.T) {
+	dir := xdg.Dir(t.TempDir())
+	if err := os.WriteFile(dir.Append(FileName).String(), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDir(dir); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestOpenDir_Null(t *testing.T) {
+	dir := xdg.Dir(t.TempDir())
+	if err := os.WriteFile(dir.Append(FileName).String(), []byte("null\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := s.Keys(); len(keys) != 0 {
+		t.Errorf("expected an empty store, got %v", keys)
+	}
+	if err = s.Set("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := s.Get("k"); !ok || v != "v" {
+		t.Errorf("got %q, %v", v, ok)
+	}
+}
+
+func TestOpen_DirMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions on windows")
+	}
+	x := xdgtest.SandboxApp(t, "myapp")
+	s, err := Open("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Set("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(x.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("state directory has mode %v, want 0700", info.Mode().Perm())
+	}
+}
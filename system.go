@@ -0,0 +1,93 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// The system wide locations used by WithSystemMode, following the
+// Filesystem Hierarchy Standard.
+const (
+	systemConfigBase  = "/etc"
+	systemStateBase   = "/var/lib"
+	systemCacheBase   = "/var/cache"
+	systemRuntimeBase = "/run"
+)
+
+var geteuid = os.Geteuid
+
+// WithSystemMode resolves directories to system wide locations instead of
+// the per-user XDG ones:
+//
+//	/etc/<name>         config
+//	/var/lib/<name>     data, state
+//	/var/cache/<name>   cache
+//	/run/<name>         runtime
+//
+// The system config and data directories are not affected. The option has
+// no effect on Windows.
+func WithSystemMode() Option {
+	return func(xdg *XDG) { xdg.systemMode = systemAlways }
+}
+
+// WithAutoSystemMode is like WithSystemMode but only applies when
+// IsSystemService reports true, so one binary can run both as a user
+// program and as a daemon.
+func WithAutoSystemMode() Option {
+	return func(xdg *XDG) { xdg.systemMode = systemAuto }
+}
+
+const (
+	systemNever = iota
+	systemAuto
+	systemAlways
+)
+
+// IsSystemService reports whether the process looks like a system daemon:
+// it runs as root, or it was started by systemd ($INVOCATION_ID is set)
+// without a user session ($XDG_RUNTIME_DIR is unset).
+func IsSystemService() bool { return newXdg("").isSystemService() }
+
+func (xdg *XDG) isSystemService() bool {
+	if geteuid() == 0 {
+		return true
+	}
+	_, service := xdg.lookupEnv("INVOCATION_ID")
+	_, session := xdg.envDir(runtimeDirKey)
+	return service && !session
+}
+
+func (xdg *XDG) systemModeOn() bool {
+	if xdg.goos == "windows" {
+		return false
+	}
+	switch xdg.systemMode {
+	case systemAlways:
+		return true
+	case systemAuto:
+		return xdg.isSystemService()
+	default:
+		return false
+	}
+}
+
+// systemBase returns the system wide base directory for key in system mode.
+func (xdg *XDG) systemBase(key string) (string, bool) {
+	if !xdg.systemModeOn() {
+		return "", false
+	}
+	var base string
+	switch key {
+	case configHomeKey:
+		base = systemConfigBase
+	case dataHomeKey, stateHomeKey:
+		base = systemStateBase
+	case cacheHomeKey:
+		base = systemCacheBase
+	case runtimeDirKey:
+		base = systemRuntimeBase
+	default:
+		return "", false
+	}
+	return filepath.FromSlash(base), true
+}
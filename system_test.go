@@ -0,0 +1,44 @@
+package xdg
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWithSystemMode(t *testing.T) {
+	unsetAll()
+	env := map[string]string{"HOME": "/home/t", runtimeDirKey: "/run/user/1000"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	uid := 1000
+	geteuid = func() int { return uid }
+	t.Cleanup(func() { geteuid = os.Geteuid })
+
+	x := New("app", WithEnviron(lookup), WithGOOS("linux"), WithSystemMode())
+	eq(t, "/etc/app", x.Config())
+	eq(t, "/var/lib/app", x.Data())
+	eq(t, "/var/lib/app", x.State())
+	eq(t, "/var/cache/app", x.Cache())
+	eq(t, "/run/app", x.Runtime())
+	arrEq(t, []string{"/etc/xdg/app"}, x.ConfigDirs())
+	eq(t, "/etc", x.baseDir(configHomeKey))
+
+	x = New("app", WithEnviron(lookup), WithGOOS("linux"), WithAutoSystemMode())
+	eq(t, false, x.isSystemService())
+	eq(t, "/home/t/.config/app", x.Config())
+
+	env["INVOCATION_ID"] = "abc"
+	eq(t, false, x.isSystemService())
+	delete(env, runtimeDirKey)
+	eq(t, true, x.isSystemService())
+	eq(t, "/etc/app", x.Config())
+
+	delete(env, "INVOCATION_ID")
+	uid = 0
+	eq(t, true, x.isSystemService())
+	eq(t, "/var/cache/app", x.Cache())
+
+	x = New("app", WithEnviron(lookup), WithGOOS("windows"), WithSystemMode())
+	if x.systemModeOn() {
+		t.Error("system mode should be ignored on windows")
+	}
+}
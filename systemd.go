@@ -0,0 +1,61 @@
+package xdg
+
+import "path/filepath"
+
+// The directories systemd creates for a service with StateDirectory=,
+// CacheDirectory= and friends. Unlike the XDG variables they already point
+// at the service's own directory, so the application name is not appended.
+const (
+	systemdStateKey   = "STATE_DIRECTORY"
+	systemdCacheKey   = "CACHE_DIRECTORY"
+	systemdConfigKey  = "CONFIGURATION_DIRECTORY"
+	systemdRuntimeKey = "RUNTIME_DIRECTORY"
+	systemdLogsKey    = "LOGS_DIRECTORY"
+)
+
+var systemdKeys = map[string]string{
+	configHomeKey: systemdConfigKey,
+	stateHomeKey:  systemdStateKey,
+	cacheHomeKey:  systemdCacheKey,
+	runtimeDirKey: systemdRuntimeKey,
+}
+
+// WithSystemdDirs prefers the directories systemd sets up for a service
+// over the XDG ones when they are present in the environment:
+//
+//	$CONFIGURATION_DIRECTORY   config
+//	$STATE_DIRECTORY           state
+//	$CACHE_DIRECTORY           cache
+//	$RUNTIME_DIRECTORY         runtime
+//	$LOGS_DIRECTORY            LogFile
+//
+// When a variable lists several directories the first one is used. The
+// data directory has no systemd equivalent and is resolved as usual.
+//
+// See docs:
+//
+//	https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#RuntimeDirectory=
+func WithSystemdDirs() Option {
+	return func(xdg *XDG) { xdg.systemd = true }
+}
+
+// systemdDir returns the systemd provided directory for one of the XDG
+// variable keys or one of the systemd ones.
+func (xdg *XDG) systemdDir(key string) (string, bool) {
+	if !xdg.systemd {
+		return "", false
+	}
+	if k, ok := systemdKeys[key]; ok {
+		key = k
+	}
+	val, ok := xdg.lookupEnv(key)
+	if !ok {
+		return "", false
+	}
+	for _, p := range filepath.SplitList(val) {
+		if filepath.IsAbs(p) {
+			return p, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,54 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithSystemdDirs(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	env := map[string]string{
+		"HOME":            "/home/svc",
+		runtimeDirKey:     "/run/user/1000",
+		systemdStateKey:   "/var/lib/app:/var/lib/app-extra",
+		systemdCacheKey:   "/var/cache/app",
+		systemdConfigKey:  "/etc/app",
+		systemdRuntimeKey: filepath.Join(tmp, "run"),
+		systemdLogsKey:    filepath.Join(tmp, "log"),
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	x := New("app", WithEnviron(lookup))
+	eq(t, "/home/svc/.config/app", x.Config())
+	eq(t, "/run/user/1000/app", x.Runtime())
+
+	x = New("app", WithEnviron(lookup), WithSystemdDirs())
+	eq(t, "/etc/app", x.Config())
+	eq(t, "/var/lib/app", x.State())
+	eq(t, "/var/cache/app", x.Cache())
+	eq(t, "/home/svc/.local/share/app", x.Data())
+	eq(t, filepath.Join(tmp, "run"), x.Runtime())
+
+	if err := os.Mkdir(filepath.Join(tmp, "run"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := x.RuntimeSubdir("sockets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, Dir(filepath.Join(tmp, "run", "sockets")), dir)
+
+	w, err := x.LogFile("app.log", LogRotation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if !exists(filepath.Join(tmp, "log", "app.log")) {
+		t.Error("log file should be created in $LOGS_DIRECTORY")
+	}
+
+	delete(env, systemdConfigKey)
+	eq(t, "/home/svc/.config/app", x.Config())
+}
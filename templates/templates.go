@@ -0,0 +1,158 @@
+// Package templates implements the "New Document" menu of file managers,
+// which offers a copy of every file in the user's templates directory,
+// $XDG_TEMPLATES_DIR in user-dirs.dirs.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// maxCopies bounds the names Instantiate tries before giving up.
+const maxCopies = 10000
+
+// Template is a file in the templates directory.
+type Template struct {
+	// Name is the slash separated path of the template relative to the
+	// templates directory. Templates in subdirectories are shown in
+	// submenus by file managers.
+	Name string
+	// Path is the full path of the template file.
+	Path string
+}
+
+// Dir returns the templates directory, or an empty string if the user has
+// disabled it. xdg-user-dirs disables a directory by pointing it at the home
+// directory, and file managers then offer no templates.
+func Dir() string {
+	dir := xdg.Templates()
+	if home, err := xdg.New("").Home(); err == nil && filepath.Clean(dir) == filepath.Clean(home) {
+		return ""
+	}
+	return dir
+}
+
+// List returns the templates sorted by name. Hidden files and directories are
+// skipped, as are backup files ending in ~. A missing or disabled templates
+// directory has no templates.
+func List() ([]Template, error) {
+	dir := Dir()
+	if len(dir) == 0 {
+		return nil, nil
+	}
+	var list []Template
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if hidden(d.Name()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		list = append(list, Template{Name: filepath.ToSlash(rel), Path: path})
+		return nil
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, err
+}
+
+func hidden(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~")
+}
+
+// Instantiate copies the template templateName, a Name returned by List,
+// into destDir as newName and returns the path of the new file. An empty
+// newName uses the template's file name. If the name is taken, " (2)",
+// " (3)" and so on are inserted before the extension until a free one is
+// found, the way file managers name copies. The new file keeps the
+// template's permission bits.
+func Instantiate(templateName, destDir, newName string) (string, error) {
+	rel := filepath.FromSlash(templateName)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("templates: invalid template name %q", templateName)
+	}
+	if len(newName) == 0 {
+		newName = filepath.Base(rel)
+	}
+	if !filepath.IsLocal(newName) || strings.ContainsAny(newName, `/\`) {
+		return "", fmt.Errorf("templates: invalid file name %q", newName)
+	}
+	dir := Dir()
+	if len(dir) == 0 {
+		return "", errors.New("templates: the templates directory is disabled")
+	}
+	src, err := os.Open(filepath.Join(dir, rel))
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("templates: %s is not a regular file", templateName)
+	}
+	dst, path, err := create(destDir, newName, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// create makes a new file named name in dir, numbering the name if it
+// already exists. O_EXCL makes sure an existing file is never truncated,
+// even if it appears after the name was checked.
+func create(dir, name string, perm fs.FileMode) (*os.File, string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if len(stem) == 0 {
+		// Dot files such as .gitignore have no extension to keep.
+		stem, ext = name, ""
+	}
+	for i := 1; i <= maxCopies; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = stem + " (" + strconv.Itoa(i) + ")" + ext
+		}
+		path := filepath.Join(dir, candidate)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, path, err
+	}
+	return nil, "", fmt.Errorf("templates: no free name for %s in %s", name, dir)
+}
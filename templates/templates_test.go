@@ -0,0 +1,99 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/harrybrwn/xdg"
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestList(t *testing.T) {
+	xdgtest.Sandbox(t)
+	dir := xdg.Templates()
+	list, err := List()
+	if err != nil || len(list) != 0 {
+		t.Fatalf("expected no templates for a missing directory, got %v, %v", list, err)
+	}
+	xdgtest.WriteFile(t, filepath.Join(dir, "Text File.txt"), "")
+	xdgtest.WriteFile(t, filepath.Join(dir, "Office", "Sheet.ods"), "")
+	xdgtest.WriteFile(t, filepath.Join(dir, ".hidden"), "")
+	xdgtest.WriteFile(t, filepath.Join(dir, ".git", "config"), "")
+	xdgtest.WriteFile(t, filepath.Join(dir, "notes.txt~"), "")
+	list, err = List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Template{
+		{Name: "Office/Sheet.ods", Path: filepath.Join(dir, "Office", "Sheet.ods")},
+		{Name: "Text File.txt", Path: filepath.Join(dir, "Text File.txt")},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("got %v, want %v", list, want)
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("%d: got %v, want %v", i, list[i], want[i])
+		}
+	}
+
+	if err = xdg.DisableUserDir(xdg.UserDirTemplates); err != nil {
+		t.Fatal(err)
+	}
+	if list, err = List(); err != nil || len(list) != 0 {
+		t.Errorf("expected no templates when disabled, got %v, %v", list, err)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	xdgtest.Sandbox(t)
+	dir := xdg.Templates()
+	script := xdgtest.WriteFile(t, filepath.Join(dir, "script.sh"), "#!/bin/sh\n")
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	xdgtest.WriteFile(t, filepath.Join(dir, ".gitignore"), "*.o\n")
+	dest := t.TempDir()
+
+	for _, want := range []string{"run.sh", "run (2).sh", "run (3).sh"} {
+		p, err := Instantiate("script.sh", dest, "run.sh")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != filepath.Join(dest, want) {
+			t.Errorf("got %s, want %s", p, want)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "run (2).sh"))
+	if err != nil || string(b) != "#!/bin/sh\n" {
+		t.Errorf("wrong copy %q, %v", b, err)
+	}
+	info, err := os.Stat(filepath.Join(dest, "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected the template's mode, got %v", info.Mode())
+	}
+
+	for _, want := range []string{".gitignore", ".gitignore (2)"} {
+		p, err := Instantiate(".gitignore", dest, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != filepath.Join(dest, want) {
+			t.Errorf("got %s, want %s", p, want)
+		}
+	}
+
+	for _, tt := range [][2]string{{"../x", "y"}, {"script.sh", "a/b"}, {"script.sh", ".."}} {
+		if _, err = Instantiate(tt[0], dest, tt[1]); err == nil {
+			t.Errorf("expected an error for %q, %q", tt[0], tt[1])
+		}
+	}
+	if _, err = Instantiate("missing", dest, ""); !os.IsNotExist(err) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
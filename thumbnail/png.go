@@ -0,0 +1,128 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	keyURI      = "Thumb::URI"
+	keyMTime    = "Thumb::MTime"
+	keySize     = "Thumb::Size"
+	keyMimeType = "Thumb::Mimetype"
+	keySoftware = "Software"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ErrNotPNG is returned for data that is not a PNG image.
+var ErrNotPNG = errors.New("thumbnail: not a PNG image")
+
+// ReadInfo reads the thumbnail metadata stored in the tEXt chunks of a PNG
+// image.
+func ReadInfo(r io.Reader) (Info, error) {
+	var info Info
+	sig := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, sig); err != nil || !bytes.Equal(sig, pngSignature) {
+		return info, ErrNotPNG
+	}
+	var head [8]byte
+	for {
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			return info, err
+		}
+		n := binary.BigEndian.Uint32(head[:4])
+		typ := string(head[4:])
+		if typ == "IDAT" || typ == "IEND" {
+			// Metadata has to come before the image data to be useful.
+			return info, nil
+		}
+		if typ != "tEXt" {
+			if _, err := io.CopyN(io.Discard, r, int64(n)+4); err != nil {
+				return info, err
+			}
+			continue
+		}
+		data := make([]byte, int(n)+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return info, err
+		}
+		key, val, _ := strings.Cut(string(data[:n]), "\x00")
+		switch key {
+		case keyURI:
+			info.URI = val
+		case keyMTime:
+			info.MTime, _ = strconv.ParseInt(val, 10, 64)
+		case keySize:
+			info.Size, _ = strconv.ParseInt(val, 10, 64)
+		case keyMimeType:
+			info.MimeType = val
+		case keySoftware:
+			info.Software = val
+		}
+	}
+}
+
+// embed returns a copy of the PNG image data with the metadata stored in
+// tEXt chunks right after the header, replacing any the image already had.
+func (i Info) embed(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrNotPNG
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)+256))
+	out.Write(pngSignature)
+	rest := data[len(pngSignature):]
+	first := true
+	for len(rest) > 0 {
+		if len(rest) < 12 {
+			return nil, ErrNotPNG
+		}
+		n := int(binary.BigEndian.Uint32(rest[:4]))
+		if n > len(rest)-12 {
+			return nil, ErrNotPNG
+		}
+		chunk := rest[:n+12]
+		rest = rest[n+12:]
+		if string(chunk[4:8]) == "tEXt" && i.replaces(chunk[8:8+n]) {
+			continue
+		}
+		out.Write(chunk)
+		if first {
+			// IHDR is always the first chunk.
+			for _, kv := range i.pairs() {
+				writeChunk(out, "tEXt", []byte(kv[0]+"\x00"+kv[1]))
+			}
+			first = false
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// replaces reports whether the tEXt chunk data holds a key that i sets.
+func (i Info) replaces(data []byte) bool {
+	key, _, _ := bytes.Cut(data, []byte{0})
+	for _, kv := range i.pairs() {
+		if string(key) == kv[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func writeChunk(w *bytes.Buffer, typ string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	w.Write(n[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	w.WriteString(typ)
+	w.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	w.Write(n[:])
+}
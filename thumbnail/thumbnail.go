@@ -0,0 +1,259 @@
+// Package thumbnail implements the Thumbnail Managing Standard, which lets
+// applications share the thumbnails of files kept in
+// $XDG_CACHE_HOME/thumbnails.
+//
+// See docs:
+//
+//	https://specifications.freedesktop.org/thumbnail-spec/latest/
+package thumbnail
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// Size is a thumbnail size bucket.
+type Size int
+
+const (
+	// Normal thumbnails fit in 128x128 pixels.
+	Normal Size = 128
+	// Large thumbnails fit in 256x256 pixels.
+	Large Size = 256
+	// XLarge thumbnails fit in 512x512 pixels.
+	XLarge Size = 512
+	// XXLarge thumbnails fit in 1024x1024 pixels.
+	XXLarge Size = 1024
+)
+
+// Dir returns the name of the size's directory.
+func (s Size) Dir() string {
+	switch s {
+	case Normal:
+		return "normal"
+	case Large:
+		return "large"
+	case XLarge:
+		return "x-large"
+	case XXLarge:
+		return "xx-large"
+	}
+	return ""
+}
+
+// Pixels returns the maximum width and height of the size's thumbnails.
+func (s Size) Pixels() int { return int(s) }
+
+// Dir returns the thumbnail cache, $XDG_CACHE_HOME/thumbnails.
+func Dir() (xdg.Dir, error) {
+	dir, err := xdg.CacheE("thumbnails")
+	return xdg.Dir(dir), err
+}
+
+// URI returns the canonical URI of the file path that thumbnails are keyed
+// by. The path is made absolute and escaped the way GLib does, so that the
+// hashes match the ones other applications compute.
+func URI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return "file://" + escapePath(p), nil
+}
+
+// Name returns the file name of the thumbnails of uri, the MD5 hash of the
+// URI followed by ".png".
+func Name(uri string) string {
+	sum := md5.Sum([]byte(uri))
+	return hex.EncodeToString(sum[:]) + ".png"
+}
+
+// Path returns where the thumbnail of uri in the given size is stored.
+func Path(uri string, size Size) (string, error) {
+	if len(size.Dir()) == 0 {
+		return "", fmt.Errorf("thumbnail: invalid size %d", size)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(string(dir), size.Dir(), Name(uri)), nil
+}
+
+// FailPath returns where the marker recording that app failed to create a
+// thumbnail for uri is stored.
+func FailPath(uri, app string) (string, error) {
+	if len(app) == 0 || !filepath.IsLocal(app) || strings.ContainsAny(app, `/\`) {
+		return "", fmt.Errorf("thumbnail: invalid application name %q", app)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(string(dir), "fail", app, Name(uri)), nil
+}
+
+// Lookup returns the thumbnail of the file path in the given size if one
+// exists and is up to date, meaning its Thumb::URI and Thumb::MTime keys
+// match the file.
+func Lookup(path string, size Size) (string, bool) {
+	uri, info, err := stat(path)
+	if err != nil {
+		return "", false
+	}
+	thumb, err := Path(uri, size)
+	if err != nil {
+		return "", false
+	}
+	return thumb, valid(thumb, uri, info)
+}
+
+// Save stores data, a PNG image that must already be scaled to fit in
+// size, as the thumbnail of the file path. The required Thumb::URI and
+// Thumb::MTime keys and the Thumb::Size key are added to meta, and the
+// thumbnail is written atomically with mode 0600. It returns the
+// thumbnail's path.
+func Save(path string, size Size, data []byte, meta Info) (string, error) {
+	uri, info, err := stat(path)
+	if err != nil {
+		return "", err
+	}
+	thumb, err := Path(uri, size)
+	if err != nil {
+		return "", err
+	}
+	return thumb, write(thumb, data, fill(meta, uri, info))
+}
+
+// SaveFailed records that app could not create a thumbnail for the file
+// path so it is not retried until the file changes.
+func SaveFailed(path, app string) (string, error) {
+	uri, info, err := stat(path)
+	if err != nil {
+		return "", err
+	}
+	marker, err := FailPath(uri, app)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		return "", err
+	}
+	return marker, write(marker, buf.Bytes(), fill(Info{Software: app}, uri, info))
+}
+
+// Failed reports whether app recorded a failure for the current version of
+// the file path.
+func Failed(path, app string) bool {
+	uri, info, err := stat(path)
+	if err != nil {
+		return false
+	}
+	marker, err := FailPath(uri, app)
+	return err == nil && valid(marker, uri, info)
+}
+
+func stat(path string) (string, os.FileInfo, error) {
+	uri, err := URI(path)
+	if err != nil {
+		return "", nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return uri, info, nil
+}
+
+func fill(meta Info, uri string, info os.FileInfo) Info {
+	meta.URI = uri
+	meta.MTime = info.ModTime().Unix()
+	meta.Size = info.Size()
+	return meta
+}
+
+func write(path string, data []byte, meta Info) error {
+	data, err := meta.embed(data)
+	if err != nil {
+		return err
+	}
+	dir := xdg.Dir(filepath.Dir(path))
+	if err = os.MkdirAll(dir.String(), 0700); err != nil {
+		return err
+	}
+	return dir.WriteFileAtomic(filepath.Base(path), data, 0600)
+}
+
+// valid reports whether the thumbnail at path belongs to the current
+// version of the file.
+func valid(path, uri string, info os.FileInfo) bool {
+	f, err := xdg.Dir(filepath.Dir(path)).Open(filepath.Base(path))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	meta, err := ReadInfo(f)
+	return err == nil && meta.URI == uri && meta.MTime == info.ModTime().Unix()
+}
+
+// escapePath percent-encodes every byte of p except letters, digits and
+// !$&'()*+,-./:=@_~, matching g_filename_to_uri.
+func escapePath(p string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!$&'()*+,-./:=@_~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+	return b.String()
+}
+
+// Info holds the metadata keys stored in a thumbnail.
+type Info struct {
+	// URI is the Thumb::URI of the original file.
+	URI string
+	// MTime is the Thumb::MTime, the original file's modification time in
+	// seconds since the epoch.
+	MTime int64
+	// Size is the Thumb::Size of the original file in bytes.
+	Size int64
+	// MimeType is the Thumb::Mimetype of the original file.
+	MimeType string
+	// Software is the program that created the thumbnail.
+	Software string
+}
+
+func (i Info) pairs() [][2]string {
+	pairs := [][2]string{{keyURI, i.URI}, {keyMTime, strconv.FormatInt(i.MTime, 10)}}
+	if i.Size > 0 {
+		pairs = append(pairs, [2]string{keySize, strconv.FormatInt(i.Size, 10)})
+	}
+	if len(i.MimeType) > 0 {
+		pairs = append(pairs, [2]string{keyMimeType, i.MimeType})
+	}
+	if len(i.Software) > 0 {
+		pairs = append(pairs, [2]string{keySoftware, i.Software})
+	}
+	return pairs
+}
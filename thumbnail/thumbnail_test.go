@@ -0,0 +1,175 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harrybrwn/xdg/xdgtest"
+)
+
+func TestURI(t *testing.T) {
+	if filepath.Separator != '/' {
+		t.Skip("unix paths")
+	}
+	for path, want := range map[string]string{
+		"/home/jens/photos/me.png":   "file:///home/jens/photos/me.png",
+		"/home/jens/my photos/ü.png": "file:///home/jens/my%20photos/%C3%BC.png",
+		"/tmp/a#b?c;d[e]%f":          "file:///tmp/a%23b%3Fc%3Bd%5Be%5D%25f",
+		"/tmp/!$&'()*+,-.:=@_~":      "file:///tmp/!$&'()*+,-.:=@_~",
+	} {
+		uri, err := URI(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uri != want {
+			t.Errorf("URI(%q): expected %s, got %s", path, want, uri)
+		}
+	}
+	// The example from the spec.
+	if name := Name("file:///home/jens/photos/me.png"); name != "c6ee772d9e49320e97ec29a7eb5b1697.png" {
+		t.Errorf("wrong name %s", name)
+	}
+}
+
+func TestPath(t *testing.T) {
+	xdgtest.Sandbox(t)
+	cache := filepath.Join(os.Getenv("XDG_CACHE_HOME"), "thumbnails")
+	uri := "file:///home/jens/photos/me.png"
+	for size, dir := range map[Size]string{Normal: "normal", Large: "large", XLarge: "x-large", XXLarge: "xx-large"} {
+		p, err := Path(uri, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(cache, dir, Name(uri)); p != want {
+			t.Errorf("expected %s, got %s", want, p)
+		}
+	}
+	if _, err := Path(uri, 100); err == nil {
+		t.Error("expected an error for an invalid size")
+	}
+	p, err := FailPath(uri, "gnome-thumbnail-factory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(cache, "fail", "gnome-thumbnail-factory", Name(uri)); p != want {
+		t.Errorf("expected %s, got %s", want, p)
+	}
+	if _, err = FailPath(uri, "../escape"); err == nil {
+		t.Error("expected an error for an invalid application name")
+	}
+}
+
+func testPNG(t *testing.T, size int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSaveLookup(t *testing.T) {
+	xdgtest.Sandbox(t)
+	file := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(file, []byte("not really a jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Lookup(file, Normal); ok {
+		t.Error("expected no thumbnail yet")
+	}
+	thumb, err := Save(file, Normal, testPNG(t, 128), Info{MimeType: "image/jpeg", Software: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := Lookup(file, Normal)
+	if !ok || got != thumb {
+		t.Fatalf("expected %s, got %s, %v", thumb, got, ok)
+	}
+	if _, ok = Lookup(file, Large); ok {
+		t.Error("expected no large thumbnail")
+	}
+	if info, err := os.Stat(thumb); err != nil {
+		t.Fatal(err)
+	} else if filepath.Separator == '/' && info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode())
+	}
+
+	f, err := os.Open(thumb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	meta, err := ReadInfo(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, _ := URI(file)
+	if meta.URI != uri || meta.MimeType != "image/jpeg" || meta.Software != "test" || meta.Size != 17 {
+		t.Errorf("wrong metadata %+v", meta)
+	}
+	f.Seek(0, 0)
+	if img, err := png.Decode(f); err != nil {
+		t.Fatalf("thumbnail is not a valid PNG: %v", err)
+	} else if img.Bounds().Dx() != 128 {
+		t.Errorf("wrong width %d", img.Bounds().Dx())
+	}
+
+	// Thumbnails go stale when the file changes.
+	later := time.Now().Add(time.Hour)
+	if err = os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok = Lookup(file, Normal); ok {
+		t.Error("expected the thumbnail to be stale")
+	}
+	// Saving again replaces the old keys instead of adding more.
+	data, _ := os.ReadFile(thumb)
+	if _, err = Save(file, Normal, data, Info{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok = Lookup(file, Normal); !ok {
+		t.Error("expected the new thumbnail to be valid")
+	}
+	data, _ = os.ReadFile(thumb)
+	if n := bytes.Count(data, []byte(keyMTime)); n != 1 {
+		t.Errorf("expected one %s key, got %d", keyMTime, n)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	xdgtest.Sandbox(t)
+	file := filepath.Join(t.TempDir(), "broken.svg")
+	if err := os.WriteFile(file, []byte("<svg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if Failed(file, "app") {
+		t.Error("expected no failure marker yet")
+	}
+	if _, err := SaveFailed(file, "app"); err != nil {
+		t.Fatal(err)
+	}
+	if !Failed(file, "app") {
+		t.Error("expected a failure marker")
+	}
+	if Failed(file, "other") {
+		t.Error("failure markers are per application")
+	}
+}
+
+func TestEmbedInvalid(t *testing.T) {
+	if _, err := (Info{}).embed([]byte("GIF89a")); err != ErrNotPNG {
+		t.Errorf("expected ErrNotPNG, got %v", err)
+	}
+	data := testPNG(t, 1)
+	if _, err := (Info{}).embed(data[:len(data)-3]); err != ErrNotPNG {
+		t.Errorf("expected ErrNotPNG for truncated data, got %v", err)
+	}
+	if _, err := ReadInfo(bytes.NewReader([]byte("nope"))); err != ErrNotPNG {
+		t.Errorf("expected ErrNotPNG, got %v", err)
+	}
+}
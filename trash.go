@@ -0,0 +1,555 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+const (
+	trashInfoGroup = "Trash Info"
+	trashInfoExt   = ".trashinfo"
+	// trashTimeFormat is the DeletionDate format, in local time.
+	trashTimeFormat = "2006-01-02T15:04:05"
+)
+
+// deviceOf is swapped out in tests to simulate mount points.
+var deviceOf = device
+
+// Trash moves path to the trash. See (*XDG).Trash.
+func Trash(path string) (string, error) { return newXdg("").Trash(path) }
+
+// Trash moves path to the trash as described by the freedesktop Trash
+// specification and returns its new location. Files on the same device as
+// $XDG_DATA_HOME go to $XDG_DATA_HOME/Trash. Files on other mounts go to
+// $topdir/.Trash/$uid when the administrator has created a sticky $topdir/.Trash,
+// or to $topdir/.Trash-$uid otherwise, and fall back to being copied into
+// the home trash when neither can be used. Symbolic links are trashed
+// themselves rather than their targets.
+func (xdg *XDG) Trash(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err = os.Lstat(longPath(abs)); err != nil {
+		return "", err
+	}
+	home, err := xdg.homeTrash()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	var errs []error
+	for _, can := range trashCans(home, abs) {
+		dst, err := can.put(abs, now)
+		if err == nil {
+			return dst, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", fmt.Errorf("trash %s: %w", path, errors.Join(errs...))
+}
+
+func (xdg *XDG) homeTrash() (trashCan, error) {
+	base := xdg.baseDir(dataHomeKey)
+	if len(base) == 0 {
+		return trashCan{}, ErrNoHome
+	}
+	return trashCan{dir: filepath.Join(base, "Trash")}, nil
+}
+
+// trashCan is a trash directory holding the files and info subdirectories.
+type trashCan struct {
+	dir string
+	// topdir is the mount point of a per-mount trash and empty for the
+	// home trash.
+	topdir string
+}
+
+// trashCans returns the trash directories path may be moved to in the order
+// they should be tried.
+func trashCans(home trashCan, path string) []trashCan {
+	dev, ok := deviceOf(path)
+	if !ok {
+		return []trashCan{home}
+	}
+	if hdev, ok := existingDevice(home.dir); !ok || hdev == dev {
+		return []trashCan{home}
+	}
+	return append(topTrashCans(mountRoot(path, dev)), home)
+}
+
+// topTrashCans returns the per-mount trash directories of the mount point
+// top.
+func topTrashCans(top string) []trashCan {
+	uid := os.Getuid()
+	if uid < 0 {
+		return nil
+	}
+	id := strconv.Itoa(uid)
+	var cans []trashCan
+	// The shared directory must be sticky so users cannot remove each
+	// other's trash.
+	shared := filepath.Join(top, ".Trash")
+	if info, err := os.Lstat(longPath(shared)); err == nil && info.IsDir() && info.Mode()&fs.ModeSticky != 0 {
+		cans = append(cans, trashCan{dir: filepath.Join(shared, id), topdir: top})
+	}
+	return append(cans, trashCan{dir: filepath.Join(top, ".Trash-"+id), topdir: top})
+}
+
+// mountRoot returns the top directory of the mount holding path.
+func mountRoot(path string, dev uint64) string {
+	dir := path
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if d, ok := deviceOf(parent); !ok || d != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// existingDevice returns the device of path or of its closest existing
+// parent.
+func existingDevice(path string) (uint64, bool) {
+	for {
+		if d, ok := deviceOf(path); ok {
+			return d, true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return 0, false
+		}
+		path = parent
+	}
+}
+
+func (c trashCan) files() string { return filepath.Join(c.dir, "files") }
+func (c trashCan) info() string  { return filepath.Join(c.dir, "info") }
+
+// put moves path into the trash can and writes its info file.
+func (c trashCan) put(path string, now time.Time) (string, error) {
+	for _, dir := range []string{c.files(), c.info()} {
+		err := os.MkdirAll(longPath(dir), 0700)
+		audit(OpMkdir, dir, 0, 0700|fs.ModeDir, err)
+		if err != nil {
+			return "", err
+		}
+	}
+	orig := filepath.ToSlash(path)
+	if len(c.topdir) > 0 {
+		rel, err := filepath.Rel(c.topdir, path)
+		if err != nil {
+			return "", err
+		}
+		orig = filepath.ToSlash(rel)
+	}
+	info, name, err := c.reserve(filepath.Base(path), trashInfo(orig, now))
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(c.files(), name)
+	err = os.Rename(longPath(path), longPath(dst))
+	if isCrossDevice(err) && len(c.topdir) == 0 {
+		err = moveAcross(path, dst)
+	}
+	audit(OpRename, dst, 0, 0, err)
+	if err != nil {
+		os.Remove(longPath(info))
+		return "", err
+	}
+	return dst, nil
+}
+
+// reserve atomically creates an info file for a unique name based on base
+// and returns its path along with the name.
+func (c trashCan) reserve(base string, data []byte) (string, string, error) {
+	name := base
+	for i := 2; ; i++ {
+		path := filepath.Join(c.info(), name+trashInfoExt)
+		if _, err := os.Lstat(longPath(filepath.Join(c.files(), name))); err != nil {
+			f, err := os.OpenFile(longPath(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err == nil {
+				_, err = f.Write(data)
+				err = errors.Join(err, f.Close())
+				audit(OpWrite, path, int64(len(data)), 0600, err)
+				if err != nil {
+					os.Remove(longPath(path))
+					return "", "", err
+				}
+				return path, name, nil
+			}
+			if !errors.Is(err, fs.ErrExist) {
+				return "", "", err
+			}
+		}
+		name = base + "." + strconv.Itoa(i)
+	}
+}
+
+func trashInfo(orig string, now time.Time) []byte {
+	f := keyfile.New()
+	g := f.AddGroup(trashInfoGroup)
+	// Values are URL escaped so they never need keyfile escaping.
+	g.SetRaw("Path", "", (&url.URL{Path: orig}).EscapedPath())
+	g.SetRaw("DeletionDate", "", now.Format(trashTimeFormat))
+	return f.Bytes()
+}
+
+// moveAcross moves src to dst by copying it and removing the original, for
+// when the two are on different devices.
+func moveAcross(src, dst string) error {
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(longPath(dst))
+		return err
+	}
+	return os.RemoveAll(longPath(src))
+}
+
+// copyTree copies src to dst keeping permission bits, modification times
+// and symbolic links. Directories are created writable and given their
+// own mode and time once everything inside them is copied.
+func copyTree(src, dst string) error {
+	type dir struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []dir
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, dir{target, info})
+			return os.Mkdir(longPath(target), 0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(longPath(path))
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, longPath(target))
+		case d.Type().IsRegular():
+			if err = copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			// The new file's mode was masked by the umask.
+			if err = os.Chmod(longPath(target), info.Mode().Perm()); err != nil {
+				return err
+			}
+			return os.Chtimes(longPath(target), info.ModTime(), info.ModTime())
+		default:
+			return fmt.Errorf("%s: cannot copy %v", path, d.Type())
+		}
+	})
+	for i := len(dirs) - 1; i >= 0 && err == nil; i-- {
+		d := dirs[i]
+		if err = os.Chmod(longPath(d.path), d.info.Mode().Perm()); err == nil {
+			err = os.Chtimes(longPath(d.path), d.info.ModTime(), d.info.ModTime())
+		}
+	}
+	return err
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(longPath(src))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(longPath(dst), os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return errors.Join(err, out.Close())
+}
+
+// TrashEntry is a file in the trash.
+type TrashEntry struct {
+	// Name is the name of the file inside the trash directory.
+	Name string
+	// Path is the absolute path the file was trashed from.
+	Path string
+	// Deleted is when the file was moved to the trash.
+	Deleted time.Time
+
+	can trashCan
+}
+
+// File returns the current location of the trashed file.
+func (e TrashEntry) File() string { return filepath.Join(e.can.files(), e.Name) }
+
+func (e TrashEntry) infoFile() string { return filepath.Join(e.can.info(), e.Name+trashInfoExt) }
+
+// mountsFile lists the mount points searched for per-mount trash
+// directories.
+var mountsFile = "/proc/self/mounts"
+
+// ListTrash returns the entries in the trash. See (*XDG).ListTrash.
+func ListTrash() ([]TrashEntry, error) { return newXdg("").ListTrash() }
+
+// ListTrash returns the entries of the home trash and of the per-mount trash
+// directories of every mounted file system, oldest first. Info files that
+// cannot be parsed or whose file is missing are skipped.
+func (xdg *XDG) ListTrash() ([]TrashEntry, error) {
+	cans, err := xdg.allTrashCans()
+	if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	for _, can := range cans {
+		list, err := can.entries()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, list...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Deleted.Before(entries[j].Deleted) })
+	return entries, nil
+}
+
+// Restore moves a trashed file back to its original path and removes its
+// entry from the trash. Missing parent directories are recreated. It fails
+// with an error wrapping fs.ErrExist if something already occupies the
+// original path.
+func Restore(e TrashEntry) error {
+	if _, err := os.Lstat(longPath(e.Path)); err == nil {
+		return &fs.PathError{Op: "restore", Path: e.Path, Err: fs.ErrExist}
+	}
+	dir := filepath.Dir(e.Path)
+	err := os.MkdirAll(longPath(dir), 0755)
+	audit(OpMkdir, dir, 0, 0755|fs.ModeDir, err)
+	if err != nil {
+		return err
+	}
+	src := e.File()
+	err = os.Rename(longPath(src), longPath(e.Path))
+	if isCrossDevice(err) {
+		err = moveAcross(src, e.Path)
+	}
+	audit(OpRename, e.Path, 0, 0, err)
+	if err != nil {
+		return err
+	}
+	return removeInfo(e.infoFile())
+}
+
+// EmptyTrash permanently deletes trash entries. See (*XDG).EmptyTrash.
+func EmptyTrash(olderThan time.Duration) ([]TrashEntry, error) {
+	return newXdg("").EmptyTrash(olderThan)
+}
+
+// EmptyTrash permanently deletes the entries that were trashed more than
+// olderThan ago, or every entry if olderThan is zero, and returns the removed
+// entries. Emptying the whole trash also removes files and info files that
+// have lost their counterpart.
+func (xdg *XDG) EmptyTrash(olderThan time.Duration) ([]TrashEntry, error) {
+	cans, err := xdg.allTrashCans()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		removed []TrashEntry
+		errs    []error
+		cutoff  = time.Now().Add(-olderThan)
+	)
+	for _, can := range cans {
+		list, err := can.entries()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, e := range list {
+			if olderThan > 0 && !e.Deleted.Before(cutoff) {
+				continue
+			}
+			// The file goes first so a failure leaves a listable entry.
+			err := os.RemoveAll(longPath(e.File()))
+			audit(OpRemove, e.File(), 0, 0, err)
+			if err == nil {
+				err = removeInfo(e.infoFile())
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			removed = append(removed, e)
+		}
+		if olderThan <= 0 {
+			errs = append(errs, can.removeOrphans())
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// allTrashCans returns the home trash and the per-mount trash directories
+// that exist.
+func (xdg *XDG) allTrashCans() ([]trashCan, error) {
+	home, err := xdg.homeTrash()
+	if err != nil {
+		return nil, err
+	}
+	cans := []trashCan{home}
+	seen := map[string]bool{home.dir: true}
+	for _, top := range mountPoints() {
+		for _, can := range topTrashCans(top) {
+			if seen[can.dir] {
+				continue
+			}
+			seen[can.dir] = true
+			if info, err := os.Stat(longPath(can.dir)); err == nil && info.IsDir() {
+				cans = append(cans, can)
+			}
+		}
+	}
+	return cans, nil
+}
+
+// mountPoints returns the mount points listed in mountsFile, or nothing on
+// systems without one.
+func mountPoints() []string {
+	b, err := os.ReadFile(mountsFile)
+	if err != nil {
+		return nil
+	}
+	var mounts []string
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mounts = append(mounts, unescapeMount(fields[1]))
+	}
+	return mounts
+}
+
+// unescapeMount decodes the octal escapes used for spaces and other special
+// characters in mount paths.
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// entries parses the info files of the trash can.
+func (c trashCan) entries() ([]TrashEntry, error) {
+	list, err := os.ReadDir(longPath(c.info()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	for _, d := range list {
+		name, ok := strings.CutSuffix(d.Name(), trashInfoExt)
+		if !ok || d.IsDir() {
+			continue
+		}
+		e, ok := c.entry(name)
+		if !ok {
+			continue
+		}
+		if _, err := os.Lstat(longPath(e.File())); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (c trashCan) entry(name string) (TrashEntry, bool) {
+	f, err := keyfile.Load(filepath.Join(c.info(), name+trashInfoExt))
+	if err != nil {
+		return TrashEntry{}, false
+	}
+	g := f.Group(trashInfoGroup)
+	if g == nil {
+		return TrashEntry{}, false
+	}
+	raw, _ := g.Raw("Path")
+	orig, err := url.PathUnescape(raw)
+	if err != nil || len(orig) == 0 {
+		return TrashEntry{}, false
+	}
+	orig = filepath.FromSlash(orig)
+	if !filepath.IsAbs(orig) {
+		if len(c.topdir) == 0 || !filepath.IsLocal(orig) {
+			return TrashEntry{}, false
+		}
+		orig = filepath.Join(c.topdir, orig)
+	}
+	e := TrashEntry{Name: name, Path: orig, can: c}
+	if date, ok := g.Raw("DeletionDate"); ok {
+		e.Deleted, _ = time.ParseInLocation(trashTimeFormat, date, time.Local)
+	}
+	return e, true
+}
+
+// removeOrphans removes files that have no info file and info files that
+// have no file.
+func (c trashCan) removeOrphans() error {
+	var errs []error
+	files, _ := os.ReadDir(longPath(c.files()))
+	for _, d := range files {
+		if _, err := os.Lstat(longPath(filepath.Join(c.info(), d.Name()+trashInfoExt))); errors.Is(err, fs.ErrNotExist) {
+			path := filepath.Join(c.files(), d.Name())
+			err = os.RemoveAll(longPath(path))
+			audit(OpRemove, path, 0, 0, err)
+			errs = append(errs, err)
+		}
+	}
+	infos, _ := os.ReadDir(longPath(c.info()))
+	for _, d := range infos {
+		name, ok := strings.CutSuffix(d.Name(), trashInfoExt)
+		if !ok {
+			continue
+		}
+		if _, err := os.Lstat(longPath(filepath.Join(c.files(), name))); errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, removeInfo(filepath.Join(c.info(), d.Name())))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeInfo removes an info file. The trash always lives on the host file
+// system, so this does not go through the installed FileSystem.
+func removeInfo(path string) error {
+	err := os.Remove(longPath(path))
+	audit(OpRemove, path, 0, 0, err)
+	return err
+}
@@ -0,0 +1,7 @@
+//go:build !unix && !windows
+
+package xdg
+
+func device(string) (uint64, bool) { return 0, false }
+
+func isCrossDevice(error) bool { return false }
@@ -0,0 +1,292 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harrybrwn/xdg/keyfile"
+)
+
+func readTrashInfo(t *testing.T, path string) (string, time.Time) {
+	t.Helper()
+	f, err := keyfile.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := f.Group(trashInfoGroup)
+	if g == nil {
+		t.Fatalf("%s: missing [%s] group", path, trashInfoGroup)
+	}
+	orig, _ := g.Raw("Path")
+	date, _ := g.Raw("DeletionDate")
+	tm, err := time.ParseInLocation(trashTimeFormat, date, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return orig, tm
+}
+
+// fakeMount makes mnt look like a separate file system.
+func fakeMount(t *testing.T, mnt string) {
+	t.Helper()
+	oldDevice, oldMounts := deviceOf, mountsFile
+	deviceOf = func(path string) (uint64, bool) {
+		if _, ok := device(path); !ok {
+			return 0, false
+		}
+		if path == mnt || strings.HasPrefix(path, mnt+string(filepath.Separator)) {
+			return 2, true
+		}
+		return 1, true
+	}
+	mountsFile = filepath.Join(t.TempDir(), "mounts")
+	data := "/dev/sda1 / ext4 rw 0 0\n/dev/sdb1 " + strings.ReplaceAll(mnt, " ", `\040`) + " ext4 rw 0 0\n"
+	if err := os.WriteFile(mountsFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { deviceOf, mountsFile = oldDevice, oldMounts })
+}
+
+func TestTrash(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(dataHomeKey, filepath.Join(tmp, "data"))
+	home := filepath.Join(tmp, "data", "Trash")
+	file := filepath.Join(tmp, "a b%.txt")
+	writeTestFile(t, file)
+
+	dst, err := Trash(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(home, "files", "a b%.txt"), dst)
+	if _, err = os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be gone, got %v", file, err)
+	}
+	orig, date := readTrashInfo(t, filepath.Join(home, "info", "a b%.txt.trashinfo"))
+	if !strings.HasSuffix(orig, "/a%20b%25.txt") {
+		t.Errorf("wrong escaped path %q", orig)
+	}
+	if time.Since(date) > time.Minute {
+		t.Errorf("wrong deletion date %v", date)
+	}
+
+	writeTestFile(t, file)
+	dst, err = Trash(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(home, "files", "a b%.txt.2"), dst)
+	if _, err = os.Stat(filepath.Join(home, "info", "a b%.txt.2.trashinfo")); err != nil {
+		t.Error(err)
+	}
+	if _, err = Trash(filepath.Join(tmp, "missing")); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestTrashTopdir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no per-mount trash on windows")
+	}
+	unsetAll()
+	tmp := t.TempDir()
+	mnt := filepath.Join(tmp, "mnt")
+	t.Setenv(dataHomeKey, filepath.Join(tmp, "data"))
+	fakeMount(t, mnt)
+	uid := strconv.Itoa(os.Getuid())
+
+	file := filepath.Join(mnt, "docs", "a.txt")
+	writeTestFile(t, file)
+	dst, err := Trash(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(mnt, ".Trash-"+uid, "files", "a.txt"), dst)
+	orig, _ := readTrashInfo(t, filepath.Join(mnt, ".Trash-"+uid, "info", "a.txt.trashinfo"))
+	eq(t, "docs/a.txt", orig)
+
+	// A shared .Trash without the sticky bit must be ignored.
+	shared := filepath.Join(mnt, ".Trash")
+	if err = os.Mkdir(shared, 0777); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, file)
+	if dst, err = Trash(file); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(mnt, ".Trash-"+uid, "files", "a.txt.2"), dst)
+
+	if err = os.Chmod(shared, 0777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, file)
+	if dst, err = Trash(file); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(shared, uid, "files", "a.txt"), dst)
+}
+
+func TestMoveAcross(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	writeTestFile(t, filepath.Join(src, "a.txt"))
+	writeTestFile(t, filepath.Join(src, "sub", "b.txt"))
+	dst := filepath.Join(tmp, "dst")
+	if err := moveAcross(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", src, err)
+	}
+	for _, name := range []string{"a.txt", filepath.Join("sub", "b.txt")} {
+		b, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, filepath.Join(src, name), string(b))
+	}
+}
+
+func TestCopyTreePreserve(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits differ on windows")
+	}
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	sub := filepath.Join(src, "sub")
+	file := filepath.Join(sub, "b.txt")
+	writeTestFile(t, file)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for path, mode := range map[string]os.FileMode{file: 0640, sub: 0550} {
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { os.Chmod(sub, 0755) })
+	dst := filepath.Join(tmp, "dst")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(dst, "sub"), 0755) })
+	for rel, mode := range map[string]os.FileMode{"sub": 0550, filepath.Join("sub", "b.txt"): 0640} {
+		info, err := os.Stat(filepath.Join(dst, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, mode, info.Mode().Perm())
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("%s: got mtime %v, want %v", rel, info.ModTime(), mtime)
+		}
+	}
+}
+
+func TestTrashLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no per-mount trash on windows")
+	}
+	unsetAll()
+	tmp := t.TempDir()
+	mnt := filepath.Join(tmp, "usb stick")
+	t.Setenv(dataHomeKey, filepath.Join(tmp, "data"))
+	fakeMount(t, mnt)
+	local := filepath.Join(tmp, "notes", "todo.txt")
+	removable := filepath.Join(mnt, "photos", "cat.png")
+	writeTestFile(t, local)
+	writeTestFile(t, removable)
+	for _, p := range []string{local, removable} {
+		if _, err := Trash(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Entries with missing files are not listed.
+	writeTestFile(t, filepath.Join(tmp, "data", "Trash", "info", "gone.trashinfo"),
+		"[Trash Info]\nPath=/gone\nDeletionDate=2004-08-31T22:32:08\n")
+
+	entries, err := ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	paths := []string{entries[0].Path, entries[1].Path}
+	sort.Strings(paths)
+	arrEq(t, []string{local, removable}, paths)
+	for _, e := range entries {
+		if time.Since(e.Deleted) > time.Minute {
+			t.Errorf("%s: wrong deletion date %v", e.Name, e.Deleted)
+		}
+		if _, err = os.Stat(e.File()); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for _, e := range entries {
+		if err = Restore(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []string{local, removable} {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t, p, string(b))
+	}
+	if entries, err = ListTrash(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty trash, got %v, %v", entries, err)
+	}
+
+	if _, err = Trash(local); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, local)
+	entries, _ = ListTrash()
+	if err = Restore(entries[0]); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("expected ErrExist, got %v", err)
+	}
+
+	if _, err = Trash(removable); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := EmptyTrash(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 0, len(removed))
+	removed, err = EmptyTrash(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(removed))
+	if entries, err = ListTrash(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty trash, got %v, %v", entries, err)
+	}
+	if _, err = os.Stat(filepath.Join(tmp, "data", "Trash", "info", "gone.trashinfo")); !os.IsNotExist(err) {
+		t.Errorf("expected orphaned info file to be removed, got %v", err)
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	for in, want := range map[string]string{
+		"/mnt/usb":           "/mnt/usb",
+		`/mnt/usb\040stick`:  "/mnt/usb stick",
+		`/mnt/tab\011`:       "/mnt/tab\t",
+		`/mnt/back\134slash`: `/mnt/back\slash`,
+		`/mnt/short\04`:      `/mnt/short\04`,
+	} {
+		eq(t, want, unescapeMount(in))
+	}
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package xdg
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func device(path string) (uint64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+func isCrossDevice(err error) bool { return errors.Is(err, syscall.EXDEV) }
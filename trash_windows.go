@@ -0,0 +1,14 @@
+package xdg
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows has no per-mount trash directories.
+func device(string) (uint64, bool) { return 0, false }
+
+// errNotSameDevice is ERROR_NOT_SAME_DEVICE.
+const errNotSameDevice = syscall.Errno(17)
+
+func isCrossDevice(err error) bool { return errors.Is(err, errNotSameDevice) }
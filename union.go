@@ -0,0 +1,158 @@
+package xdg
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// ConfigFS returns a read-only view of the application's config directories
+// with $XDG_CONFIG_HOME layered on top of $XDG_CONFIG_DIRS.
+func ConfigFS(name string) fs.FS { return newXdg(name).ConfigFS() }
+
+// DataFS returns a read-only view of the application's data directories with
+// $XDG_DATA_HOME layered on top of $XDG_DATA_DIRS.
+func DataFS(name string) fs.FS { return newXdg(name).DataFS() }
+
+// ConfigFS returns a read-only file system over the config search path. A
+// file is read from the first directory that has it and directory listings
+// merge the entries of every layer.
+func (xdg *XDG) ConfigFS() fs.FS { return newUnionFS(xdg.configSearchPath()) }
+
+// DataFS returns a read-only file system over the data search path. See
+// ConfigFS.
+func (xdg *XDG) DataFS() fs.FS { return newUnionFS(xdg.dataSearchPath()) }
+
+// unionFS overlays a list of file systems. Earlier layers take precedence.
+type unionFS struct {
+	layers []fs.FS
+}
+
+var (
+	_ fs.ReadDirFS  = (*unionFS)(nil)
+	_ fs.StatFS     = (*unionFS)(nil)
+	_ fs.ReadFileFS = (*unionFS)(nil)
+)
+
+func newUnionFS(dirs []string) *unionFS {
+	u := &unionFS{layers: make([]fs.FS, 0, len(dirs))}
+	for _, d := range dirs {
+		if len(d) > 0 {
+			u.layers = append(u.layers, Dir(d))
+		}
+	}
+	return u
+}
+
+func (u *unionFS) Open(name string) (fs.File, error) {
+	for _, layer := range u.layers {
+		f, err := layer.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if !info.IsDir() {
+			return f, nil
+		}
+		entries, err := u.ReadDir(name)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &unionDir{File: f, entries: entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (u *unionFS) Stat(name string) (fs.FileInfo, error) {
+	for _, layer := range u.layers {
+		info, err := fs.Stat(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return info, err
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (u *unionFS) ReadFile(name string) ([]byte, error) {
+	for _, layer := range u.layers {
+		b, err := fs.ReadFile(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return b, err
+	}
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the entries of name from every layer. When more than one
+// layer has an entry with the same name the one from the highest precedence
+// layer is used. A layer on a case-insensitive file system also hides the
+// entries of lower layers that only differ in case, since opening them
+// through the union would find its copy instead.
+func (u *unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var (
+		found   bool
+		seen    = make(map[string]bool)
+		folded  = make(map[string]bool)
+		entries []fs.DirEntry
+	)
+	for _, layer := range u.layers {
+		list, err := fs.ReadDir(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found = true
+		d, ok := layer.(Dir)
+		fold := ok && caseInsensitive(filepath.Join(string(d), filepath.FromSlash(name)))
+		for _, e := range list {
+			if seen[e.Name()] || folded[foldName(e.Name())] {
+				continue
+			}
+			seen[e.Name()] = true
+			if fold {
+				folded[foldName(e.Name())] = true
+			}
+			entries = append(entries, e)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// unionDir is an open directory whose listing spans every layer.
+type unionDir struct {
+	fs.File
+	entries []fs.DirEntry
+	off     int
+}
+
+func (d *unionDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.off:]
+	if n <= 0 {
+		d.off = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.off += n
+	return rest[:n], nil
+}
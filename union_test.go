@@ -0,0 +1,60 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConfigFS(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(configHomeKey, filepath.Join(root, "home"))
+	t.Setenv(configDirsKey, filepath.Join(root, "etc"))
+	t.Setenv(dataHomeKey, filepath.Join(root, "data"))
+	t.Setenv(dataDirsKey, filepath.Join(root, "share"))
+	write := func(path, content string) {
+		t.Helper()
+		writeTestFile(t, path)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(root, "etc", "app", "settings.toml"), "system")
+	write(filepath.Join(root, "etc", "app", "themes", "dark.css"), "system dark")
+	write(filepath.Join(root, "home", "app", "settings.toml"), "user")
+	write(filepath.Join(root, "home", "app", "themes", "light.css"), "user light")
+
+	fsys := ConfigFS("app")
+	if err := fstest.TestFS(fsys, "settings.toml", "themes/dark.css", "themes/light.css"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.ReadFile(fsys, "settings.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "user", string(b))
+	b, _ = fs.ReadFile(fsys, "themes/dark.css")
+	eq(t, "system dark", string(b))
+	entries, err := fs.ReadDir(fsys, "themes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(entries))
+
+	_, err = fs.ReadFile(fsys, "missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+	if _, err = fs.Stat(fsys, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+	if _, err = fsys.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+	if _, err = fs.ReadDir(DataFS("app"), "."); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+}
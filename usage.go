@@ -0,0 +1,66 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+// usageKinds are the directories reported by Usage.
+var usageKinds = []Kind{KindCache, KindData, KindState}
+
+// Usage returns the bytes used by the cache, data and state directories of
+// the application name. See (*XDG).Usage.
+func Usage(name string) (map[Kind]int64, error) { return newXdg(name).Usage() }
+
+// Usage returns the total size of the regular files in the cache, data and
+// state directories, keyed by kind. A directory that does not exist uses
+// zero bytes. Symbolic links are not followed.
+func (xdg *XDG) Usage() (map[Kind]int64, error) {
+	usage := make(map[Kind]int64, len(usageKinds))
+	for _, kind := range usageKinds {
+		sizes, err := xdg.SubdirUsage(kind)
+		if err != nil {
+			return nil, err
+		}
+		usage[kind] = 0
+		for _, n := range sizes {
+			usage[kind] += n
+		}
+	}
+	return usage, nil
+}
+
+// SubdirUsage breaks the usage of the directory of the given kind down by
+// its top level entries, so that a cache of downloads and thumbnails can be
+// reported as two numbers. Files directly in the directory are keyed by
+// their own name.
+func (xdg *XDG) SubdirUsage(kind Kind) (map[string]int64, error) {
+	dir, err := xdg.Lookup(kind)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]int64)
+	err = Dir(dir).Walk(func(rel string, e fs.DirEntry, err error) error {
+		if rel == "." && errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		top, _, _ := strings.Cut(rel, "/")
+		usage[top] += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
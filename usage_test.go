@@ -0,0 +1,36 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	unsetAll()
+	t.Setenv("HOME", t.TempDir())
+	x := New("app")
+	writeTestFile(t, filepath.Join(x.Cache(), "thumbs", "a.png"), "0123456789")
+	writeTestFile(t, filepath.Join(x.Cache(), "thumbs", "small", "b.png"), "01234")
+	writeTestFile(t, filepath.Join(x.Cache(), "index"), "012")
+	writeTestFile(t, filepath.Join(x.State(), "history"), "01")
+
+	usage, err := Usage("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 3, len(usage))
+	eq(t, int64(18), usage[KindCache])
+	eq(t, int64(0), usage[KindData])
+	eq(t, int64(2), usage[KindState])
+
+	sub, err := x.SubdirUsage(KindCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 2, len(sub))
+	eq(t, int64(15), sub["thumbs"])
+	eq(t, int64(3), sub["index"])
+	if _, err = x.SubdirUsage(Kind(0)); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
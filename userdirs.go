@@ -0,0 +1,322 @@
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the well known user directories as used in user-dirs.dirs,
+// without the XDG_ prefix and _DIR suffix.
+const (
+	UserDirDesktop     = "DESKTOP"
+	UserDirDownload    = "DOWNLOAD"
+	UserDirTemplates   = "TEMPLATES"
+	UserDirPublicShare = "PUBLICSHARE"
+	UserDirDocuments   = "DOCUMENTS"
+	UserDirMusic       = "MUSIC"
+	UserDirPictures    = "PICTURES"
+	UserDirVideos      = "VIDEOS"
+)
+
+const userDirsFile = "user-dirs.dirs"
+
+// userDirMode is the mode UpdateUserDirs creates missing user directories
+// with. They are not one of the Kinds, so DirMode does not apply, and like
+// xdg-user-dirs-update they are left readable by others.
+const userDirMode = 0755
+
+// userDirOrder is the order xdg-user-dirs-update writes the directories in.
+var userDirOrder = []string{
+	UserDirDesktop,
+	UserDirDownload,
+	UserDirTemplates,
+	UserDirPublicShare,
+	UserDirDocuments,
+	UserDirMusic,
+	UserDirPictures,
+	UserDirVideos,
+}
+
+const userDirsHeader = `# This file is written by xdg-user-dirs-update
+# If you want to change or add directories, just edit the line you're
+# interested in. All local changes will be retained on the next run.
+# Format is XDG_xxx_DIR="$HOME/yyy", where yyy is a shell-escaped
+# homedir-relative path, or XDG_xxx_DIR="/yyy", where /yyy is an
+# absolute path. No other format is supported.
+#
+`
+
+// userDirDefaults are used when user-dirs.dirs is missing or does not list a
+// directory. They are the names xdg-user-dirs-update uses in the C locale.
+var userDirDefaults = map[string]string{
+	UserDirDesktop:     "Desktop",
+	UserDirDownload:    "Downloads",
+	UserDirTemplates:   "Templates",
+	UserDirPublicShare: "Public",
+	UserDirDocuments:   "Documents",
+	UserDirMusic:       "Music",
+	UserDirPictures:    "Pictures",
+	UserDirVideos:      "Videos",
+}
+
+func Desktop() string     { return baseXdg().UserDir(UserDirDesktop) }
+func Downloads() string   { return baseXdg().UserDir(UserDirDownload) }
+func Templates() string   { return baseXdg().UserDir(UserDirTemplates) }
+func PublicShare() string { return baseXdg().UserDir(UserDirPublicShare) }
+func Documents() string   { return baseXdg().UserDir(UserDirDocuments) }
+func Music() string       { return baseXdg().UserDir(UserDirMusic) }
+func Pictures() string    { return baseXdg().UserDir(UserDirPictures) }
+func Videos() string      { return baseXdg().UserDir(UserDirVideos) }
+
+// UserDir returns one of the user directories, such as UserDirDownload, as
+// configured in $XDG_CONFIG_HOME/user-dirs.dirs. When the file or the entry is
+// missing a directory with the conventional English name in the home
+// directory is returned. Unknown names fall back to the home directory.
+//
+// See docs:
+//
+//	https://www.freedesktop.org/wiki/Software/xdg-user-dirs/
+func (xdg *XDG) UserDir(name string) string {
+	dirs, _ := xdg.UserDirs()
+	if d, ok := dirs[name]; ok {
+		return d
+	}
+	home, err := xdg.Home()
+	if err != nil {
+		return ""
+	}
+	if def, ok := userDirDefaults[name]; ok {
+		return filepath.Join(home, def)
+	}
+	return home
+}
+
+// UserDirs reads $XDG_CONFIG_HOME/user-dirs.dirs and returns the
+// directories it lists keyed by name.
+func (xdg *XDG) UserDirs() (map[string]string, error) {
+	home, err := xdg.Home()
+	if err != nil {
+		return nil, err
+	}
+	data, err := Dir(xdg.baseDir(configHomeKey)).ReadFile(userDirsFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseUserDirs(bytes.NewReader(data), home)
+}
+
+// parseUserDirs parses the shell fragment written by xdg-user-dirs-update.
+// Only lines of the form XDG_NAME_DIR="$HOME/path" or XDG_NAME_DIR="/path"
+// are understood, everything else is ignored.
+func parseUserDirs(r io.Reader, home string) (map[string]string, error) {
+	dirs := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasPrefix(key, "XDG_") || !strings.HasSuffix(key, "_DIR") {
+			continue
+		}
+		name := key[len("XDG_") : len(key)-len("_DIR")]
+		if len(val) < 2 || val[0] != '"' || val[len(val)-1] != '"' {
+			continue
+		}
+		val = unquoteShell(val[1 : len(val)-1])
+		switch {
+		case val == "$HOME":
+			val = home
+		case strings.HasPrefix(val, "$HOME/"):
+			val = filepath.Join(home, val[len("$HOME/"):])
+		case filepath.IsAbs(val):
+			val = filepath.Clean(val)
+		default:
+			continue
+		}
+		dirs[name] = val
+	}
+	return dirs, sc.Err()
+}
+
+// unquoteShell removes backslash escapes from the inside of a double quoted
+// shell string.
+func unquoteShell(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// UpdateUserDirs creates the user directories. See (*XDG).UpdateUserDirs.
+func UpdateUserDirs() (map[string]string, error) { return baseXdg().UpdateUserDirs() }
+
+// SetUserDir changes a user directory. See (*XDG).SetUserDir.
+func SetUserDir(name, dir string) error { return baseXdg().SetUserDir(name, dir) }
+
+// DisableUserDir disables a user directory. See (*XDG).DisableUserDir.
+func DisableUserDir(name string) error { return baseXdg().DisableUserDir(name) }
+
+// UpdateUserDirs does what xdg-user-dirs-update does: the well known
+// directories missing from $XDG_CONFIG_HOME/user-dirs.dirs are added with
+// their default names, user-dirs.dirs is written, and every directory it
+// lists is created unless it is disabled. It returns the resulting
+// directories keyed by name.
+func (xdg *XDG) UpdateUserDirs() (map[string]string, error) {
+	home, err := xdg.Home()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := xdg.UserDirs()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if dirs == nil {
+		dirs = make(map[string]string)
+	}
+	missing := make(map[string]string)
+	for _, name := range userDirOrder {
+		if _, ok := dirs[name]; !ok {
+			dirs[name] = filepath.Join(home, userDirDefaults[name])
+			missing[name] = dirs[name]
+		}
+	}
+	if err = xdg.writeUserDirs(missing); err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if filepath.Clean(dir) == filepath.Clean(home) {
+			continue
+		}
+		if err = mkdirAll(dir, userDirMode); err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// SetUserDir points the user directory name at dir in
+// $XDG_CONFIG_HOME/user-dirs.dirs, keeping the rest of the file as it is.
+// Pointing a directory at the home directory disables it. The directory is
+// not created.
+func (xdg *XDG) SetUserDir(name, dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("%w: user directory %s must be absolute: %q", ErrInvalidPath, name, dir)
+	}
+	return xdg.writeUserDirs(map[string]string{name: dir})
+}
+
+// DisableUserDir disables the user directory name by pointing it at $HOME,
+// which is how xdg-user-dirs marks a directory the user does not want.
+func (xdg *XDG) DisableUserDir(name string) error {
+	home, err := xdg.Home()
+	if err != nil {
+		return err
+	}
+	return xdg.SetUserDir(name, home)
+}
+
+// writeUserDirs rewrites user-dirs.dirs with the entries in set replaced
+// or added. Other lines are kept.
+func (xdg *XDG) writeUserDirs(set map[string]string) error {
+	home, err := xdg.Home()
+	if err != nil {
+		return err
+	}
+	for name := range set {
+		if !validUserDirName(name) {
+			return fmt.Errorf("xdg: invalid user directory name %q", name)
+		}
+	}
+	conf := Dir(xdg.baseDir(configHomeKey))
+	old, err := conf.ReadFile(userDirsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		old = []byte(userDirsHeader)
+	} else if err != nil {
+		return err
+	}
+	var (
+		buf  bytes.Buffer
+		done = make(map[string]bool)
+	)
+	sc := bufio.NewScanner(bytes.NewReader(old))
+	for sc.Scan() {
+		line := sc.Text()
+		key, _, _ := strings.Cut(strings.TrimSpace(line), "=")
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "XDG_"), "_DIR")
+		if dir, ok := set[name]; ok && key == "XDG_"+name+"_DIR" {
+			if done[name] {
+				continue
+			}
+			line = userDirLine(name, dir, home)
+			done[name] = true
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if err = sc.Err(); err != nil {
+		return err
+	}
+	var extra []string
+	for _, name := range userDirOrder {
+		if _, ok := set[name]; ok {
+			extra = append(extra, name)
+		}
+	}
+	for name := range set {
+		if _, ok := userDirDefaults[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	for _, name := range extra {
+		if !done[name] {
+			buf.WriteString(userDirLine(name, set[name], home))
+			buf.WriteByte('\n')
+		}
+	}
+	return xdg.writeFileAtomicIn(KindConfig, string(conf), userDirsFile, buf.Bytes(), 0644)
+}
+
+func validUserDirName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, c := range name {
+		if !('A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// userDirLine formats an entry of user-dirs.dirs, relative to $HOME when
+// dir is inside the home directory.
+func userDirLine(name, dir, home string) string {
+	val := quoteShell(filepath.ToSlash(filepath.Clean(dir)))
+	if rel, err := filepath.Rel(home, dir); err == nil && rel == "." {
+		val = "$HOME"
+	} else if err == nil && filepath.IsLocal(rel) {
+		val = "$HOME/" + quoteShell(filepath.ToSlash(rel))
+	}
+	return "XDG_" + name + "_DIR=\"" + val + "\""
+}
+
+// quoteShell escapes the characters that are special inside a double quoted
+// shell string.
+func quoteShell(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(s)
+}
@@ -0,0 +1,156 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUserDirs(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	eq(t, filepath.Join(home, "Desktop"), Desktop())
+	eq(t, filepath.Join(home, "Downloads"), Downloads())
+	eq(t, home, newXdg("").UserDir("UNKNOWN"))
+
+	conf := filepath.Join(home, ".config")
+	if err := os.MkdirAll(conf, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(filepath.Join(conf, userDirsFile), []byte(`# This file is written by xdg-user-dirs-update
+XDG_DESKTOP_DIR="$HOME/Schreibtisch"
+XDG_DOWNLOAD_DIR="$HOME/Downloads/"
+XDG_TEMPLATES_DIR="$HOME"
+XDG_PUBLICSHARE_DIR="/srv/public"
+XDG_DOCUMENTS_DIR="$HOME/My \"Docs\""
+XDG_MUSIC_DIR="relative/ignored"
+XDG_PICTURES_DIR=$HOME/unquoted
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(home, "Schreibtisch"), Desktop())
+	eq(t, filepath.Join(home, "Downloads"), Downloads())
+	eq(t, home, Templates())
+	eq(t, "/srv/public", PublicShare())
+	eq(t, filepath.Join(home, `My "Docs"`), Documents())
+	eq(t, filepath.Join(home, "Music"), Music())
+	eq(t, filepath.Join(home, "Pictures"), Pictures())
+	eq(t, filepath.Join(home, "Videos"), Videos())
+}
+
+func TestParseUserDirs(t *testing.T) {
+	dirs, err := parseUserDirs(strings.NewReader("XDG_MUSIC_DIR=\"$HOME/m\"\nOTHER=\"/x\"\nXDG_VIDEOS_DIR\n"), "/home/t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 1, len(dirs))
+	eq(t, "/home/t/m", dirs[UserDirMusic])
+}
+
+func TestUpdateUserDirs(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	file := filepath.Join(home, ".config", userDirsFile)
+	writeTestFile(t, file, "# my comment\nXDG_DESKTOP_DIR=\"$HOME/Schreibtisch\"\nXDG_MUSIC_DIR=\"$HOME\"\n")
+
+	dirs, err := UpdateUserDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, 8, len(dirs))
+	eq(t, filepath.Join(home, "Schreibtisch"), dirs[UserDirDesktop])
+	eq(t, filepath.Join(home, "Downloads"), dirs[UserDirDownload])
+	for _, d := range []string{"Schreibtisch", "Downloads", "Videos"} {
+		if info, err := os.Stat(filepath.Join(home, d)); err != nil || !info.IsDir() {
+			t.Errorf("expected %s to be created: %v", d, err)
+		}
+	}
+	if exists(filepath.Join(home, "Music")) {
+		t.Error("disabled directories should not be created")
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "# my comment\n"+
+		"XDG_DESKTOP_DIR=\"$HOME/Schreibtisch\"\n"+
+		"XDG_MUSIC_DIR=\"$HOME\"\n"+
+		"XDG_DOWNLOAD_DIR=\"$HOME/Downloads\"\n"+
+		"XDG_TEMPLATES_DIR=\"$HOME/Templates\"\n"+
+		"XDG_PUBLICSHARE_DIR=\"$HOME/Public\"\n"+
+		"XDG_DOCUMENTS_DIR=\"$HOME/Documents\"\n"+
+		"XDG_PICTURES_DIR=\"$HOME/Pictures\"\n"+
+		"XDG_VIDEOS_DIR=\"$HOME/Videos\"\n", string(b))
+
+	// A second run changes nothing.
+	if _, err = UpdateUserDirs(); err != nil {
+		t.Fatal(err)
+	}
+	again, _ := os.ReadFile(file)
+	eq(t, string(b), string(again))
+}
+
+func TestSetUserDir(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	file := filepath.Join(home, ".config", userDirsFile)
+
+	if err := SetUserDir(UserDirDocuments, filepath.Join(home, `My "$Docs"`)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "# This file is written by xdg-user-dirs-update\n") {
+		t.Errorf("missing header:\n%s", b)
+	}
+	if !strings.HasSuffix(string(b), "\nXDG_DOCUMENTS_DIR=\"$HOME/My \\\"\\$Docs\\\"\"\n") {
+		t.Errorf("wrong entry:\n%s", b)
+	}
+	eq(t, filepath.Join(home, `My "$Docs"`), Documents())
+
+	if err = SetUserDir(UserDirDocuments, "/srv/docs"); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "/srv/docs", Documents())
+	if err = DisableUserDir(UserDirDocuments); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, home, Documents())
+	b, _ = os.ReadFile(file)
+	eq(t, 1, strings.Count(string(b), "XDG_DOCUMENTS_DIR"))
+
+	if err = SetUserDir(UserDirMusic, "relative"); err == nil {
+		t.Error("expected an error for a relative path")
+	}
+	if err = SetUserDir("bad name", "/x"); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+}
+
+func TestUserDirs_FileSystem(t *testing.T) {
+	unsetAll()
+	mem := memFS{m: fstest.MapFS{}}
+	SetFileSystem(mem)
+	defer SetFileSystem(nil)
+	t.Setenv("HOME", "/home/t")
+	x := New("")
+	if err := x.SetUserDir(UserDirMusic, "/srv/music"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mem.m["home/t/.config/"+userDirsFile]; !ok {
+		t.Fatal("user-dirs.dirs should be written to the installed file system")
+	}
+	dirs, err := x.UserDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "/srv/music", dirs[UserDirMusic])
+}
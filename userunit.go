@@ -0,0 +1,95 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// unitTypes are the suffixes of the unit files systemd loads.
+var unitTypes = []string{
+	".service", ".socket", ".timer", ".path", ".target",
+	".mount", ".automount", ".swap", ".slice", ".scope", ".device",
+}
+
+// SystemdUserUnitDir returns $XDG_CONFIG_HOME/systemd/user, where systemd
+// looks for the units of the user's service manager.
+func SystemdUserUnitDir() string { return baseXdg().SystemdUserUnitDir() }
+
+// SystemdUserUnitDir returns $XDG_CONFIG_HOME/systemd/user.
+//
+// See docs:
+//
+//	https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html
+func (xdg *XDG) SystemdUserUnitDir() string {
+	base := xdg.baseDir(configHomeKey)
+	if len(base) == 0 {
+		return ""
+	}
+	return filepath.Join(base, "systemd", "user")
+}
+
+// InstallUserService writes the service unit of the application name. See
+// (*XDG).InstallUserService.
+func InstallUserService(name string, unit []byte) (string, error) {
+	return newXdg(name).InstallUserService(unit)
+}
+
+// RemoveUserService removes the service unit of the application name. See
+// (*XDG).RemoveUserService.
+func RemoveUserService(name string) error { return newXdg(name).RemoveUserService() }
+
+// InstallUserService writes unit as <name>.service in the user unit
+// directory and returns its path. See InstallUserUnit.
+func (xdg *XDG) InstallUserService(unit []byte) (string, error) {
+	return xdg.InstallUserUnit(xdg.appID()+".service", unit)
+}
+
+// RemoveUserService removes the unit written by InstallUserService.
+func (xdg *XDG) RemoveUserService() error { return xdg.RemoveUserUnit(xdg.appID() + ".service") }
+
+// InstallUserUnit writes a unit file, such as "myapp.timer", into the user
+// unit directory and returns its path. The file is replaced atomically.
+// systemd only notices new or changed units after
+// "systemctl --user daemon-reload".
+func (xdg *XDG) InstallUserUnit(file string, unit []byte) (string, error) {
+	path, err := xdg.userUnitPath(file)
+	if err != nil {
+		return "", err
+	}
+	if err = xdg.writeFileAtomicIn(KindConfig, filepath.Dir(path), file, unit, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// RemoveUserUnit removes a unit file from the user unit directory. It is not
+// an error if the unit does not exist. Stop and disable the unit first, as
+// removing the file leaves a running unit and its enablement symlinks
+// behind.
+func (xdg *XDG) RemoveUserUnit(file string) error {
+	path, err := xdg.userUnitPath(file)
+	if err != nil {
+		return err
+	}
+	err = remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (xdg *XDG) userUnitPath(file string) (string, error) {
+	ext := filepath.Ext(file)
+	if !filepath.IsLocal(file) || strings.ContainsAny(file, `/\`) || !slices.Contains(unitTypes, ext) || len(file) == len(ext) {
+		return "", fmt.Errorf("%w: unit file name %q", ErrInvalidPath, file)
+	}
+	dir := xdg.SystemdUserUnitDir()
+	if len(dir) == 0 {
+		return "", ErrNoHome
+	}
+	return filepath.Join(dir, file), nil
+}
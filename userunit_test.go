@@ -0,0 +1,53 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserService(t *testing.T) {
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(configHomeKey, tmp)
+	dir := filepath.Join(tmp, "systemd", "user")
+	eq(t, dir, SystemdUserUnitDir())
+
+	unit := []byte("[Unit]\nDescription=Agent\n\n[Service]\nExecStart=/usr/bin/agent\n")
+	path, err := InstallUserService("agent", unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(dir, "agent.service"), path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, string(unit), string(b))
+	if err = RemoveUserService("agent"); err != nil {
+		t.Fatal(err)
+	}
+	if exists(path) {
+		t.Error("unit should be removed")
+	}
+	if err = RemoveUserService("agent"); err != nil {
+		t.Errorf("removing a missing unit should not fail: %v", err)
+	}
+
+	x := NewXDG(NewDirFinderVendor("acme", "agent"))
+	if path, err = x.InstallUserUnit("acme-agent.timer", unit); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(dir, "acme-agent.timer"), path)
+	if path, err = x.InstallUserService(unit); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, filepath.Join(dir, "acme-agent.service"), path)
+
+	for _, name := range []string{"agent", ".service", "../agent.service", "sub/agent.service", "agent.conf"} {
+		if _, err = x.InstallUserUnit(name, unit); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("InstallUserUnit(%q): expected ErrInvalidPath, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package xdg
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventOp is the kind of change reported by WatchConfig.
+type EventOp int
+
+const (
+	// EventCreate means the file appeared on the config search path.
+	EventCreate EventOp = iota + 1
+	// EventWrite means the file that takes precedence was modified or a
+	// different copy now takes precedence.
+	EventWrite
+	// EventRemove means no copy of the file is left.
+	EventRemove
+)
+
+func (op EventOp) String() string {
+	switch op {
+	case EventCreate:
+		return "create"
+	case EventWrite:
+		return "write"
+	case EventRemove:
+		return "remove"
+	default:
+		return fmt.Sprintf("EventOp(%d)", int(op))
+	}
+}
+
+// Event is a change to a watched config file.
+type Event struct {
+	Op EventOp
+	// Path is the copy of the file that now takes precedence, or the one
+	// that was removed.
+	Path string
+}
+
+// watchInterval is how often watched files are polled.
+var watchInterval = 500 * time.Millisecond
+
+// WatchConfig watches the config file of the application name. See
+// (*XDG).WatchConfig.
+func WatchConfig(name, file string) (<-chan Event, func()) {
+	return newXdg(name).WatchConfig(file)
+}
+
+// WatchConfig reports changes to file on the config search path, the copy
+// SearchConfigFile would return. The file is polled, so no platform
+// notification API is needed, and a burst of changes is reported as a
+// single event once the file stops changing. Calling the returned function
+// stops the watcher and closes the channel.
+func (xdg *XDG) WatchConfig(file string) (<-chan Event, func()) {
+	var (
+		events = make(chan Event)
+		done   = make(chan struct{})
+		wg     sync.WaitGroup
+		once   sync.Once
+		last   = xdg.configState(file)
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(events)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		pending := last
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			cur := xdg.configState(file)
+			if cur != pending {
+				// Still changing, wait for it to settle.
+				pending = cur
+				continue
+			}
+			if cur == last {
+				continue
+			}
+			ev := last.event(cur)
+			last = cur
+			select {
+			case events <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return events, func() {
+		once.Do(func() { close(done) })
+		wg.Wait()
+	}
+}
+
+type fileState struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+func (xdg *XDG) configState(file string) fileState {
+	res := find(xdg.configSearchPath(), file, true)
+	if len(res) == 0 {
+		return fileState{}
+	}
+	info, err := fileSystem().Stat(res[0].Path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{path: res[0].Path, size: info.Size(), mtime: info.ModTime()}
+}
+
+func (old fileState) event(cur fileState) Event {
+	switch {
+	case len(old.path) == 0:
+		return Event{Op: EventCreate, Path: cur.path}
+	case len(cur.path) == 0:
+		return Event{Op: EventRemove, Path: old.path}
+	default:
+		return Event{Op: EventWrite, Path: cur.path}
+	}
+}
@@ -0,0 +1,61 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return Event{}
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	old := watchInterval
+	watchInterval = 5 * time.Millisecond
+	t.Cleanup(func() { watchInterval = old })
+	unsetAll()
+	tmp := t.TempDir()
+	t.Setenv(configHomeKey, filepath.Join(tmp, "home"))
+	t.Setenv(configDirsKey, filepath.Join(tmp, "etc"))
+	user := filepath.Join(tmp, "home", "app", "config.toml")
+	system := filepath.Join(tmp, "etc", "app", "config.toml")
+
+	events, stop := WatchConfig("app", "config.toml")
+	defer stop()
+
+	writeTestFile(t, system, "a=1")
+	eq(t, Event{Op: EventCreate, Path: system}, nextEvent(t, events))
+
+	writeTestFile(t, user, "a=2")
+	eq(t, Event{Op: EventWrite, Path: user}, nextEvent(t, events))
+
+	writeTestFile(t, user, "a=345")
+	eq(t, Event{Op: EventWrite, Path: user}, nextEvent(t, events))
+
+	if err := os.Remove(user); err != nil {
+		t.Fatal(err)
+	}
+	eq(t, Event{Op: EventWrite, Path: system}, nextEvent(t, events))
+
+	if err := os.Remove(system); err != nil {
+		t.Fatal(err)
+	}
+	ev := nextEvent(t, events)
+	eq(t, Event{Op: EventRemove, Path: system}, ev)
+	eq(t, "remove", ev.Op.String())
+
+	stop()
+	stop()
+	if _, ok := <-events; ok {
+		t.Error("channel should be closed")
+	}
+}
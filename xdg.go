@@ -1,15 +1,24 @@
 // Package xdg is a helper package to get configuration directories according
 // to the XDG Base Directory Specification
 //
+// On Windows, when the XDG variables are not set, directories default to the
+// matching Known Folders (%APPDATA%, %LOCALAPPDATA%, %PROGRAMDATA%) instead
+// of dot directories in the user's home.
+//
 // See docs:
 //
 //	https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
 package xdg
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 const (
@@ -41,15 +50,39 @@ func Runtime(name string) string      { return newXdg(name).Runtime() }
 func ConfigDirs(name string) []string { return newXdg(name).ConfigDirs() }
 func DataDirs(name string) []string   { return newXdg(name).DataDirs() }
 
+// ConfigE is like Config but returns an error when the directory cannot be
+// determined.
+func ConfigE(name string) (string, error) { return newXdg(name).ConfigE() }
+
+// StateE is like State but returns an error when the directory cannot be
+// determined.
+func StateE(name string) (string, error) { return newXdg(name).StateE() }
+
+// DataE is like Data but returns an error when the directory cannot be
+// determined.
+func DataE(name string) (string, error) { return newXdg(name).DataE() }
+
+// CacheE is like Cache but returns an error when the directory cannot be
+// determined.
+func CacheE(name string) (string, error) { return newXdg(name).CacheE() }
+
+// RuntimeE is like Runtime but returns an error when XDG_RUNTIME_DIR is not
+// set.
+func RuntimeE(name string) (string, error) { return newXdg(name).RuntimeE() }
+
 func newXdg(name string) *XDG { return NewXDG(NewDirFinder(name)) }
 
 type Dir string
 
 func (d Dir) Exists() bool           { return exists(string(d)) }
-func (d Dir) Create() error          { return os.MkdirAll(string(d), 0755) }
+func (d Dir) Create() error          { return d.CreateMode(0755) }
 func (d Dir) String() string         { return string(d) }
 func (d Dir) Append(name string) Dir { return Dir(filepath.Join(string(d), name)) }
 
+// CreateMode creates the directory and any missing parents with mode perm,
+// before the umask. Existing directories are left as they are.
+func (d Dir) CreateMode(perm fs.FileMode) error { return mkdirAll(string(d), perm) }
+
 func (d Dir) Split() []string {
 	p := strings.Split(string(d), string(filepath.Separator))
 	if len(p) > 0 {
@@ -69,9 +102,185 @@ type DirFinder interface {
 
 type XDG struct {
 	finder DirFinder
+
+	homeOnce sync.Once
+	home     string
+	homeErr  error
+
+	noOverrides bool
+	root        string
+	goos        string
+
+	nativeDarwin bool
+
+	runtimeFallback bool
+	runtimeWarn     func(error)
+
+	environ func(string) (string, bool)
+	strict  bool
+	profile string
+	systemd bool
+
+	systemMode int
+
+	portable       bool
+	portableRoot   string
+	portableMarker string
+
+	dirModes map[Kind]fs.FileMode
+
+	searchWorkers int
+	lookups       *lookupCache
+}
+
+// Option configures an XDG instance.
+type Option func(*XDG)
+
+// WithHomeDir overrides the home directory used to compute default paths.
+func WithHomeDir(dir string) Option {
+	return func(xdg *XDG) { xdg.home = dir }
+}
+
+// WithEnviron replaces os.LookupEnv as the source of environment variables,
+// including the home directory variable. This allows resolving directories
+// for another user or in tests without touching the process environment.
+func WithEnviron(lookup func(key string) (string, bool)) Option {
+	return func(xdg *XDG) { xdg.environ = lookup }
+}
+
+// WithGOOS resolves directories using the conventions of goos instead of
+// runtime.GOOS.
+func WithGOOS(goos string) Option {
+	return func(xdg *XDG) { xdg.goos = goos }
+}
+
+// WithStrictSpec ignores relative paths in the XDG variables as the spec
+// requires. A relative $XDG_CONFIG_HOME is treated as if it were unset and
+// relative entries are dropped from $XDG_CONFIG_DIRS and $XDG_DATA_DIRS.
+// Values are also used literally, without the expansion of ~ and variables
+// described in envDir.
+func WithStrictSpec() Option {
+	return func(xdg *XDG) { xdg.strict = true }
+}
+
+// envDir looks up a single directory variable, reporting whether it should
+// be used. Empty values are treated as unset, as the spec requires. Unless
+// WithStrictSpec is used, a leading ~ and $VAR or ${VAR} references are
+// expanded, since values written in rc files with the wrong quoting would
+// otherwise create directories literally named ~.
+func (xdg *XDG) envDir(key string) (string, bool) {
+	val, ok := xdg.lookupEnv(key)
+	if !ok || len(val) == 0 {
+		return "", false
+	}
+	if xdg.strict && !filepath.IsAbs(val) {
+		return "", false
+	}
+	val = xdg.expand(val)
+	return val, len(val) > 0
+}
+
+// envDirs looks up a directory list variable, reporting whether it should
+// be used. Empty values are treated as unset.
+func (xdg *XDG) envDirs(key string) (string, bool) {
+	val, ok := xdg.lookupEnv(key)
+	if !ok || len(val) == 0 {
+		return "", false
+	}
+	if !xdg.strict {
+		if !strings.ContainsAny(val, "~$") {
+			return val, true
+		}
+		var dirs []string
+		for _, p := range filepath.SplitList(val) {
+			if p = xdg.expand(p); len(p) > 0 {
+				dirs = append(dirs, p)
+			}
+		}
+		return strings.Join(dirs, listSeparator), len(dirs) > 0
+	}
+	var keep []string
+	for _, p := range filepath.SplitList(val) {
+		if filepath.IsAbs(p) {
+			keep = append(keep, p)
+		}
+	}
+	if len(keep) == 0 {
+		return "", false
+	}
+	return strings.Join(keep, listSeparator), true
+}
+
+// expand replaces a leading ~ in val with the home directory and $VAR or
+// ${VAR} with the value of the variable, the way a shell does. When the
+// home directory cannot be determined ~ is left as it is.
+func (xdg *XDG) expand(val string) string {
+	if xdg.strict || !strings.ContainsAny(val, "~$") {
+		return val
+	}
+	if val == "~" || strings.HasPrefix(val, "~/") || strings.HasPrefix(val, "~"+string(filepath.Separator)) {
+		if home, err := xdg.Home(); err == nil {
+			val = home + val[1:]
+		}
+	}
+	if !strings.Contains(val, "$") {
+		return val
+	}
+	return os.Expand(val, func(key string) string {
+		v, ok := xdg.lookupEnv(key)
+		if !ok && key == "HOME" {
+			v, _ = xdg.Home()
+		}
+		return v
+	})
 }
 
-func NewXDG(finder DirFinder) *XDG { return &XDG{finder: finder} }
+func (xdg *XDG) lookupEnv(key string) (string, bool) {
+	if xdg.environ != nil {
+		return xdg.environ(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// New returns the directories for the application name.
+func New(name string, opts ...Option) *XDG { return NewXDG(NewDirFinder(name), opts...) }
+
+func NewXDG(finder DirFinder, opts ...Option) *XDG {
+	xdg := &XDG{finder: finder, goos: runtime.GOOS}
+	for _, o := range opts {
+		o(xdg)
+	}
+	return xdg
+}
+
+// Home returns the home directory used to compute default paths. Unless
+// overridden with WithHomeDir, it is looked up once with os.UserHomeDir, or
+// from the environment given to WithEnviron, and cached for the lifetime of
+// the instance.
+func (xdg *XDG) Home() (string, error) {
+	xdg.homeOnce.Do(func() {
+		if len(xdg.home) > 0 {
+			return
+		}
+		if xdg.environ == nil {
+			xdg.home, xdg.homeErr = os.UserHomeDir()
+			return
+		}
+		key := "HOME"
+		switch xdg.goos {
+		case "windows":
+			key = "USERPROFILE"
+		case "plan9":
+			key = "home"
+		}
+		if home, ok := xdg.environ(key); ok && len(home) > 0 {
+			xdg.home = home
+		} else {
+			xdg.homeErr = errors.New("$" + key + " is not defined")
+		}
+	})
+	return xdg.home, xdg.homeErr
+}
 
 func (xdg *XDG) Config() string       { return xdg.getDir(configHomeKey) }
 func (xdg *XDG) Cache() string        { return xdg.getDir(cacheHomeKey) }
@@ -81,76 +290,209 @@ func (xdg *XDG) Runtime() string      { return xdg.getDir(runtimeDirKey) }
 func (xdg *XDG) ConfigDirs() []string { return xdg.getDirs(configDirsKey) }
 func (xdg *XDG) DataDirs() []string   { return xdg.getDirs(dataDirsKey) }
 
+func (xdg *XDG) ConfigDir() Dir  { return Dir(xdg.Config()) }
+func (xdg *XDG) CacheDir() Dir   { return Dir(xdg.Cache()) }
+func (xdg *XDG) DataDir() Dir    { return Dir(xdg.Data()) }
+func (xdg *XDG) StateDir() Dir   { return Dir(xdg.State()) }
+func (xdg *XDG) RuntimeDir() Dir { return Dir(xdg.Runtime()) }
+
+func (xdg *XDG) ConfigE() (string, error)  { return xdg.getDirE(configHomeKey) }
+func (xdg *XDG) CacheE() (string, error)   { return xdg.getDirE(cacheHomeKey) }
+func (xdg *XDG) DataE() (string, error)    { return xdg.getDirE(dataHomeKey) }
+func (xdg *XDG) StateE() (string, error)   { return xdg.getDirE(stateHomeKey) }
+func (xdg *XDG) RuntimeE() (string, error) { return xdg.getDirE(runtimeDirKey) }
+
 func (xdg *XDG) getDir(key string) string {
-	val, ok := os.LookupEnv(key)
-	if ok {
-		return filepath.Join(val, xdg.finder.Name())
+	dir, _ := xdg.getDirE(key)
+	return dir
+}
+
+func (xdg *XDG) getDirE(key string) (string, error) {
+	dir, err := xdg.appDirE(key)
+	if err != nil || len(xdg.profile) == 0 || key == runtimeDirKey {
+		return dir, err
 	}
-	switch key {
-	case runtimeDirKey:
-		return ""
+	if !validProfile(xdg.profile) {
+		return "", fmt.Errorf("%w: profile %q", ErrInvalidPath, xdg.profile)
+	}
+	return filepath.Join(dir, profilesDir, xdg.profile), nil
+}
+
+// appDirE resolves the application directory for key, ignoring any profile.
+func (xdg *XDG) appDirE(key string) (string, error) {
+	if len(xdg.root) > 0 {
+		return xdg.rootedDir(key), nil
+	}
+	if p, ok := xdg.portableDir(key); ok {
+		return p, nil
+	}
+	if p, ok, err := xdg.override(key); ok {
+		return p, err
+	}
+	if p, ok := xdg.systemdDir(key); ok {
+		return p, nil
+	}
+	if base, ok := xdg.systemBase(key); ok {
+		return filepath.Join(base, xdg.finder.Name()), nil
+	}
+	if key == runtimeDirKey {
+		base, err := xdg.runtimeBase()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, xdg.finder.Name()), nil
+	}
+	val, ok := xdg.envDir(key)
+	if ok {
+		return filepath.Join(val, xdg.finder.Name()), nil
 	}
-	home, err := os.UserHomeDir()
+	home, err := xdg.Home()
 	if err != nil {
-		return ""
+		return "", fmt.Errorf("%w: %w", ErrNoHome, err)
 	}
 	def := xdg.defaultVal(home, key)
 	if len(def) > 0 {
-		return def
+		return def, nil
 	}
-	return filepath.Join(home, "."+xdg.finder.Name())
+	return filepath.Join(home, "."+xdg.finder.Name()), nil
 }
 
-func (xdg *XDG) getDirs(key string) []string {
-	var p string
-	v, ok := os.LookupEnv(key)
-	if ok {
-		p = v
-	} else {
-		switch key {
-		case dataDirsKey:
-			p = defaultDataDirs
-		case configDirsKey:
-			p = defaultConfigDirs
-		default:
-			p = ""
-		}
+func (xdg *XDG) getDirs(key string) []string { return xdg.dirsFor(key, xdg.finder.Name()) }
+
+// baseDirs returns the system directories for key without the application
+// name appended.
+func (xdg *XDG) baseDirs(key string) []string { return xdg.dirsFor(key, "") }
+
+func (xdg *XDG) dirsFor(key, name string) []string {
+	if len(xdg.root) > 0 {
+		return xdg.rootedDirs(key, name)
 	}
-	if len(p) > 0 {
-		paths := filepath.SplitList(p)
-		name := xdg.finder.Name()
-		for i := range paths {
-			paths[i] = filepath.Join(paths[i], name)
+	if v, ok := xdg.envDirs(key); ok {
+		if len(v) == 0 {
+			return nil
 		}
-		return paths
+		return joinName(filepath.SplitList(v), name)
+	} else if xdg.goos == "windows" {
+		return joinName(xdg.windowsDirs(key), name)
+	} else if xdg.darwinNative() {
+		return joinName(darwinDirs(key), name)
+	}
+	return joinName(defaultDirs(key), name)
+}
+
+// defaultDirs returns the spec's default search path for key. The defaults
+// are unix paths and always use ':' regardless of the host list separator.
+func defaultDirs(key string) []string {
+	switch key {
+	case dataDirsKey:
+		return strings.Split(defaultDataDirs, ":")
+	case configDirsKey:
+		return strings.Split(defaultConfigDirs, ":")
 	}
 	return nil
 }
 
+func joinName(paths []string, name string) []string {
+	for i := range paths {
+		paths[i] = filepath.Join(paths[i], name)
+	}
+	return paths
+}
+
+// baseDir returns the base directory for key without the application name
+// appended.
+func (xdg *XDG) baseDir(key string) string {
+	if len(xdg.root) > 0 {
+		return rootedBase(xdg.root, key)
+	}
+	if base, ok := xdg.systemBase(key); ok {
+		return base
+	}
+	if key == runtimeDirKey {
+		base, _ := xdg.runtimeBase()
+		return base
+	}
+	val, ok := xdg.envDir(key)
+	if ok {
+		return val
+	}
+	home, err := xdg.Home()
+	if err != nil {
+		return ""
+	}
+	return xdg.defaultBase(home, key)
+}
+
 func (xdg *XDG) defaultVal(home, key string) string {
-	var base string
+	base := xdg.defaultBase(home, key)
+	if len(base) == 0 {
+		return ""
+	}
+	return filepath.Join(base, xdg.finder.Name())
+}
+
+// defaultBase returns the base directory used for key when its environment
+// variable is not set.
+func (xdg *XDG) defaultBase(home, key string) string {
+	if xdg.goos == "windows" {
+		if p := xdg.windowsBase(key); len(p) > 0 {
+			return p
+		}
+	}
+	if xdg.darwinNative() {
+		if p := darwinBase(home, key); len(p) > 0 {
+			return p
+		}
+	}
+	if p := xdg.SandboxInfo().base(key); len(p) > 0 {
+		return p
+	}
+	return defaultBase(home, key)
+}
+
+func defaultBase(home, key string) string {
 	switch strings.ToUpper(key) {
 	case configHomeKey:
-		base = filepath.Join(home, defaultHomeBase)
+		return filepath.Join(home, defaultHomeBase)
 	case cacheHomeKey:
-		base = filepath.Join(home, defaultCacheBase)
+		return filepath.Join(home, defaultCacheBase)
 	case dataHomeKey:
-		base = filepath.Join(home, defaultDataBase)
+		return filepath.Join(home, defaultDataBase)
 	case stateHomeKey:
-		base = filepath.Join(home, defaultStateBase)
+		return filepath.Join(home, defaultStateBase)
 	default:
 		return ""
 	}
-	return filepath.Join(base, xdg.finder.Name())
 }
 
-func NewDirFinder(name string) *dirFinder { return &dirFinder{name} }
+func NewDirFinder(name string) *dirFinder { return &dirFinder{name: name} }
+
+// NewDirFinderVendor returns a DirFinder that nests the application under a
+// vendor directory so paths resolve as <base>/<vendor>/<app>.
+func NewDirFinderVendor(vendor, app string) *dirFinder {
+	return &dirFinder{name: app, vendor: vendor}
+}
+
+type dirFinder struct{ name, vendor string }
 
-type dirFinder struct{ name string }
+func (df *dirFinder) Name() string {
+	if len(df.vendor) == 0 {
+		return df.name
+	}
+	return filepath.Join(df.vendor, df.name)
+}
 
-func (df *dirFinder) Name() string { return df.name }
+// appID returns the application name as a single path component, joining
+// any vendor prefix with '-' the way desktop file IDs are formed.
+func (xdg *XDG) appID() string {
+	return strings.Join(nameParts(xdg.finder.Name()), "-")
+}
+
+func nameParts(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == filepath.Separator })
+}
 
 func exists(path string) bool {
-	_, err := os.Stat(path)
+	_, err := fileSystem().Stat(path)
 	return !os.IsNotExist(err)
 }
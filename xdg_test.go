@@ -1,7 +1,9 @@
 package xdg
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -76,6 +78,23 @@ func TestDir_Create(t *testing.T) {
 	_ = os.RemoveAll(string(d))
 }
 
+// writeTestFile creates the file at path and its parent directories. The
+// file holds data, or its own path when data is omitted so tests can tell
+// which copy was read.
+func writeTestFile(t *testing.T, path string, data ...string) {
+	t.Helper()
+	content := path
+	if len(data) > 0 {
+		content = data[0]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func eq[T comparable](t *testing.T, a, b T) {
 	t.Helper()
 	if a != b {
@@ -113,3 +132,182 @@ func unsetAll() {
 		}
 	}
 }
+
+func TestHome(t *testing.T) {
+	t.Setenv("HOME", "/home/first")
+	t.Setenv(configHomeKey, "")
+	os.Unsetenv(configHomeKey)
+	x := NewXDG(NewDirFinder("app"))
+	home, err := x.Home()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, "/home/first", home)
+	os.Setenv("HOME", "/home/second")
+	home, _ = x.Home()
+	eq(t, "/home/first", home)
+
+	x = NewXDG(NewDirFinder("app"), WithHomeDir("/home/other"))
+	home, _ = x.Home()
+	eq(t, "/home/other", home)
+	eq(t, "/home/other/.config/app", x.Config())
+}
+
+func TestErrorVariants(t *testing.T) {
+	unsetAll()
+	t.Setenv("HOME", "/home/t")
+	name := "go-xdg-test"
+	for _, fn := range []func(string) (string, error){ConfigE, StateE, DataE, CacheE} {
+		dir, err := fn(name)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(dir) == 0 {
+			t.Error("expected a directory")
+		}
+	}
+	_, err := RuntimeE(name)
+	if !errors.Is(err, ErrRuntimeDirUnset) {
+		t.Errorf("expected runtime dir error, got %v", err)
+	}
+	os.Setenv(runtimeDirKey, "/run/user/1000")
+	dir, err := RuntimeE(name)
+	eq(t, nil, err)
+	eq(t, "/run/user/1000/go-xdg-test", dir)
+
+	os.Unsetenv("HOME")
+	_, err = ConfigE(name)
+	if !errors.Is(err, ErrNoHome) {
+		t.Errorf("expected no home error, got %v", err)
+	}
+	eq(t, "", Config(name))
+}
+
+func TestNew(t *testing.T) {
+	unsetAll()
+	t.Setenv(runtimeDirKey, "/run/user/1000")
+	x := New("myapp", WithHomeDir("/home/t"))
+	eq(t, Dir("/home/t/.config/myapp"), x.ConfigDir())
+	eq(t, Dir("/home/t/.cache/myapp"), x.CacheDir())
+	eq(t, Dir("/home/t/.local/share/myapp"), x.DataDir())
+	eq(t, Dir("/home/t/.local/state/myapp"), x.StateDir())
+	eq(t, Dir("/run/user/1000/myapp"), x.RuntimeDir())
+	eq(t, "/home/t/.config/myapp/settings.toml", x.ConfigDir().Append("settings.toml").String())
+}
+
+func TestDirFinderVendor(t *testing.T) {
+	unsetAll()
+	base := t.TempDir()
+	t.Setenv(runtimeDirKey, base)
+	t.Setenv(configDirsKey, "/etc/xdg")
+	x := NewXDG(NewDirFinderVendor("acme", "tool"), WithHomeDir("/home/t"))
+	eq(t, filepath.Join("acme", "tool"), x.finder.Name())
+	eq(t, "/home/t/.config/acme/tool", x.Config())
+	eq(t, "/home/t/.cache/acme/tool", x.Cache())
+	arrEq(t, []string{"/etc/xdg/acme/tool"}, x.ConfigDirs())
+	eq(t, "acme-tool", x.appID())
+	eq(t, "/home/t/.config/autostart/acme-tool.desktop", x.autostartFile())
+
+	dir, err := x.RuntimeSubdir("sockets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t, Dir(filepath.Join(base, "acme", "tool", "sockets")), dir)
+}
+
+func TestWithEnviron(t *testing.T) {
+	env := map[string]string{
+		"HOME":         "/home/other",
+		dataHomeKey:    "/srv/data",
+		runtimeDirKey:  "/run/user/1001",
+		configDirsKey:  "/opt/etc",
+		"USERPROFILE":  `C:\Users\other`,
+		"LOCALAPPDATA": `C:\Users\other\AppData\Local`,
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	x := New("app", WithEnviron(lookup), WithGOOS("linux"))
+	eq(t, "/home/other/.config/app", x.Config())
+	eq(t, "/srv/data/app", x.Data())
+	eq(t, "/run/user/1001/app", x.Runtime())
+	arrEq(t, []string{"/opt/etc/app"}, x.ConfigDirs())
+	arrEq(t, []string{"/usr/local/share/app", "/usr/share/app"}, x.DataDirs())
+
+	delete(env, dataHomeKey)
+	x = New("app", WithEnviron(lookup), WithGOOS("windows"))
+	home, _ := x.Home()
+	eq(t, `C:\Users\other`, home)
+	eq(t, filepath.Join(`C:\Users\other\AppData\Local`, "app"), x.Data())
+
+	x = New("app", WithEnviron(func(string) (string, bool) { return "", false }))
+	_, err := x.Home()
+	if err == nil {
+		t.Error("expected missing home error")
+	}
+	_, err = x.ConfigE()
+	if !errors.Is(err, ErrNoHome) {
+		t.Errorf("expected no home error, got %v", err)
+	}
+}
+
+func TestStrictSpec(t *testing.T) {
+	env := map[string]string{
+		"HOME":        "/home/t",
+		configHomeKey: "relative/config",
+		dataHomeKey:   "/abs/data",
+		runtimeDirKey: "run",
+		configDirsKey: "etc:/etc/xdg2",
+		dataDirsKey:   "share",
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	x := New("app", WithEnviron(lookup), WithStrictSpec())
+	eq(t, "/home/t/.config/app", x.Config())
+	eq(t, "/abs/data/app", x.Data())
+	eq(t, "", x.Runtime())
+	arrEq(t, []string{"/etc/xdg2/app"}, x.ConfigDirs())
+	arrEq(t, []string{"/usr/local/share/app", "/usr/share/app"}, x.DataDirs())
+
+	x = New("app", WithEnviron(lookup))
+	eq(t, "relative/config/app", x.Config())
+	arrEq(t, []string{"share/app"}, x.DataDirs())
+}
+
+func TestEmptyEnv(t *testing.T) {
+	env := map[string]string{"HOME": "/home/t"}
+	for _, key := range []string{configHomeKey, dataHomeKey, stateHomeKey, cacheHomeKey, runtimeDirKey, configDirsKey, dataDirsKey} {
+		env[key] = ""
+	}
+	x := New("app", WithEnviron(func(k string) (string, bool) { v, ok := env[k]; return v, ok }))
+	eq(t, "/home/t/.config/app", x.Config())
+	eq(t, "/home/t/.local/share/app", x.Data())
+	eq(t, "/home/t/.local/state/app", x.State())
+	eq(t, "/home/t/.cache/app", x.Cache())
+	eq(t, "", x.Runtime())
+	arrEq(t, []string{"/etc/xdg/app"}, x.ConfigDirs())
+	arrEq(t, []string{"/usr/local/share/app", "/usr/share/app"}, x.DataDirs())
+	_, err := x.RuntimeE()
+	if !errors.Is(err, ErrRuntimeDirUnset) {
+		t.Errorf("expected runtime dir error, got %v", err)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	unsetAll()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PROJECT", "proj")
+	t.Setenv(configHomeKey, "~/conf")
+	t.Setenv(dataHomeKey, "$HOME/share")
+	t.Setenv(cacheHomeKey, "${HOME}/${PROJECT}/cache")
+	t.Setenv(stateHomeKey, "/var/~state")
+	t.Setenv(dataDirsKey, "~/a"+string(os.PathListSeparator)+"$UNSET"+string(os.PathListSeparator)+"/usr/share")
+
+	eq(t, filepath.Join(home, "conf", "app"), Config("app"))
+	eq(t, filepath.Join(home, "share", "app"), Data("app"))
+	eq(t, filepath.Join(home, "proj", "cache", "app"), Cache("app"))
+	eq(t, filepath.Join("/var/~state", "app"), State("app"))
+	arrEq(t, []string{filepath.Join(home, "a", "app"), filepath.Join("/usr/share", "app")}, DataDirs("app"))
+
+	// The spec does not allow relative paths, so they are ignored instead.
+	strict := New("app", WithStrictSpec())
+	eq(t, filepath.Join(home, ".config", "app"), strict.Config())
+}
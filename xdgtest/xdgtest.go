@@ -0,0 +1,79 @@
+// Package xdgtest provides helpers for testing code that uses the xdg
+// package.
+package xdgtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg"
+)
+
+// Sandbox points HOME and every XDG variable at a fresh temporary directory
+// for the duration of the test and returns the directories for an
+// application named after the test. See SandboxApp.
+func Sandbox(t testing.TB) *xdg.XDG {
+	t.Helper()
+	return SandboxApp(t, strings.NewReplacer("/", "-", " ", "_").Replace(t.Name()))
+}
+
+// SandboxApp is like Sandbox for the application name. The layout inside
+// the temporary directory is:
+//
+//	home/                 HOME, USERPROFILE
+//	home/.config          XDG_CONFIG_HOME, APPDATA
+//	home/.local/share     XDG_DATA_HOME, LOCALAPPDATA
+//	home/.local/state     XDG_STATE_HOME
+//	home/.cache           XDG_CACHE_HOME
+//	run/                  XDG_RUNTIME_DIR (mode 0700)
+//	etc/xdg               XDG_CONFIG_DIRS, PROGRAMDATA
+//	usr/share             XDG_DATA_DIRS
+//
+// The variables are restored and the directory removed when the test ends.
+// Like testing.T.Setenv it cannot be used in parallel tests.
+func SandboxApp(t testing.TB, name string) *xdg.XDG {
+	t.Helper()
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	dirs := map[string]string{
+		"HOME":            home,
+		"USERPROFILE":     home,
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local", "share"),
+		"XDG_STATE_HOME":  filepath.Join(home, ".local", "state"),
+		"XDG_CACHE_HOME":  filepath.Join(home, ".cache"),
+		"XDG_RUNTIME_DIR": filepath.Join(root, "run"),
+		"XDG_CONFIG_DIRS": filepath.Join(root, "etc", "xdg"),
+		"XDG_DATA_DIRS":   filepath.Join(root, "usr", "share"),
+	}
+	for key, dir := range dirs {
+		mode := os.FileMode(0755)
+		if key == "XDG_RUNTIME_DIR" {
+			mode = 0700
+		}
+		if err := os.MkdirAll(dir, mode); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(key, dir)
+	}
+	t.Setenv("APPDATA", dirs["XDG_CONFIG_HOME"])
+	t.Setenv("LOCALAPPDATA", dirs["XDG_DATA_HOME"])
+	t.Setenv("PROGRAMDATA", dirs["XDG_CONFIG_DIRS"])
+	return xdg.New(name, xdg.WithHomeDir(home))
+}
+
+// WriteFile creates the file at path with data and mode 0644, creating any
+// missing parent directories, and returns path. The test fails if either
+// cannot be created.
+func WriteFile(t testing.TB, path, data string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,65 @@
+package xdgtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harrybrwn/xdg"
+)
+
+func TestSandbox(t *testing.T) {
+	x := Sandbox(t)
+	home, err := x.Home()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv("HOME") != home {
+		t.Errorf("HOME should be %s", home)
+	}
+	root := filepath.Dir(home)
+	for _, dir := range []string{x.Config(), x.Data(), x.State(), x.Cache()} {
+		if !strings.HasPrefix(dir, home) {
+			t.Errorf("%s is not inside %s", dir, home)
+		}
+	}
+	if want := filepath.Join(root, "run", "TestSandbox"); x.Runtime() != want {
+		t.Errorf("got %s, want %s", x.Runtime(), want)
+	}
+	info, err := os.Stat(xdg.RuntimeDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("runtime dir should be 0700, got %v", info.Mode().Perm())
+	}
+	if got := x.ConfigDirs(); len(got) != 1 || got[0] != filepath.Join(root, "etc", "xdg", "TestSandbox") {
+		t.Errorf("wrong config dirs %v", got)
+	}
+	if xdg.Config("other") != filepath.Join(home, ".config", "other") {
+		t.Error("package functions should use the sandbox")
+	}
+}
+
+func TestSandboxApp(t *testing.T) {
+	t.Run("sub test", func(t *testing.T) {
+		x := Sandbox(t)
+		if filepath.Base(x.Config()) != "TestSandboxApp-sub_test" {
+			t.Errorf("wrong name %s", x.Config())
+		}
+	})
+	x := SandboxApp(t, "myapp")
+	if filepath.Base(x.Data()) != "myapp" {
+		t.Errorf("wrong name %s", x.Data())
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a", "b", "file")
+	eq := WriteFile(t, path, "data") == path
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "data" || !eq {
+		t.Errorf("got %q, %v", b, err)
+	}
+}